@@ -0,0 +1,272 @@
+package decisionmaker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/internal/lru"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultCacheHintAdviceID = "cache_hint"
+	defaultCacheHintTTLAttr  = "ttl_seconds"
+	defaultCacheMaxEntries   = 10000
+	cacheKeySeparator        = "\n"
+	cacheHintTTLBitSize      = 64
+)
+
+// cachingDecisionMaker decorates a DecisionMaker with a decision cache
+type cachingDecisionMaker struct {
+	next       *decisionMaker
+	adviceID   string
+	ttlAttr    string
+	maxEntries int
+	now        func() time.Time
+	cache      *lru.Cache
+}
+
+// CacheOption defines configuration options for the caching DecisionMaker
+type CacheOption func(*cachingDecisionMaker)
+
+// NewCachingDecisionMaker creates a DecisionMaker that caches decisions made by next, which must be created by
+// NewDecisionMaker.
+// Policies are resolved and retrieved by next with its own resolvers, resolver options and duplicate policy strategy,
+// and requests of a batch resolving to the same policies share a single retrieval, cache hits included. Entries are
+// keyed on a canonical hash of the request, excluding RequestID, and the content hashes of the retrieved policies, so
+// a new policy version or reloaded policy content results in a new entry. Entries expire after the TTL read from the
+// cache hint advice of the decision; decisions without the advice, Indeterminate decisions and traced requests are not
+// cached, and neither are requests whose policies could not be retrieved or which skipped a failed optional resolver.
+func NewCachingDecisionMaker(next DecisionMaker, options ...CacheOption) (DecisionMaker, error) {
+	dm, ok := next.(*decisionMaker)
+	if !ok {
+		return nil, fmt.Errorf("unsupported decision maker %T, expected one created by NewDecisionMaker", next)
+	}
+
+	c := &cachingDecisionMaker{
+		next:       dm,
+		adviceID:   defaultCacheHintAdviceID,
+		ttlAttr:    defaultCacheHintTTLAttr,
+		maxEntries: defaultCacheMaxEntries,
+		now:        time.Now,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.cache = lru.New(c.maxEntries, lru.WithClock(func() time.Time { return c.now() }))
+	return c, nil
+}
+
+// WithCacheHintAdvice sets the advice ID and attribute carrying the TTL in seconds, "cache_hint" and "ttl_seconds" by default
+func WithCacheHintAdvice(adviceID, ttlAttribute string) CacheOption {
+	return func(dm *cachingDecisionMaker) {
+		dm.adviceID = adviceID
+		dm.ttlAttr = ttlAttribute
+	}
+}
+
+// WithCacheMaxEntries bounds the number of cached decisions, evicting the least recently used one when full, 10000 by default.
+// A bound of zero or less disables caching.
+func WithCacheMaxEntries(maxEntries int) CacheOption {
+	return func(dm *cachingDecisionMaker) {
+		dm.maxEntries = maxEntries
+	}
+}
+
+// MakeDecision returns a cached decision for the request when available, otherwise evaluates it with the wrapped
+// DecisionMaker.
+func (c *cachingDecisionMaker) MakeDecision(ctx context.Context, req *DecisionRequest) (*DecisionResponse, error) {
+	if req == nil {
+		return nil, errors.New("decision request cannot be nil")
+	}
+
+	return c.next.makeDecision(ctx, req, c.decide), nil
+}
+
+// MakeDecisions returns cached decisions where available and evaluates the remaining requests with the wrapped
+// DecisionMaker, retrieving the policies of requests resolving to the same references once.
+func (c *cachingDecisionMaker) MakeDecisions(ctx context.Context, reqs []*DecisionRequest) ([]*DecisionResponse, error) {
+	return c.next.makeDecisions(ctx, reqs, c.decide)
+}
+
+// decide returns the cached decision for the request and its retrieved policies, or makes and caches it on a miss
+func (c *cachingDecisionMaker) decide(
+	ctx context.Context,
+	req *DecisionRequest,
+	resolved resolution,
+	policies []Policy,
+	policyErr error,
+	trace *Trace,
+) *DecisionResponse {
+	if trace != nil || policyErr != nil || len(resolved.failedResolvers) > 0 {
+		return c.next.decide(ctx, req, resolved, policies, policyErr, trace)
+	}
+
+	key, err := cacheKey(req, policies)
+	if err != nil {
+		return c.next.decide(ctx, req, resolved, policies, policyErr, trace)
+	}
+
+	if response, ok := c.get(key, req.RequestID); ok {
+		return response
+	}
+
+	response := c.next.decide(ctx, req, resolved, policies, policyErr, trace)
+	c.set(key, response)
+	return response
+}
+
+// cacheKey hashes the request, excluding RequestID, together with the identity and content hash of its policies
+func cacheKey(req *DecisionRequest, policies []Policy) (string, error) {
+	keyReq := *req
+	keyReq.RequestID = uuid.Nil
+
+	// encoding/json sorts map keys, making the encoding canonical for equal requests
+	content, err := json.Marshal(keyReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode decision request: %w", err)
+	}
+
+	hash := sha256.New()
+	hash.Write(content)
+	for _, policy := range policies {
+		contentHash := policy.ContentHash
+		if contentHash == "" {
+			contentHash = policyprovider.HashContent(policy.Content)
+		}
+
+		hash.Write([]byte(cacheKeySeparator + policy.ID + "@" + policy.Version + "#" + contentHash))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// get returns a copy of the cached response for the key, stamped with the given request ID and the current time
+func (c *cachingDecisionMaker) get(key string, requestID uuid.UUID) (*DecisionResponse, bool) {
+	cached, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	response := cloneResponse(cached.(*DecisionResponse))
+	response.RequestID = requestID
+	response.EvaluatedAt = c.now()
+	return response, true
+}
+
+// set caches the response when it is cacheable and carries a positive TTL in its cache hint advice
+func (c *cachingDecisionMaker) set(key string, response *DecisionResponse) {
+	if response == nil || response.Decision == Indeterminate {
+		return
+	}
+
+	if ttl, ok := c.ttl(response); ok {
+		c.cache.AddWithTTL(key, cloneResponse(response), ttl)
+	}
+}
+
+// ttl reads the TTL from the cache hint advice of the response
+func (c *cachingDecisionMaker) ttl(response *DecisionResponse) (time.Duration, bool) {
+	for _, advice := range response.Advice {
+		if advice.ID != c.adviceID {
+			continue
+		}
+
+		seconds, err := toSeconds(advice.Attributes[c.ttlAttr])
+		if err != nil || seconds <= 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	return 0, false
+}
+
+// toSeconds converts a numeric advice attribute to a number of seconds
+func toSeconds(value any) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, cacheHintTTLBitSize)
+	default:
+		return 0, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+// cloneResponse returns a copy of the response sharing no slices or attribute values with it
+func cloneResponse(response *DecisionResponse) *DecisionResponse {
+	clone := *response
+	clone.PolicyIdReferences = slices.Clone(response.PolicyIdReferences)
+
+	if response.Status != nil {
+		status := *response.Status
+		status.MissingAttributes = slices.Clone(status.MissingAttributes)
+		status.FailedResolvers = slices.Clone(status.FailedResolvers)
+		clone.Status = &status
+	}
+
+	if response.Obligations != nil {
+		clone.Obligations = make([]Obligation, 0, len(response.Obligations))
+		for _, obligation := range response.Obligations {
+			clone.Obligations = append(clone.Obligations, Obligation{ID: obligation.ID, Attributes: cloneAttributes(obligation.Attributes)})
+		}
+	}
+
+	if response.Advice != nil {
+		clone.Advice = make([]Advice, 0, len(response.Advice))
+		for _, advice := range response.Advice {
+			clone.Advice = append(clone.Advice, Advice{ID: advice.ID, Attributes: cloneAttributes(advice.Attributes)})
+		}
+	}
+
+	return &clone
+}
+
+// cloneAttributes returns a deep copy of the attributes, copying nested JSON objects and arrays
+func cloneAttributes(attributes map[string]any) map[string]any {
+	if attributes == nil {
+		return nil
+	}
+
+	clone := make(map[string]any, len(attributes))
+	for name, value := range attributes {
+		clone[name] = cloneAttributeValue(value)
+	}
+
+	return clone
+}
+
+// cloneAttributeValue returns a deep copy of JSON objects and arrays, and other values as they are
+func cloneAttributeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return cloneAttributes(v)
+	case []any:
+		clone := make([]any, 0, len(v))
+		for _, item := range v {
+			clone = append(clone, cloneAttributeValue(item))
+		}
+
+		return clone
+	default:
+		return value
+	}
+}
@@ -0,0 +1,287 @@
+package decisionmaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type nopDecisionMaker struct{}
+
+func (nopDecisionMaker) MakeDecision(context.Context, *DecisionRequest) (*DecisionResponse, error) {
+	return nil, nil
+}
+
+func (nopDecisionMaker) MakeDecisions(context.Context, []*DecisionRequest) ([]*DecisionResponse, error) {
+	return nil, nil
+}
+
+// TestNewCachingDecisionMaker tests that only decision makers created by NewDecisionMaker can be cached
+func TestNewCachingDecisionMaker(t *testing.T) {
+	dm, err := NewCachingDecisionMaker(nopDecisionMaker{})
+
+	assert.Nil(t, dm)
+	assert.EqualError(t, err, "unsupported decision maker decisionmaker.nopDecisionMaker, expected one created by NewDecisionMaker")
+}
+
+// TestCachingDecisionMaker_MakeDecision tests caching of single decisions
+func TestCachingDecisionMaker_MakeDecision(t *testing.T) {
+	cacheHint := []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}}
+
+	tests := map[string]struct {
+		first               *EvaluationResult
+		secondVersion       string
+		secondContentHash   string
+		secondTrace         bool
+		optionalResolverErr error
+		elapsed             time.Duration
+		expectedEvaluations int
+		expectedFromHit     bool
+	}{
+		"should serve repeated request from cache": {
+			first:               &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}, Advice: cacheHint},
+			elapsed:             29 * time.Second,
+			expectedEvaluations: 1,
+			expectedFromHit:     true,
+		},
+		"should expire cached decision after cache hint ttl": {
+			first:               &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}, Advice: cacheHint},
+			elapsed:             30 * time.Second,
+			expectedEvaluations: 2,
+		},
+		"should not cache decision without cache hint advice": {
+			first:               &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}},
+			expectedEvaluations: 2,
+		},
+		"should not cache decision with invalid cache hint ttl": {
+			first: &EvaluationResult{
+				Decision: Permit,
+				Status:   Status{Code: StatusOK},
+				Advice:   []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": "soon"}}},
+			},
+			expectedEvaluations: 2,
+		},
+		"should not cache indeterminate decision": {
+			first:               &EvaluationResult{Decision: Indeterminate, Status: Status{Code: StatusEvaluationError}, Advice: cacheHint},
+			expectedEvaluations: 2,
+		},
+		"should miss cache when resolved policy version changes": {
+			first:               &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}, Advice: cacheHint},
+			secondVersion:       "2.0",
+			expectedEvaluations: 2,
+		},
+		"should miss cache when policy content is reloaded under the same version": {
+			first:               &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}, Advice: cacheHint},
+			secondContentHash:   "reloaded",
+			expectedEvaluations: 2,
+		},
+		"should bypass cache for traced request": {
+			first:               &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}, Advice: cacheHint},
+			secondTrace:         true,
+			expectedEvaluations: 2,
+		},
+		"should bypass cache when optional resolver fails": {
+			first:               &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}, Advice: cacheHint},
+			optionalResolverErr: errors.New("flag service unavailable"),
+			expectedEvaluations: 2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			provider := new(mockPolicyProvider)
+			evaluator := new(mockPolicyEvaluator)
+			resolver := new(mockPolicyResolver)
+			optional := new(mockPolicyResolver)
+
+			secondVersion := "1.0"
+			if tc.secondVersion != "" {
+				secondVersion = tc.secondVersion
+			}
+
+			secondContentHash := "hash"
+			if tc.secondContentHash != "" {
+				secondContentHash = tc.secondContentHash
+			}
+
+			resolver.On("Resolve", mock.Anything, mock.Anything).
+				Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}}, nil).Once()
+			resolver.On("Resolve", mock.Anything, mock.Anything).
+				Return([]PolicyIdReference{{ID: "policy1", Version: secondVersion}}, nil).Once()
+			optional.On("Resolve", mock.Anything, mock.Anything).Return(nil, tc.optionalResolverErr)
+			provider.On("GetPolicies", mock.Anything, mock.Anything).
+				Return([]policyprovider.PolicyResponse{{ID: "policy1", Version: "1.0", ContentHash: "hash"}}, nil).Once()
+			provider.On("GetPolicies", mock.Anything, mock.Anything).
+				Return([]policyprovider.PolicyResponse{{ID: "policy1", Version: secondVersion, ContentHash: secondContentHash}}, nil).Once()
+			evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything).Return(tc.first, nil)
+
+			dm, err := NewCachingDecisionMaker(NewDecisionMaker(
+				provider,
+				evaluator,
+				WithPolicyResolver(resolver),
+				WithPolicyResolver(optional, OptionalResolver()),
+			))
+			require.NoError(t, err)
+
+			dm.(*cachingDecisionMaker).now = func() time.Time { return now }
+
+			response, err := dm.MakeDecision(context.Background(), newCacheTestRequest())
+			require.NoError(t, err)
+			assert.Equal(t, tc.first.Decision, response.Decision)
+
+			now = now.Add(tc.elapsed)
+			secondReq := newCacheTestRequest()
+			secondReq.Trace = tc.secondTrace
+
+			response, err = dm.MakeDecision(context.Background(), secondReq)
+			require.NoError(t, err)
+			assert.Equal(t, tc.first.Decision, response.Decision)
+			assert.Equal(t, secondReq.RequestID, response.RequestID)
+			if tc.expectedFromHit {
+				assert.Equal(t, now, response.EvaluatedAt)
+			}
+
+			evaluator.AssertNumberOfCalls(t, "Evaluate", tc.expectedEvaluations)
+		})
+	}
+}
+
+// TestCachingDecisionMaker_UsesDecisionMakerOptions tests that policies are resolved with the wrapped DecisionMaker's options
+func TestCachingDecisionMaker_UsesDecisionMakerOptions(t *testing.T) {
+	provider := new(mockPolicyProvider)
+	evaluator := new(mockPolicyEvaluator)
+	primary := new(mockPolicyResolver)
+	secondary := new(mockPolicyResolver)
+
+	primary.On("Resolve", mock.Anything, mock.Anything).Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}}, nil)
+	secondary.On("Resolve", mock.Anything, mock.Anything).Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}}, nil)
+	provider.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "1.0"}}).
+		Return([]policyprovider.PolicyResponse{{ID: "policy1", Version: "1.0", ContentHash: "hash"}}, nil)
+	evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything).Return(&EvaluationResult{
+		Decision: Permit,
+		Status:   Status{Code: StatusOK},
+		Advice:   []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}},
+	}, nil)
+
+	dm, err := NewCachingDecisionMaker(NewDecisionMaker(
+		provider,
+		evaluator,
+		WithPolicyResolver(primary),
+		WithPolicyResolver(secondary),
+		WithDuplicatePolicyStrategy(NewDedupeSameVersion()),
+	))
+	require.NoError(t, err)
+
+	for range 2 {
+		response, err := dm.MakeDecision(context.Background(), newCacheTestRequest())
+		require.NoError(t, err)
+		assert.Equal(t, Permit, response.Decision)
+		assert.Equal(t, []PolicyIdReference{{ID: "policy1", Version: "1.0"}}, response.PolicyIdReferences)
+	}
+
+	provider.AssertNumberOfCalls(t, "GetPolicies", 2)
+	evaluator.AssertNumberOfCalls(t, "Evaluate", 1)
+}
+
+// TestCachingDecisionMaker_ReturnsCopies tests that responses never share state with cache entries
+func TestCachingDecisionMaker_ReturnsCopies(t *testing.T) {
+	provider := new(mockPolicyProvider)
+	evaluator := new(mockPolicyEvaluator)
+	resolver := new(mockPolicyResolver)
+
+	resolver.On("Resolve", mock.Anything, mock.Anything).Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}}, nil)
+	provider.On("GetPolicies", mock.Anything, mock.Anything).
+		Return([]policyprovider.PolicyResponse{{ID: "policy1", Version: "1.0", ContentHash: "hash"}}, nil)
+	evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything).Return(&EvaluationResult{
+		Decision:    Permit,
+		Status:      Status{Code: StatusOK},
+		Obligations: []Obligation{{ID: "audit", Attributes: map[string]any{"fields": []any{"owner"}}}},
+		Advice:      []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}},
+	}, nil).Once()
+
+	dm, err := NewCachingDecisionMaker(NewDecisionMaker(provider, evaluator, WithPolicyResolver(resolver)))
+	require.NoError(t, err)
+
+	for range 2 {
+		response, err := dm.MakeDecision(context.Background(), newCacheTestRequest())
+		require.NoError(t, err)
+
+		assert.Equal(t, StatusOK, response.Status.Code)
+		assert.Equal(t, []any{"owner"}, response.Obligations[0].Attributes["fields"])
+		assert.Equal(t, []PolicyIdReference{{ID: "policy1", Version: "1.0"}}, response.PolicyIdReferences)
+
+		response.Status.Code = StatusProcessingError
+		response.Obligations[0].Attributes["fields"].([]any)[0] = "changed"
+		response.PolicyIdReferences[0].Version = "changed"
+	}
+
+	evaluator.AssertExpectations(t)
+}
+
+// TestCachingDecisionMaker_MakeDecisions tests caching of batched decisions
+func TestCachingDecisionMaker_MakeDecisions(t *testing.T) {
+	provider := new(mockPolicyProvider)
+	evaluator := new(mockPolicyEvaluator)
+	resolver := new(mockPolicyResolver)
+
+	resolver.On("Resolve", mock.Anything, mock.Anything).Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}}, nil)
+	provider.On("GetPolicies", mock.Anything, mock.Anything).
+		Return([]policyprovider.PolicyResponse{{ID: "policy1", Version: "1.0", ContentHash: "hash"}}, nil)
+	evaluator.On("Evaluate", mock.Anything, mock.MatchedBy(func(req *DecisionRequest) bool {
+		return req.Resource.ID == "order1"
+	}), mock.Anything).Return(&EvaluationResult{
+		Decision: Permit,
+		Status:   Status{Code: StatusOK},
+		Advice:   []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}},
+	}, nil).Once()
+	evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything).
+		Return(&EvaluationResult{Decision: Deny, Status: Status{Code: StatusOK}}, nil)
+
+	dm, err := NewCachingDecisionMaker(NewDecisionMaker(provider, evaluator, WithPolicyResolver(resolver)))
+	require.NoError(t, err)
+
+	_, err = dm.MakeDecision(context.Background(), newCacheTestRequest())
+	require.NoError(t, err)
+
+	repeated := newCacheTestRequest()
+	missed := newCacheTestRequest()
+	missed.Resource.ID = "order2"
+	other := newCacheTestRequest()
+	other.Resource.ID = "order3"
+
+	responses, err := dm.MakeDecisions(context.Background(), []*DecisionRequest{repeated, missed, other})
+	require.NoError(t, err)
+	require.Len(t, responses, 3)
+
+	assert.Equal(t, repeated.RequestID, responses[0].RequestID)
+	assert.Equal(t, Permit, responses[0].Decision)
+	assert.Equal(t, missed.RequestID, responses[1].RequestID)
+	assert.Equal(t, Deny, responses[1].Decision)
+	assert.Equal(t, other.RequestID, responses[2].RequestID)
+	assert.Equal(t, Deny, responses[2].Decision)
+
+	// The batch retrieves the policies shared by its requests once, cache hit included
+	provider.AssertNumberOfCalls(t, "GetPolicies", 2)
+	evaluator.AssertNumberOfCalls(t, "Evaluate", 3)
+
+	_, err = dm.MakeDecisions(context.Background(), []*DecisionRequest{nil})
+	assert.EqualError(t, err, "decision request at index 0 cannot be nil")
+}
+
+// newCacheTestRequest creates a decision request with a fresh request ID
+func newCacheTestRequest() *DecisionRequest {
+	return &DecisionRequest{
+		RequestID: uuid.New(),
+		Subject:   Subject{ID: "user123", Type: "user", Attributes: map[string]any{"roles": []string{"admin"}}},
+		Resource:  Resource{ID: "order1", Type: "order"},
+		Action:    Action{ID: "read"},
+	}
+}
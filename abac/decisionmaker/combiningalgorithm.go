@@ -0,0 +1,210 @@
+package decisionmaker
+
+import "slices"
+
+// CombiningAlgorithm defines the interface for components that merge the results of individually evaluated policies
+// into a single evaluation result.
+type CombiningAlgorithm interface {
+	// Combine merges the per-policy evaluation results, given in policy order, into a single evaluation result.
+	Combine(results []EvaluationResult) *EvaluationResult
+}
+
+// denyOverrides implements the deny-overrides combining algorithm
+type denyOverrides struct{}
+
+// NewDenyOverrides creates a CombiningAlgorithm where a single Deny overrides any other result.
+// Indeterminate takes precedence over Permit, and NotApplicable is returned when no policy applies.
+func NewDenyOverrides() CombiningAlgorithm {
+	return &denyOverrides{}
+}
+
+// Combine merges results with Deny > Indeterminate > Permit > NotApplicable precedence
+func (a *denyOverrides) Combine(results []EvaluationResult) *EvaluationResult {
+	return combineByPrecedence(results, Deny, Indeterminate, Permit)
+}
+
+// permitOverrides implements the permit-overrides combining algorithm
+type permitOverrides struct{}
+
+// NewPermitOverrides creates a CombiningAlgorithm where a single Permit overrides any other result.
+// Indeterminate takes precedence over Deny, and NotApplicable is returned when no policy applies.
+func NewPermitOverrides() CombiningAlgorithm {
+	return &permitOverrides{}
+}
+
+// Combine merges results with Permit > Indeterminate > Deny > NotApplicable precedence
+func (a *permitOverrides) Combine(results []EvaluationResult) *EvaluationResult {
+	return combineByPrecedence(results, Permit, Indeterminate, Deny)
+}
+
+// firstApplicable implements the first-applicable combining algorithm
+type firstApplicable struct{}
+
+// NewFirstApplicable creates a CombiningAlgorithm that returns the first result, in policy order, that is not NotApplicable.
+func NewFirstApplicable() CombiningAlgorithm {
+	return &firstApplicable{}
+}
+
+// Combine returns the first applicable result or NotApplicable when no policy applies
+func (a *firstApplicable) Combine(results []EvaluationResult) *EvaluationResult {
+	for _, result := range results {
+		if result.Decision != NotApplicable {
+			return merge(result.Decision, []EvaluationResult{result})
+		}
+	}
+
+	return notApplicable(results)
+}
+
+// onlyOneApplicable implements the only-one-applicable combining algorithm
+type onlyOneApplicable struct{}
+
+// NewOnlyOneApplicable creates a CombiningAlgorithm that requires exactly one policy to apply.
+// The result is Indeterminate when any policy is Indeterminate or when more than one policy applies.
+func NewOnlyOneApplicable() CombiningAlgorithm {
+	return &onlyOneApplicable{}
+}
+
+// Combine returns the single applicable result, Indeterminate on ambiguity, or NotApplicable when no policy applies
+func (a *onlyOneApplicable) Combine(results []EvaluationResult) *EvaluationResult {
+	if hasDecision(results, Indeterminate) {
+		return merge(Indeterminate, results)
+	}
+
+	applicable := make([]EvaluationResult, 0, 1)
+	for _, result := range results {
+		if result.Decision != NotApplicable {
+			applicable = append(applicable, result)
+		}
+	}
+
+	switch len(applicable) {
+	case 0:
+		return notApplicable(results)
+	case 1:
+		return merge(applicable[0].Decision, applicable)
+	default:
+		return &EvaluationResult{
+			Decision: Indeterminate,
+			Status: Status{
+				Code:    StatusProcessingError,
+				Message: "More than one policy is applicable to the request",
+			},
+		}
+	}
+}
+
+// denyUnlessPermit implements the deny-unless-permit combining algorithm
+type denyUnlessPermit struct{}
+
+// NewDenyUnlessPermit creates a CombiningAlgorithm that returns Permit when any policy permits and Deny otherwise.
+// It never returns Indeterminate or NotApplicable.
+func NewDenyUnlessPermit() CombiningAlgorithm {
+	return &denyUnlessPermit{}
+}
+
+// Combine returns Permit if any result permits, otherwise Deny
+func (a *denyUnlessPermit) Combine(results []EvaluationResult) *EvaluationResult {
+	if hasDecision(results, Permit) {
+		return merge(Permit, results)
+	}
+
+	return merge(Deny, results)
+}
+
+// permitUnlessDeny implements the permit-unless-deny combining algorithm
+type permitUnlessDeny struct{}
+
+// NewPermitUnlessDeny creates a CombiningAlgorithm that returns Deny when any policy denies and Permit otherwise.
+// It never returns Indeterminate or NotApplicable.
+func NewPermitUnlessDeny() CombiningAlgorithm {
+	return &permitUnlessDeny{}
+}
+
+// Combine returns Deny if any result denies, otherwise Permit
+func (a *permitUnlessDeny) Combine(results []EvaluationResult) *EvaluationResult {
+	if hasDecision(results, Deny) {
+		return merge(Deny, results)
+	}
+
+	return merge(Permit, results)
+}
+
+// combineByPrecedence returns the merged result of the first decision in precedence order found in results,
+// falling back to NotApplicable when none are found
+func combineByPrecedence(results []EvaluationResult, precedence ...Decision) *EvaluationResult {
+	for _, decision := range precedence {
+		if hasDecision(results, decision) {
+			return merge(decision, results)
+		}
+	}
+
+	return notApplicable(results)
+}
+
+// hasDecision reports whether any of the results has the given decision
+func hasDecision(results []EvaluationResult, decision Decision) bool {
+	for _, result := range results {
+		if result.Decision == decision {
+			return true
+		}
+	}
+
+	return false
+}
+
+// merge builds a result with the given decision from all results sharing that decision.
+// The status is taken from the first matching result, and obligations, advice and missing attributes are accumulated
+// in policy order.
+func merge(decision Decision, results []EvaluationResult) *EvaluationResult {
+	merged := &EvaluationResult{
+		Decision: decision,
+		Status:   Status{Code: StatusOK},
+	}
+
+	matched := false
+	var missingAttributes []AttributeDesignator
+	for _, result := range results {
+		if result.Decision != decision {
+			continue
+		}
+
+		if !matched {
+			merged.Status = result.Status
+			matched = true
+		}
+
+		merged.Obligations = append(merged.Obligations, result.Obligations...)
+		merged.Advice = append(merged.Advice, result.Advice...)
+		missingAttributes = appendMissingAttributes(missingAttributes, result.Status.MissingAttributes)
+	}
+
+	merged.Status.MissingAttributes = missingAttributes
+	return merged
+}
+
+// appendMissingAttributes appends the attributes not already listed
+func appendMissingAttributes(listed, attributes []AttributeDesignator) []AttributeDesignator {
+	for _, attribute := range attributes {
+		if !slices.Contains(listed, attribute) {
+			listed = append(listed, attribute)
+		}
+	}
+
+	return listed
+}
+
+// notApplicable builds a NotApplicable result, keeping the status and obligations of any NotApplicable results
+func notApplicable(results []EvaluationResult) *EvaluationResult {
+	if hasDecision(results, NotApplicable) {
+		return merge(NotApplicable, results)
+	}
+
+	return &EvaluationResult{
+		Decision: NotApplicable,
+		Status: Status{
+			Code:    StatusPolicyNotFound,
+			Message: "No applicable policies found for the request",
+		},
+	}
+}
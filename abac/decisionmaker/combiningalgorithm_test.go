@@ -0,0 +1,228 @@
+//nolint:lll // unit tests
+package decisionmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCombiningAlgorithm_Combine tests the built-in combining algorithms
+func TestCombiningAlgorithm_Combine(t *testing.T) {
+	permit := EvaluationResult{
+		Decision:    Permit,
+		Status:      Status{Code: StatusOK, Message: "permitted"},
+		Obligations: []Obligation{{ID: "audit_permit"}},
+		Advice:      []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}},
+	}
+	otherPermit := EvaluationResult{
+		Decision:    Permit,
+		Status:      Status{Code: StatusOK, Message: "also permitted"},
+		Obligations: []Obligation{{ID: "notify_owner"}},
+	}
+	deny := EvaluationResult{
+		Decision:    Deny,
+		Status:      Status{Code: StatusOK, Message: "denied"},
+		Obligations: []Obligation{{ID: "audit_deny"}},
+	}
+	indeterminate := EvaluationResult{
+		Decision: Indeterminate,
+		Status:   Status{Code: StatusEvaluationError, Message: "evaluation failed"},
+	}
+	missingRoles := EvaluationResult{
+		Decision: Indeterminate,
+		Status: Status{
+			Code:              StatusMissingAttribute,
+			Message:           "Missing attributes: subject.roles",
+			MissingAttributes: []AttributeDesignator{{Category: "subject", Path: "roles"}},
+		},
+	}
+	missingRegion := EvaluationResult{
+		Decision: Indeterminate,
+		Status: Status{
+			Code:              StatusMissingAttribute,
+			Message:           "Missing attributes: environment.region, subject.roles",
+			MissingAttributes: []AttributeDesignator{{Category: "environment", Path: "region"}, {Category: "subject", Path: "roles"}},
+		},
+	}
+	notApplicableResult := EvaluationResult{
+		Decision:    NotApplicable,
+		Status:      Status{Code: StatusPolicyNotFound, Message: "not applicable"},
+		Obligations: []Obligation{{ID: "audit_not_applicable"}},
+	}
+	noPolicyApplies := &EvaluationResult{
+		Decision: NotApplicable,
+		Status:   Status{Code: StatusPolicyNotFound, Message: "No applicable policies found for the request"},
+	}
+
+	tests := map[string]struct {
+		algorithm      CombiningAlgorithm
+		results        []EvaluationResult
+		expectedResult *EvaluationResult
+	}{
+		"deny-overrides should deny when any policy denies": {
+			algorithm: NewDenyOverrides(),
+			results:   []EvaluationResult{permit, indeterminate, deny},
+			expectedResult: &EvaluationResult{
+				Decision:    Deny,
+				Status:      Status{Code: StatusOK, Message: "denied"},
+				Obligations: []Obligation{{ID: "audit_deny"}},
+			},
+		},
+		"deny-overrides should return indeterminate over permit": {
+			algorithm:      NewDenyOverrides(),
+			results:        []EvaluationResult{permit, indeterminate},
+			expectedResult: &indeterminate,
+		},
+		"deny-overrides should merge missing attributes of all indeterminate results": {
+			algorithm: NewDenyOverrides(),
+			results:   []EvaluationResult{permit, missingRoles, missingRegion},
+			expectedResult: &EvaluationResult{
+				Decision: Indeterminate,
+				Status: Status{
+					Code:    StatusMissingAttribute,
+					Message: "Missing attributes: subject.roles",
+					MissingAttributes: []AttributeDesignator{
+						{Category: "subject", Path: "roles"},
+						{Category: "environment", Path: "region"},
+					},
+				},
+			},
+		},
+		"deny-overrides should merge obligations and advice of all permits": {
+			algorithm: NewDenyOverrides(),
+			results:   []EvaluationResult{notApplicableResult, permit, otherPermit},
+			expectedResult: &EvaluationResult{
+				Decision:    Permit,
+				Status:      Status{Code: StatusOK, Message: "permitted"},
+				Obligations: []Obligation{{ID: "audit_permit"}, {ID: "notify_owner"}},
+				Advice:      []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}},
+			},
+		},
+		"deny-overrides should return not applicable when no policy applies": {
+			algorithm: NewDenyOverrides(),
+			results:   []EvaluationResult{notApplicableResult},
+			expectedResult: &EvaluationResult{
+				Decision:    NotApplicable,
+				Status:      Status{Code: StatusPolicyNotFound, Message: "not applicable"},
+				Obligations: []Obligation{{ID: "audit_not_applicable"}},
+			},
+		},
+		"deny-overrides should return not applicable for empty results": {
+			algorithm:      NewDenyOverrides(),
+			results:        []EvaluationResult{},
+			expectedResult: noPolicyApplies,
+		},
+		"permit-overrides should permit when any policy permits": {
+			algorithm: NewPermitOverrides(),
+			results:   []EvaluationResult{deny, indeterminate, permit},
+			expectedResult: &EvaluationResult{
+				Decision:    Permit,
+				Status:      Status{Code: StatusOK, Message: "permitted"},
+				Obligations: []Obligation{{ID: "audit_permit"}},
+				Advice:      []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}},
+			},
+		},
+		"permit-overrides should return indeterminate over deny": {
+			algorithm:      NewPermitOverrides(),
+			results:        []EvaluationResult{deny, indeterminate},
+			expectedResult: &indeterminate,
+		},
+		"permit-overrides should deny when only denies apply": {
+			algorithm: NewPermitOverrides(),
+			results:   []EvaluationResult{notApplicableResult, deny},
+			expectedResult: &EvaluationResult{
+				Decision:    Deny,
+				Status:      Status{Code: StatusOK, Message: "denied"},
+				Obligations: []Obligation{{ID: "audit_deny"}},
+			},
+		},
+		"first-applicable should return the first applicable result": {
+			algorithm: NewFirstApplicable(),
+			results:   []EvaluationResult{notApplicableResult, deny, permit},
+			expectedResult: &EvaluationResult{
+				Decision:    Deny,
+				Status:      Status{Code: StatusOK, Message: "denied"},
+				Obligations: []Obligation{{ID: "audit_deny"}},
+			},
+		},
+		"first-applicable should return indeterminate when it comes first": {
+			algorithm:      NewFirstApplicable(),
+			results:        []EvaluationResult{indeterminate, permit},
+			expectedResult: &indeterminate,
+		},
+		"first-applicable should return not applicable for empty results": {
+			algorithm:      NewFirstApplicable(),
+			results:        nil,
+			expectedResult: noPolicyApplies,
+		},
+		"only-one-applicable should return the single applicable result": {
+			algorithm: NewOnlyOneApplicable(),
+			results:   []EvaluationResult{notApplicableResult, permit},
+			expectedResult: &EvaluationResult{
+				Decision:    Permit,
+				Status:      Status{Code: StatusOK, Message: "permitted"},
+				Obligations: []Obligation{{ID: "audit_permit"}},
+				Advice:      []Advice{{ID: "cache_hint", Attributes: map[string]any{"ttl_seconds": 30}}},
+			},
+		},
+		"only-one-applicable should return indeterminate when more than one policy applies": {
+			algorithm: NewOnlyOneApplicable(),
+			results:   []EvaluationResult{permit, deny},
+			expectedResult: &EvaluationResult{
+				Decision: Indeterminate,
+				Status:   Status{Code: StatusProcessingError, Message: "More than one policy is applicable to the request"},
+			},
+		},
+		"only-one-applicable should return indeterminate when any policy is indeterminate": {
+			algorithm:      NewOnlyOneApplicable(),
+			results:        []EvaluationResult{permit, indeterminate},
+			expectedResult: &indeterminate,
+		},
+		"only-one-applicable should return not applicable when no policy applies": {
+			algorithm:      NewOnlyOneApplicable(),
+			results:        []EvaluationResult{},
+			expectedResult: noPolicyApplies,
+		},
+		"deny-unless-permit should permit when any policy permits": {
+			algorithm: NewDenyUnlessPermit(),
+			results:   []EvaluationResult{deny, otherPermit},
+			expectedResult: &EvaluationResult{
+				Decision:    Permit,
+				Status:      Status{Code: StatusOK, Message: "also permitted"},
+				Obligations: []Obligation{{ID: "notify_owner"}},
+			},
+		},
+		"deny-unless-permit should deny when no policy permits": {
+			algorithm: NewDenyUnlessPermit(),
+			results:   []EvaluationResult{indeterminate, notApplicableResult},
+			expectedResult: &EvaluationResult{
+				Decision: Deny,
+				Status:   Status{Code: StatusOK},
+			},
+		},
+		"permit-unless-deny should deny when any policy denies": {
+			algorithm: NewPermitUnlessDeny(),
+			results:   []EvaluationResult{permit, deny},
+			expectedResult: &EvaluationResult{
+				Decision:    Deny,
+				Status:      Status{Code: StatusOK, Message: "denied"},
+				Obligations: []Obligation{{ID: "audit_deny"}},
+			},
+		},
+		"permit-unless-deny should permit when no policy denies": {
+			algorithm: NewPermitUnlessDeny(),
+			results:   []EvaluationResult{indeterminate, notApplicableResult},
+			expectedResult: &EvaluationResult{
+				Decision: Permit,
+				Status:   Status{Code: StatusOK},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedResult, tc.algorithm.Combine(tc.results))
+		})
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
@@ -106,6 +106,7 @@ type DecisionRequest struct {
 	Resource    Resource       `json:"resource"`
 	Action      Action         `json:"action"`
 	Environment map[string]any `json:"environment,omitempty"`
+	Trace       bool           `json:"trace,omitempty"`
 }
 
 // Obligation represents a mandatory action that must be performed when enforcing the decision
@@ -120,10 +121,29 @@ type Advice struct {
 	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
+// AttributeDesignator identifies a request attribute by its category and its path within the attributes of that
+// category, with nested attributes separated by dots
+type AttributeDesignator struct {
+	Category policyprovider.AttributeCategory `json:"category"`
+	Path     string                           `json:"path"`
+}
+
+// String returns the designator as category.path
+func (a AttributeDesignator) String() string {
+	return fmt.Sprintf("%s.%s", a.Category, a.Path)
+}
+
 // Status provides detailed information about the outcome of the decision process
 type Status struct {
 	Code    StatusCode `json:"code"`
 	Message string     `json:"message"`
+
+	// MissingAttributes lists the attributes the policies needed but could not find in the request, reported with
+	// StatusMissingAttribute
+	MissingAttributes []AttributeDesignator `json:"missingAttributes,omitempty"`
+
+	// FailedResolvers names the optional resolvers that failed, whose policy references were left out of the decision
+	FailedResolvers []string `json:"failedResolvers,omitempty"`
 }
 
 // DecisionResponse represents the result of evaluating an authorization request, including decisions, status, and obligations.
@@ -135,13 +155,15 @@ type DecisionResponse struct {
 	Advice             []Advice            `json:"advice,omitempty"`
 	EvaluatedAt        time.Time           `json:"evaluatedAt"`
 	PolicyIdReferences []PolicyIdReference `json:"policyIdReferences"`
+	Trace              *Trace              `json:"trace,omitempty"`
 }
 
 // Policy represents a retrieved policy that will be evaluated against a request
 type Policy struct {
-	ID      string
-	Version string
-	Content []byte
+	ID          string
+	Version     string
+	Content     []byte
+	ContentHash string // Hex encoded SHA-256 hash of the content as reported by the provider, empty if unknown
 }
 
 // PolicyIdReference represents a reference to a policy, including its unique identifier and version information.
@@ -160,24 +182,37 @@ type PolicyResolver interface {
 type DecisionMaker interface {
 	// MakeDecision evaluates a decision request using policies and returns an authorization decision or an error.
 	MakeDecision(ctx context.Context, req *DecisionRequest) (*DecisionResponse, error)
+
+	// MakeDecisions evaluates multiple decision requests in a single call and returns one decision response per request,
+	// in request order, or an error.
+	MakeDecisions(ctx context.Context, reqs []*DecisionRequest) ([]*DecisionResponse, error)
 }
 
 // decisionMaker implements the DecisionMaker interface
 type decisionMaker struct {
-	processors []PolicyResolver
-	provider   policyprovider.PolicyProvider
-	evaluator  PolicyEvaluator
+	resolvers          []registeredResolver
+	duplicateStrategy  DuplicatePolicyStrategy
+	provider           policyprovider.PolicyProvider
+	evaluator          PolicyEvaluator
+	combiningAlgorithm CombiningAlgorithm
+	trace              bool
+	maxConcurrency     int
 }
 
+// defaultMaxConcurrency bounds the concurrent work of each fan-out of a decision maker unless configured otherwise
+const defaultMaxConcurrency = 16
+
 // Option defines configuration options for DecisionMaker
 type Option func(*decisionMaker)
 
 // NewDecisionMaker creates a new DecisionMaker with the provided dependencies and options
 func NewDecisionMaker(provider policyprovider.PolicyProvider, evaluator PolicyEvaluator, options ...Option) DecisionMaker {
 	dm := &decisionMaker{
-		processors: make([]PolicyResolver, 0),
-		provider:   provider,
-		evaluator:  evaluator,
+		resolvers:         make([]registeredResolver, 0),
+		duplicateStrategy: NewRejectDuplicates(),
+		provider:          provider,
+		evaluator:         evaluator,
+		maxConcurrency:    defaultMaxConcurrency,
 	}
 
 	for _, option := range options {
@@ -187,10 +222,44 @@ func NewDecisionMaker(provider policyprovider.PolicyProvider, evaluator PolicyEv
 	return dm
 }
 
-// WithPolicyResolver registers a policy resolver
-func WithPolicyResolver(processor PolicyResolver) Option {
+// WithPolicyResolver registers a policy resolver, required and without a timeout unless configured otherwise
+func WithPolicyResolver(resolver PolicyResolver, options ...ResolverOption) Option {
+	return func(dm *decisionMaker) {
+		dm.resolvers = append(dm.resolvers, newRegisteredResolver(resolver, options...))
+	}
+}
+
+// WithDuplicatePolicyStrategy sets how references to the same policy ID returned by resolvers are reconciled.
+// Without it, duplicate references fail the resolution.
+func WithDuplicatePolicyStrategy(strategy DuplicatePolicyStrategy) Option {
+	return func(dm *decisionMaker) {
+		dm.duplicateStrategy = strategy
+	}
+}
+
+// WithCombiningAlgorithm evaluates each policy on its own and merges the results with the given combining algorithm.
+// Without it, all policies are passed to the evaluator in a single call and combined by the policies themselves.
+func WithCombiningAlgorithm(algorithm CombiningAlgorithm) Option {
 	return func(dm *decisionMaker) {
-		dm.processors = append(dm.processors, processor)
+		dm.combiningAlgorithm = algorithm
+	}
+}
+
+// WithMaxConcurrency bounds how many requests of a batch, and policies evaluated under a combining algorithm, are
+// processed concurrently, 16 by default. Values below one keep the default.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(dm *decisionMaker) {
+		if maxConcurrency > 0 {
+			dm.maxConcurrency = maxConcurrency
+		}
+	}
+}
+
+// WithTrace attaches an evaluation trace to every decision response.
+// Without it, a trace is only attached when the decision request sets Trace.
+func WithTrace() Option {
+	return func(dm *decisionMaker) {
+		dm.trace = true
 	}
 }
 
@@ -200,10 +269,125 @@ func (d *decisionMaker) MakeDecision(ctx context.Context, req *DecisionRequest)
 		return nil, errors.New("decision request cannot be nil")
 	}
 
+	return d.makeDecision(ctx, req, d.decide), nil
+}
+
+// MakeDecisions evaluates multiple decision requests and returns one decision response per request, in request order.
+// Requests resolving to the same set of policy references share a single policy retrieval.
+func (d *decisionMaker) MakeDecisions(ctx context.Context, reqs []*DecisionRequest) ([]*DecisionResponse, error) {
+	return d.makeDecisions(ctx, reqs, d.decide)
+}
+
+// decideFunc builds the decision response of a request from its resolution and retrieved policies
+type decideFunc func(
+	ctx context.Context,
+	req *DecisionRequest,
+	resolved resolution,
+	policies []Policy,
+	policyErr error,
+	trace *Trace,
+) *DecisionResponse
+
+// makeDecision resolves and retrieves the policies of the request and hands them to decide
+func (d *decisionMaker) makeDecision(ctx context.Context, req *DecisionRequest, decide decideFunc) *DecisionResponse {
+	trace := d.newTrace(req)
+
 	// Resolve applicable policy references for this request
-	policyRefs, err := d.resolve(ctx, req)
+	resolved, response := d.resolvePolicyRefs(ctx, req, trace)
+	if response == nil {
+		// Retrieve policy contents
+		policies, err := d.getPolicies(ctx, resolved.policyRefs)
+		trace.setPolicies(policies)
+
+		response = decide(ctx, req, resolved, policies, err, trace)
+	}
+
+	response.Trace = trace
+	return response
+}
+
+// makeDecisions resolves the policies of every request, retrieves them once per distinct list of references and hands
+// them to decide
+func (d *decisionMaker) makeDecisions(ctx context.Context, reqs []*DecisionRequest, decide decideFunc) ([]*DecisionResponse, error) {
+	for idx, req := range reqs {
+		if req == nil {
+			return nil, fmt.Errorf("decision request at index %d cannot be nil", idx)
+		}
+	}
+
+	responses := make([]*DecisionResponse, len(reqs))
+	resolutions := make([]resolution, len(reqs))
+	traces := make([]*Trace, len(reqs))
+
+	// Resolve applicable policy references for each request
+	d.forEach(len(reqs), func(idx int) {
+		traces[idx] = d.newTrace(reqs[idx])
+		resolutions[idx], responses[idx] = d.resolvePolicyRefs(ctx, reqs[idx], traces[idx])
+	})
+
+	// Group the remaining requests by their ordered policy references, so each list is retrieved once
+	groups := make(map[string]int)
+	groupRefs := make([][]PolicyIdReference, 0)
+	pending := make([]int, 0, len(reqs))
+	requestGroups := make([]int, len(reqs))
+	for idx := range reqs {
+		if responses[idx] != nil {
+			continue
+		}
+
+		key := policyRefsKey(resolutions[idx].policyRefs)
+		group, exists := groups[key]
+		if !exists {
+			group = len(groupRefs)
+			groups[key] = group
+			groupRefs = append(groupRefs, resolutions[idx].policyRefs)
+		}
+
+		pending = append(pending, idx)
+		requestGroups[idx] = group
+	}
+
+	// Retrieve each list of policies
+	policies := make([][]Policy, len(groupRefs))
+	policyErrs := make([]error, len(groupRefs))
+	d.forEach(len(groupRefs), func(group int) {
+		policies[group], policyErrs[group] = d.getPolicies(ctx, groupRefs[group])
+	})
+
+	// Evaluate the remaining requests against the policies of their group
+	d.forEach(len(pending), func(pendingIdx int) {
+		idx := pending[pendingIdx]
+		group := requestGroups[idx]
+		traces[idx].setPolicies(policies[group])
+		responses[idx] = decide(ctx, reqs[idx], resolutions[idx], policies[group], policyErrs[group], traces[idx])
+	})
+
+	for idx, response := range responses {
+		response.Trace = traces[idx]
+	}
+
+	return responses, nil
+}
+
+// newTrace returns an empty trace when tracing is enabled for the request, or nil otherwise
+func (d *decisionMaker) newTrace(req *DecisionRequest) *Trace {
+	if !d.trace && !req.Trace {
+		return nil
+	}
+
+	return &Trace{}
+}
+
+// resolvePolicyRefs resolves the policy references applicable to the request.
+// When no decision can be made from the references, it returns the final decision response instead.
+func (d *decisionMaker) resolvePolicyRefs(
+	ctx context.Context,
+	req *DecisionRequest,
+	trace *Trace,
+) (resolution, *DecisionResponse) {
+	resolved, err := d.resolve(ctx, req, trace)
 	if err != nil {
-		return &DecisionResponse{
+		return resolution{}, &DecisionResponse{
 			RequestID: req.RequestID,
 			Decision:  Indeterminate,
 			Status: &Status{
@@ -211,139 +395,216 @@ func (d *decisionMaker) MakeDecision(ctx context.Context, req *DecisionRequest)
 				Message: fmt.Sprintf("Failed to resolve policies: %v", err),
 			},
 			EvaluatedAt: time.Now(),
-		}, nil
+		}
 	}
 
-	if len(policyRefs) == 0 {
-		return &DecisionResponse{
+	if len(resolved.policyRefs) == 0 {
+		return resolution{}, &DecisionResponse{
 			RequestID: req.RequestID,
 			Decision:  NotApplicable,
 			Status: &Status{
-				Code:    StatusPolicyNotFound,
-				Message: "No applicable policies found for the request",
+				Code:            StatusPolicyNotFound,
+				Message:         "No applicable policies found for the request",
+				FailedResolvers: resolved.failedResolvers,
 			},
 			EvaluatedAt: time.Now(),
-		}, nil
-	}
-
-	// Retrieve policy contents
-	policies, err := d.getPolicies(ctx, policyRefs)
-	if err != nil {
-		return &DecisionResponse{
-			RequestID: req.RequestID,
-			Decision:  Indeterminate,
-			Status: &Status{
-				Code:    StatusProcessingError,
-				Message: fmt.Sprintf("Failed to retrieve policies: %v", err),
-			},
-			EvaluatedAt:        time.Now(),
-			PolicyIdReferences: policyRefs,
-		}, nil
+		}
 	}
 
-	// Evaluate the request against policies
-	result, err := d.evaluator.Evaluate(ctx, req, policies)
-	if err != nil {
-		return &DecisionResponse{
-			RequestID: req.RequestID,
-			Decision:  Indeterminate,
-			Status: &Status{
-				Code:    StatusEvaluationError,
-				Message: fmt.Sprintf("Policy evaluation failed: %v", err),
-			},
-			EvaluatedAt:        time.Now(),
-			PolicyIdReferences: policyRefs,
-		}, nil
-	}
+	return resolved, nil
+}
 
-	// Build the final decision response with evaluation results, request metadata, and policy information
+// decide evaluates the request against the retrieved policies and builds the decision response.
+// A non-nil policyErr reports a failed policy retrieval and results in an Indeterminate decision.
+func (d *decisionMaker) decide(
+	ctx context.Context,
+	req *DecisionRequest,
+	resolved resolution,
+	policies []Policy,
+	policyErr error,
+	trace *Trace,
+) *DecisionResponse {
 	response := &DecisionResponse{
 		RequestID:          req.RequestID,
-		Decision:           result.Decision,
-		Status:             &result.Status,
-		Obligations:        result.Obligations,
-		Advice:             result.Advice,
 		EvaluatedAt:        time.Now(),
-		PolicyIdReferences: policyRefs,
+		PolicyIdReferences: resolved.policyRefs,
+	}
+
+	if policyErr != nil {
+		response.Decision = Indeterminate
+		response.Status = &Status{
+			Code:    StatusProcessingError,
+			Message: fmt.Sprintf("Failed to retrieve policies: %v", policyErr),
+		}
+	} else if result, err := d.evaluate(ctx, req, policies, trace); err != nil {
+		// Evaluate the request against policies
+		response.Decision = Indeterminate
+		response.Status = &Status{
+			Code:    StatusEvaluationError,
+			Message: fmt.Sprintf("Policy evaluation failed: %v", err),
+		}
+	} else {
+		status := result.Status
+		response.Decision = result.Decision
+		response.Status = &status
+		response.Obligations = result.Obligations
+		response.Advice = result.Advice
+	}
+
+	if len(resolved.failedResolvers) > 0 {
+		response.Status.FailedResolvers = resolved.failedResolvers
 	}
 
-	return response, nil
+	return response
+}
+
+// resolution is the outcome of resolving the policies applicable to a request
+type resolution struct {
+	policyRefs      []PolicyIdReference
+	failedResolvers []string // Names of the failed optional resolvers, in registration order
 }
 
-// resolve executes the resolution process for a decision request using configured processors and returns unique policy references.
-func (d *decisionMaker) resolve(ctx context.Context, req *DecisionRequest) ([]PolicyIdReference, error) {
-	if len(d.processors) == 0 {
-		return nil, errors.New("no policy resolve processors configured")
+// resolve executes the resolution process for a decision request using configured resolvers and returns unique policy
+// references. A failing required resolver cancels the others and fails the resolution, naming the resolver in the
+// error; a failing optional resolver contributes no references and is named in the resolution.
+func (d *decisionMaker) resolve(ctx context.Context, req *DecisionRequest, trace *Trace) (resolution, error) {
+	if len(d.resolvers) == 0 {
+		return resolution{}, errors.New("no policy resolve processors configured")
 	}
 
+	// Record each resolver's outcome by registration index for tracing
+	resolved := make([][]PolicyIdReference, len(d.resolvers))
+	resolveErrs := make([]error, len(d.resolvers))
+	defer func() {
+		trace.setResolutions(d.resolvers, resolved, resolveErrs)
+	}()
+
 	// Create an error group to manage parallel execution
 	g, ctx := errgroup.WithContext(ctx)
 
-	// Use mutex to protect the shared map
-	var mu sync.Mutex
-	seen := make(map[string]PolicyIdReference)
-
-	// Launch each processor in its own goroutine
-	for _, processor := range d.processors {
-		proc := processor
+	// Launch each resolver in its own goroutine
+	for idx, resolver := range d.resolvers {
 		g.Go(func() error {
-			// Check if context was canceled before processing
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
+			results, err := resolver.resolve(ctx, req)
+			resolveErrs[idx] = err
+			if err != nil {
+				if resolver.optional {
+					return nil
+				}
+
+				return fmt.Errorf("policy resolver %s failed: %w", resolver.name, err)
 			}
 
-			// Resolve the request
-			results, err := proc.Resolve(ctx, req)
-			if err != nil {
-				return err
+			resolved[idx] = results
+			return nil
+		})
+	}
+
+	// Wait for all resolvers to complete or first error
+	if err := g.Wait(); err != nil {
+		return resolution{}, err
+	}
+
+	policyRefs, err := d.mergePolicyRefs(resolved)
+	if err != nil {
+		return resolution{}, err
+	}
+
+	var failedResolvers []string
+	for idx, resolver := range d.resolvers {
+		if resolveErrs[idx] != nil {
+			failedResolvers = append(failedResolvers, resolver.name)
+		}
+	}
+
+	return resolution{policyRefs: policyRefs, failedResolvers: failedResolvers}, nil
+}
+
+// mergePolicyRefs merges the references returned by each resolver, ordered by resolver registration and then by
+// policy ID, reconciling references to the same policy ID with the duplicate policy strategy. A merged reference keeps
+// the position of the first reference to its policy.
+func (d *decisionMaker) mergePolicyRefs(resolved [][]PolicyIdReference) ([]PolicyIdReference, error) {
+	policyRefs := make([]PolicyIdReference, 0)
+	positions := make(map[string]int)
+
+	for _, refs := range resolved {
+		sorted := slices.SortedStableFunc(slices.Values(refs), func(a, b PolicyIdReference) int {
+			return strings.Compare(a.ID, b.ID)
+		})
+
+		for _, ref := range sorted {
+			position, exists := positions[ref.ID]
+			if !exists {
+				positions[ref.ID] = len(policyRefs)
+				policyRefs = append(policyRefs, ref)
+				continue
 			}
 
-			// Skip lock if no results
-			if len(results) == 0 {
-				return nil
+			merged, err := d.duplicateStrategy.Merge(policyRefs[position], ref)
+			if err != nil {
+				return nil, err
 			}
 
-			// Safely add results to the shared map using policy ID as the key
-			mu.Lock()
-			defer mu.Unlock()
-			for _, policyRef := range results {
-				existingPolicyRef, exists := seen[policyRef.ID]
+			policyRefs[position] = merged
+		}
+	}
 
-				if !exists {
-					seen[policyRef.ID] = policyRef
-					continue
-				}
+	return policyRefs, nil
+}
 
-				if existingPolicyRef.Version == policyRef.Version {
-					return fmt.Errorf(
-						"duplicate policy reference detected: policy '%s' version '%s' returned by multiple processors",
-						existingPolicyRef.ID,
-						existingPolicyRef.Version,
-					)
-				}
+// evaluate evaluates the request against the policies, either in a single evaluator call or, when a combining
+// algorithm is configured, per policy with the results merged by the algorithm.
+func (d *decisionMaker) evaluate(ctx context.Context, req *DecisionRequest, policies []Policy, trace *Trace) (*EvaluationResult, error) {
+	if trace != nil {
+		ctx = WithTraceContext(ctx)
+	}
 
-				return fmt.Errorf("duplicate policy ID '%s' found: existing version '%s', conflicting version '%s'",
-					policyRef.ID, existingPolicyRef.Version, policyRef.Version)
-			}
+	if d.combiningAlgorithm == nil {
+		result, err := d.evaluator.Evaluate(ctx, req, policies)
+		if err == nil && result == nil {
+			err = errNoEvaluationResult
+		}
 
-			return nil
-		})
+		trace.addEvaluation(policies, result, err)
+		return result, err
 	}
 
-	// Wait for all processors to complete or first error
-	if err := g.Wait(); err != nil {
-		return nil, err
+	evaluated := make([]*EvaluationResult, len(policies))
+	evaluateErrs := make([]error, len(policies))
+	d.forEach(len(policies), func(idx int) {
+		evaluated[idx], evaluateErrs[idx] = d.evaluator.Evaluate(ctx, req, []Policy{policies[idx]})
+	})
+
+	results := make([]EvaluationResult, 0, len(policies))
+	for idx, policy := range policies {
+		trace.addEvaluation([]Policy{policy}, evaluated[idx], evaluateErrs[idx])
+		results = append(results, policyResult(policy, evaluated[idx], evaluateErrs[idx]))
 	}
 
-	// Convert the map values to a slice
-	policyRefs := make([]PolicyIdReference, 0, len(seen))
-	for _, policyRef := range seen {
-		policyRefs = append(policyRefs, policyRef)
+	return d.combiningAlgorithm.Combine(results), nil
+}
+
+// errNoEvaluationResult reports an evaluator returning neither a result nor an error
+var errNoEvaluationResult = errors.New("evaluator returned no result")
+
+// policyResult returns the evaluation result of a single policy, reporting evaluation errors, and missing results, as
+// an Indeterminate result
+func policyResult(policy Policy, result *EvaluationResult, err error) EvaluationResult {
+	if err == nil && result == nil {
+		err = errNoEvaluationResult
 	}
 
-	return policyRefs, nil
+	if err != nil {
+		return EvaluationResult{
+			Decision: Indeterminate,
+			Status: Status{
+				Code:    StatusEvaluationError,
+				Message: fmt.Sprintf("Policy '%s' evaluation failed: %v", policy.ID, err),
+			},
+		}
+	}
+
+	return *result
 }
 
 // getPolicies retrieves policy content for a list of policy references
@@ -371,11 +632,38 @@ func (d *decisionMaker) getPolicies(ctx context.Context, policyRefs []PolicyIdRe
 	policies := make([]Policy, 0, len(responses))
 	for _, resp := range responses {
 		policies = append(policies, Policy{
-			ID:      resp.ID,
-			Version: resp.Version,
-			Content: resp.Content,
+			ID:          resp.ID,
+			Version:     resp.Version,
+			Content:     resp.Content,
+			ContentHash: resp.ContentHash,
 		})
 	}
 
 	return policies, nil
 }
+
+// policyRefsKey returns a key identifying the ordered list of policy references, as their order determines the
+// order of evaluation and combination
+func policyRefsKey(policyRefs []PolicyIdReference) string {
+	keys := make([]string, 0, len(policyRefs))
+	for _, ref := range policyRefs {
+		keys = append(keys, ref.ID+"@"+ref.Version)
+	}
+
+	return strings.Join(keys, "\n")
+}
+
+// forEach calls fn for every index in [0, n) concurrently, at most maxConcurrency at a time, and waits for all calls
+// to return
+func (d *decisionMaker) forEach(n int, fn func(idx int)) {
+	var g errgroup.Group
+	g.SetLimit(d.maxConcurrency)
+	for idx := range n {
+		g.Go(func() error {
+			fn(idx)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+}
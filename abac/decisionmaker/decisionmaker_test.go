@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -154,7 +155,7 @@ func TestDecisionMaker_MakeDecision(t *testing.T) {
 				Decision:  Indeterminate,
 				Status: &Status{
 					Code:    StatusProcessingError,
-					Message: "Failed to resolve policies: resolver error",
+					Message: "Failed to resolve policies: policy resolver *decisionmaker.mockPolicyResolver failed: resolver error",
 				},
 				EvaluatedAt: time.Now(),
 			},
@@ -221,6 +222,27 @@ func TestDecisionMaker_MakeDecision(t *testing.T) {
 			},
 		},
 
+		"should return indeterminate decision when evaluator returns no result": {
+			request: standardRequest,
+			policyResolverConfigs: []*mockPolicyResolverConfig{
+				{policyIdRefs: []PolicyIdReference{{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"}}},
+			},
+			policyProviderResponses: policyResponses,
+			expectedResponse: &DecisionResponse{
+				RequestID: fixedUUID,
+				Decision:  Indeterminate,
+				Status: &Status{
+					Code:    StatusEvaluationError,
+					Message: "Policy evaluation failed: evaluator returned no result",
+				},
+				EvaluatedAt: time.Now(),
+				PolicyIdReferences: []PolicyIdReference{
+					{ID: "policy1", Version: "1.0"},
+					{ID: "policy2", Version: "1.0"},
+				},
+			},
+		},
+
 		"should return permit decision with obligations and advice": {
 			request: standardRequest,
 			policyResolverConfigs: []*mockPolicyResolverConfig{
@@ -440,6 +462,718 @@ func TestDecisionMaker_MakeDecision(t *testing.T) {
 	}
 }
 
+// TestDecisionMaker_MakeDecisionWithResolverOptions tests optional resolvers and resolver timeouts
+func TestDecisionMaker_MakeDecisionWithResolverOptions(t *testing.T) {
+	request := &DecisionRequest{
+		RequestID: uuid.New(),
+		Subject:   Subject{ID: "user123", Type: "user"},
+		Resource:  Resource{ID: "resource456", Type: "document"},
+		Action:    Action{ID: "read"},
+	}
+
+	tests := map[string]struct {
+		delay            time.Duration
+		partialRefs      []PolicyIdReference
+		err              error
+		options          []ResolverOption
+		expectedDecision Decision
+		expectedMessage  string
+		expectedRefs     []PolicyIdReference
+		expectedFailed   []string
+	}{
+		"should ignore failing optional resolver": {
+			err:              errors.New("flag service unavailable"),
+			options:          []ResolverOption{OptionalResolver(), WithResolverName("feature-flags")},
+			expectedDecision: Permit,
+			expectedRefs:     []PolicyIdReference{{ID: "policy1", Version: "1.0"}},
+			expectedFailed:   []string{"feature-flags"},
+		},
+		"should leave out partial references of failing optional resolver": {
+			partialRefs:      []PolicyIdReference{{ID: "policy2", Version: "1.0"}},
+			err:              errors.New("flag service unavailable"),
+			options:          []ResolverOption{OptionalResolver(), WithResolverName("feature-flags")},
+			expectedDecision: Permit,
+			expectedRefs:     []PolicyIdReference{{ID: "policy1", Version: "1.0"}},
+			expectedFailed:   []string{"feature-flags"},
+		},
+		"should ignore optional resolver exceeding its timeout": {
+			delay:            time.Second,
+			options:          []ResolverOption{OptionalResolver(), WithResolverName("feature-flags"), WithResolverTimeout(10 * time.Millisecond)},
+			expectedDecision: Permit,
+			expectedRefs:     []PolicyIdReference{{ID: "policy1", Version: "1.0"}},
+			expectedFailed:   []string{"feature-flags"},
+		},
+		"should name failing required resolver in status": {
+			err:              errors.New("flag service unavailable"),
+			options:          []ResolverOption{WithResolverName("feature-flags")},
+			expectedDecision: Indeterminate,
+			expectedMessage:  "Failed to resolve policies: policy resolver feature-flags failed: flag service unavailable",
+		},
+		"should fail required resolver exceeding its timeout": {
+			delay:            time.Second,
+			options:          []ResolverOption{WithResolverName("feature-flags"), WithResolverTimeout(10 * time.Millisecond)},
+			expectedDecision: Indeterminate,
+			expectedMessage:  "Failed to resolve policies: policy resolver feature-flags failed: timed out after 10ms: context deadline exceeded",
+		},
+		"should use resolver completing within its timeout": {
+			options:          []ResolverOption{WithResolverTimeout(time.Second)},
+			expectedDecision: Permit,
+			expectedRefs:     []PolicyIdReference{{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProvider := new(mockPolicyProvider)
+			mockEvaluator := new(mockPolicyEvaluator)
+
+			primary := new(mockPolicyResolver)
+			primary.On("Resolve", mock.Anything, request).Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}}, nil)
+
+			secondary := &mockPolicyResolver{delay: tc.delay}
+			if tc.err != nil {
+				secondary.On("Resolve", mock.Anything, request).Return(tc.partialRefs, tc.err)
+			} else {
+				secondary.On("Resolve", mock.Anything, request).Maybe().Return([]PolicyIdReference{{ID: "policy2", Version: "1.0"}}, nil)
+			}
+
+			mockProvider.On("GetPolicies", mock.Anything, mock.Anything).Maybe().Return([]policyprovider.PolicyResponse{}, nil)
+			mockEvaluator.On("Evaluate", mock.Anything, request, mock.Anything).Maybe().
+				Return(&EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}}, nil)
+
+			dm := NewDecisionMaker(
+				mockProvider,
+				mockEvaluator,
+				WithPolicyResolver(primary),
+				WithPolicyResolver(secondary, tc.options...),
+			)
+
+			response, err := dm.MakeDecision(context.Background(), request)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDecision, response.Decision)
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, response.Status.Message)
+			}
+			assert.Equal(t, tc.expectedRefs, response.PolicyIdReferences)
+			assert.Equal(t, tc.expectedFailed, response.Status.FailedResolvers)
+		})
+	}
+}
+
+// TestDecisionMaker_MakeDecisionWithFailedOptionalResolvers tests the status when every optional resolver fails
+func TestDecisionMaker_MakeDecisionWithFailedOptionalResolvers(t *testing.T) {
+	request := &DecisionRequest{
+		RequestID: uuid.New(),
+		Subject:   Subject{ID: "user123", Type: "user"},
+		Resource:  Resource{ID: "resource456", Type: "document"},
+		Action:    Action{ID: "read"},
+	}
+
+	flags := new(mockPolicyResolver)
+	flags.On("Resolve", mock.Anything, request).Return(nil, errors.New("flag service unavailable"))
+
+	tenants := new(mockPolicyResolver)
+	tenants.On("Resolve", mock.Anything, request).Return(nil, errors.New("tenant service unavailable"))
+
+	dm := NewDecisionMaker(
+		new(mockPolicyProvider),
+		new(mockPolicyEvaluator),
+		WithPolicyResolver(flags, OptionalResolver(), WithResolverName("feature-flags")),
+		WithPolicyResolver(tenants, OptionalResolver(), WithResolverName("tenants")),
+	)
+
+	response, err := dm.MakeDecision(context.Background(), request)
+
+	require.NoError(t, err)
+	assert.Equal(t, NotApplicable, response.Decision)
+	assert.Equal(t, StatusPolicyNotFound, response.Status.Code)
+	assert.Equal(t, []string{"feature-flags", "tenants"}, response.Status.FailedResolvers)
+}
+
+// TestDecisionMaker_MakeDecisionWithDuplicatePolicyStrategy tests ordering and merging of resolved policy references
+func TestDecisionMaker_MakeDecisionWithDuplicatePolicyStrategy(t *testing.T) {
+	request := &DecisionRequest{
+		RequestID: uuid.New(),
+		Subject:   Subject{ID: "user123", Type: "user"},
+		Resource:  Resource{ID: "resource456", Type: "document"},
+		Action:    Action{ID: "read"},
+	}
+
+	tests := map[string]struct {
+		options          []Option
+		secondaryRefs    []PolicyIdReference
+		expectedDecision Decision
+		expectedMessage  string
+		expectedRefs     []PolicyIdReference
+	}{
+		"should order references by resolver registration and policy ID": {
+			secondaryRefs:    []PolicyIdReference{{ID: "policy4", Version: "1.0"}, {ID: "policy2", Version: "1.0"}},
+			expectedDecision: Permit,
+			expectedRefs: []PolicyIdReference{
+				{ID: "policy1", Version: "1.0"}, {ID: "policy3", Version: "1.0"},
+				{ID: "policy2", Version: "1.0"}, {ID: "policy4", Version: "1.0"},
+			},
+		},
+		"should reject duplicate references by default": {
+			secondaryRefs:    []PolicyIdReference{{ID: "policy1", Version: "1.0"}},
+			expectedDecision: Indeterminate,
+			expectedMessage: "Failed to resolve policies: duplicate policy reference detected: " +
+				"policy 'policy1' version '1.0' returned by multiple processors",
+		},
+		"should dedupe references to the same version": {
+			options:          []Option{WithDuplicatePolicyStrategy(NewDedupeSameVersion())},
+			secondaryRefs:    []PolicyIdReference{{ID: "policy2", Version: "1.0"}, {ID: "policy1", Version: "1.0"}},
+			expectedDecision: Permit,
+			expectedRefs: []PolicyIdReference{
+				{ID: "policy1", Version: "1.0"}, {ID: "policy3", Version: "1.0"}, {ID: "policy2", Version: "1.0"},
+			},
+		},
+		"should keep highest version in position of first reference": {
+			options:          []Option{WithDuplicatePolicyStrategy(NewPreferHighestVersion())},
+			secondaryRefs:    []PolicyIdReference{{ID: "policy2", Version: "1.0"}, {ID: "policy1", Version: "1.10"}},
+			expectedDecision: Permit,
+			expectedRefs: []PolicyIdReference{
+				{ID: "policy1", Version: "1.10"}, {ID: "policy3", Version: "1.0"}, {ID: "policy2", Version: "1.0"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProvider := new(mockPolicyProvider)
+			mockEvaluator := new(mockPolicyEvaluator)
+
+			// The first registered resolver completes last, so ordering does not depend on completion order
+			primary := &mockPolicyResolver{delay: 10 * time.Millisecond}
+			primary.On("Resolve", mock.Anything, request).
+				Return([]PolicyIdReference{{ID: "policy3", Version: "1.0"}, {ID: "policy1", Version: "1.0"}}, nil)
+
+			secondary := new(mockPolicyResolver)
+			secondary.On("Resolve", mock.Anything, request).Return(tc.secondaryRefs, nil)
+
+			mockProvider.On("GetPolicies", mock.Anything, mock.Anything).Maybe().Return([]policyprovider.PolicyResponse{}, nil)
+			mockEvaluator.On("Evaluate", mock.Anything, request, mock.Anything).Maybe().
+				Return(&EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}}, nil)
+
+			options := append([]Option{WithPolicyResolver(primary), WithPolicyResolver(secondary)}, tc.options...)
+			dm := NewDecisionMaker(mockProvider, mockEvaluator, options...)
+
+			response, err := dm.MakeDecision(context.Background(), request)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDecision, response.Decision)
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, response.Status.Message)
+			}
+			assert.Equal(t, tc.expectedRefs, response.PolicyIdReferences)
+		})
+	}
+}
+
+// TestDecisionMaker_MakeDecisionWithCombiningAlgorithm tests per-policy evaluation merged by a combining algorithm
+func TestDecisionMaker_MakeDecisionWithCombiningAlgorithm(t *testing.T) {
+	fixedUUID := uuid.New()
+	request := &DecisionRequest{
+		RequestID: fixedUUID,
+		Subject:   Subject{ID: "user123", Type: "user"},
+		Resource:  Resource{ID: "resource456", Type: "document"},
+		Action:    Action{ID: "read"},
+	}
+
+	policy1 := Policy{ID: "policy1", Version: "1.0", Content: []byte(`{"policy": "content1"}`)}
+	policy2 := Policy{ID: "policy2", Version: "1.0", Content: []byte(`{"policy": "content2"}`)}
+
+	permitResult := &EvaluationResult{
+		Decision:    Permit,
+		Status:      Status{Code: StatusOK, Message: "Access permitted"},
+		Obligations: []Obligation{{ID: "log-access"}},
+	}
+	denyResult := &EvaluationResult{
+		Decision:    Deny,
+		Status:      Status{Code: StatusOK, Message: "Access denied"},
+		Obligations: []Obligation{{ID: "log-denial"}},
+	}
+
+	type evaluation struct {
+		result *EvaluationResult
+		err    error
+	}
+
+	tests := map[string]struct {
+		algorithm        CombiningAlgorithm
+		evaluations      map[string]evaluation
+		expectedResponse *DecisionResponse
+	}{
+		"should deny when one policy denies under deny-overrides": {
+			algorithm: NewDenyOverrides(),
+			evaluations: map[string]evaluation{
+				"policy1": {result: permitResult},
+				"policy2": {result: denyResult},
+			},
+			expectedResponse: &DecisionResponse{
+				RequestID:   fixedUUID,
+				Decision:    Deny,
+				Status:      &Status{Code: StatusOK, Message: "Access denied"},
+				Obligations: []Obligation{{ID: "log-denial"}},
+				EvaluatedAt: time.Now(),
+				PolicyIdReferences: []PolicyIdReference{
+					{ID: "policy1", Version: "1.0"},
+					{ID: "policy2", Version: "1.0"},
+				},
+			},
+		},
+
+		"should permit when one policy permits under permit-overrides": {
+			algorithm: NewPermitOverrides(),
+			evaluations: map[string]evaluation{
+				"policy1": {result: permitResult},
+				"policy2": {result: denyResult},
+			},
+			expectedResponse: &DecisionResponse{
+				RequestID:   fixedUUID,
+				Decision:    Permit,
+				Status:      &Status{Code: StatusOK, Message: "Access permitted"},
+				Obligations: []Obligation{{ID: "log-access"}},
+				EvaluatedAt: time.Now(),
+				PolicyIdReferences: []PolicyIdReference{
+					{ID: "policy1", Version: "1.0"},
+					{ID: "policy2", Version: "1.0"},
+				},
+			},
+		},
+
+		"should treat a policy without result as indeterminate": {
+			algorithm: NewDenyOverrides(),
+			evaluations: map[string]evaluation{
+				"policy1": {result: permitResult},
+				"policy2": {},
+			},
+			expectedResponse: &DecisionResponse{
+				RequestID: fixedUUID,
+				Decision:  Indeterminate,
+				Status: &Status{
+					Code:    StatusEvaluationError,
+					Message: "Policy 'policy2' evaluation failed: evaluator returned no result",
+				},
+				EvaluatedAt: time.Now(),
+				PolicyIdReferences: []PolicyIdReference{
+					{ID: "policy1", Version: "1.0"},
+					{ID: "policy2", Version: "1.0"},
+				},
+			},
+		},
+
+		"should treat a failing policy as indeterminate": {
+			algorithm: NewDenyOverrides(),
+			evaluations: map[string]evaluation{
+				"policy1": {result: permitResult},
+				"policy2": {err: errors.New("evaluator error")},
+			},
+			expectedResponse: &DecisionResponse{
+				RequestID: fixedUUID,
+				Decision:  Indeterminate,
+				Status: &Status{
+					Code:    StatusEvaluationError,
+					Message: "Policy 'policy2' evaluation failed: evaluator error",
+				},
+				EvaluatedAt: time.Now(),
+				PolicyIdReferences: []PolicyIdReference{
+					{ID: "policy1", Version: "1.0"},
+					{ID: "policy2", Version: "1.0"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockProvider := new(mockPolicyProvider)
+			mockEvaluator := new(mockPolicyEvaluator)
+			resolver := new(mockPolicyResolver)
+
+			resolver.On("Resolve", mock.Anything, request).Return([]PolicyIdReference{
+				{ID: "policy1", Version: "1.0"},
+				{ID: "policy2", Version: "1.0"},
+			}, nil)
+
+			mockProvider.On("GetPolicies", mock.Anything, mock.Anything).Return([]policyprovider.PolicyResponse{
+				{ID: policy1.ID, Version: policy1.Version, Content: policy1.Content},
+				{ID: policy2.ID, Version: policy2.Version, Content: policy2.Content},
+			}, nil)
+
+			for _, policy := range []Policy{policy1, policy2} {
+				eval := tc.evaluations[policy.ID]
+				mockEvaluator.On("Evaluate", mock.Anything, request, []Policy{policy}).Return(eval.result, eval.err)
+			}
+
+			dm := NewDecisionMaker(
+				mockProvider,
+				mockEvaluator,
+				WithPolicyResolver(resolver),
+				WithCombiningAlgorithm(tc.algorithm),
+			)
+
+			response, err := dm.MakeDecision(context.Background(), request)
+
+			assert.NoError(t, err)
+			assertResponseMatch(t, tc.expectedResponse, response)
+			mockProvider.AssertExpectations(t)
+			mockEvaluator.AssertExpectations(t)
+			resolver.AssertExpectations(t)
+		})
+	}
+}
+
+// TestDecisionMaker_MakeDecisions tests the DecisionMaker's MakeDecisions method
+func TestDecisionMaker_MakeDecisions(t *testing.T) {
+	newRequest := func(resourceID string) *DecisionRequest {
+		return &DecisionRequest{
+			RequestID: uuid.New(),
+			Subject:   Subject{ID: "user123", Type: "user"},
+			Resource:  Resource{ID: resourceID, Type: "order"},
+			Action:    Action{ID: "read"},
+		}
+	}
+
+	sharedRefs := []PolicyIdReference{{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"}}
+	reorderedSharedRefs := []PolicyIdReference{{ID: "policy2", Version: "1.0"}, {ID: "policy1", Version: "1.0"}}
+	otherRefs := []PolicyIdReference{{ID: "policy3", Version: "1.0"}}
+
+	sharedResponses := []policyprovider.PolicyResponse{
+		{ID: "policy1", Version: "1.0", Content: []byte(`{"policy": "content1"}`)},
+		{ID: "policy2", Version: "1.0", Content: []byte(`{"policy": "content2"}`)},
+	}
+	otherResponses := []policyprovider.PolicyResponse{
+		{ID: "policy3", Version: "1.0", Content: []byte(`{"policy": "content3"}`)},
+	}
+
+	permitResult := &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK, Message: "Access permitted"}}
+	denyResult := &EvaluationResult{Decision: Deny, Status: Status{Code: StatusOK, Message: "Access denied"}}
+
+	t.Run("should return error when any request is nil", func(t *testing.T) {
+		dm := NewDecisionMaker(new(mockPolicyProvider), new(mockPolicyEvaluator))
+
+		responses, err := dm.MakeDecisions(context.Background(), []*DecisionRequest{newRequest("order1"), nil})
+
+		assert.Nil(t, responses)
+		assert.EqualError(t, err, "decision request at index 1 cannot be nil")
+	})
+
+	t.Run("should return empty responses for empty requests", func(t *testing.T) {
+		dm := NewDecisionMaker(new(mockPolicyProvider), new(mockPolicyEvaluator))
+
+		responses, err := dm.MakeDecisions(context.Background(), []*DecisionRequest{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, responses)
+	})
+
+	t.Run("should retrieve each policy list once and return responses in request order", func(t *testing.T) {
+		mockProvider := new(mockPolicyProvider)
+		mockEvaluator := new(mockPolicyEvaluator)
+		resolver := new(mockPolicyResolver)
+
+		order1, order2, order3, order4 := newRequest("order1"), newRequest("order2"), newRequest("order3"), newRequest("order4")
+
+		resolver.On("Resolve", mock.Anything, order1).Return(sharedRefs, nil)
+		resolver.On("Resolve", mock.Anything, order2).Return(otherRefs, nil)
+		resolver.On("Resolve", mock.Anything, order3).Return(reorderedSharedRefs, nil)
+		resolver.On("Resolve", mock.Anything, order4).Return([]PolicyIdReference{}, nil)
+
+		mockProvider.On("GetPolicies", mock.Anything, mock.MatchedBy(func(reqs []policyprovider.GetPolicyRequest) bool {
+			return len(reqs) == 2
+		})).Return(sharedResponses, nil).Once()
+		mockProvider.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{{ID: "policy3", Version: "1.0"}}).
+			Return(otherResponses, nil).Once()
+
+		mockEvaluator.On("Evaluate", mock.Anything, order1, mock.Anything).Return(permitResult, nil)
+		mockEvaluator.On("Evaluate", mock.Anything, order2, mock.Anything).Return(denyResult, nil)
+		mockEvaluator.On("Evaluate", mock.Anything, order3, mock.Anything).Return(nil, errors.New("evaluator error"))
+
+		dm := NewDecisionMaker(mockProvider, mockEvaluator, WithPolicyResolver(resolver))
+
+		responses, err := dm.MakeDecisions(context.Background(), []*DecisionRequest{order1, order2, order3, order4})
+
+		require.NoError(t, err)
+		require.Len(t, responses, 4)
+
+		assertResponseMatch(t, &DecisionResponse{
+			RequestID:          order1.RequestID,
+			Decision:           Permit,
+			Status:             &Status{Code: StatusOK, Message: "Access permitted"},
+			PolicyIdReferences: sharedRefs,
+		}, responses[0])
+		assertResponseMatch(t, &DecisionResponse{
+			RequestID:          order2.RequestID,
+			Decision:           Deny,
+			Status:             &Status{Code: StatusOK, Message: "Access denied"},
+			PolicyIdReferences: otherRefs,
+		}, responses[1])
+		assertResponseMatch(t, &DecisionResponse{
+			RequestID:          order3.RequestID,
+			Decision:           Indeterminate,
+			Status:             &Status{Code: StatusEvaluationError, Message: "Policy evaluation failed: evaluator error"},
+			PolicyIdReferences: reorderedSharedRefs,
+		}, responses[2])
+		assertResponseMatch(t, &DecisionResponse{
+			RequestID: order4.RequestID,
+			Decision:  NotApplicable,
+			Status:    &Status{Code: StatusPolicyNotFound, Message: "No applicable policies found for the request"},
+		}, responses[3])
+
+		mockProvider.AssertExpectations(t)
+		mockEvaluator.AssertExpectations(t)
+		resolver.AssertExpectations(t)
+	})
+
+	t.Run("should keep the policy order of each request under first-applicable", func(t *testing.T) {
+		mockProvider := new(mockPolicyProvider)
+		mockEvaluator := new(mockPolicyEvaluator)
+		first, second := new(mockPolicyResolver), new(mockPolicyResolver)
+
+		order1, order2 := newRequest("order1"), newRequest("order2")
+
+		// References are ordered by resolver, so the requests resolve the same policies in opposite orders
+		first.On("Resolve", mock.Anything, order1).Return(sharedRefs[:1], nil)
+		second.On("Resolve", mock.Anything, order1).Return(sharedRefs[1:], nil)
+		first.On("Resolve", mock.Anything, order2).Return(reorderedSharedRefs[:1], nil)
+		second.On("Resolve", mock.Anything, order2).Return(reorderedSharedRefs[1:], nil)
+
+		mockProvider.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{
+			{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"},
+		}).Return(sharedResponses, nil).Once()
+		mockProvider.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{
+			{ID: "policy2", Version: "1.0"}, {ID: "policy1", Version: "1.0"},
+		}).Return([]policyprovider.PolicyResponse{sharedResponses[1], sharedResponses[0]}, nil).Once()
+
+		for _, req := range []*DecisionRequest{order1, order2} {
+			mockEvaluator.On("Evaluate", mock.Anything, req, mock.MatchedBy(func(policies []Policy) bool {
+				return policies[0].ID == "policy1"
+			})).Return(permitResult, nil).Maybe()
+			mockEvaluator.On("Evaluate", mock.Anything, req, mock.MatchedBy(func(policies []Policy) bool {
+				return policies[0].ID == "policy2"
+			})).Return(denyResult, nil).Maybe()
+		}
+
+		dm := NewDecisionMaker(
+			mockProvider,
+			mockEvaluator,
+			WithPolicyResolver(first),
+			WithPolicyResolver(second),
+			WithCombiningAlgorithm(NewFirstApplicable()),
+			WithTrace(),
+		)
+
+		responses, err := dm.MakeDecisions(context.Background(), []*DecisionRequest{order1, order2})
+
+		require.NoError(t, err)
+		require.Len(t, responses, 2)
+		assert.Equal(t, Permit, responses[0].Decision)
+		assert.Equal(t, sharedRefs, responses[0].Trace.Policies)
+		assert.Equal(t, Deny, responses[1].Decision)
+		assert.Equal(t, reorderedSharedRefs, responses[1].Trace.Policies)
+
+		mockProvider.AssertExpectations(t)
+	})
+
+	t.Run("should return indeterminate for every request sharing a failed policy list", func(t *testing.T) {
+		mockProvider := new(mockPolicyProvider)
+		resolver := new(mockPolicyResolver)
+
+		order1, order2 := newRequest("order1"), newRequest("order2")
+
+		resolver.On("Resolve", mock.Anything, mock.Anything).Return(otherRefs, nil)
+		mockProvider.On("GetPolicies", mock.Anything, mock.Anything).Return(nil, errors.New("provider error")).Once()
+
+		dm := NewDecisionMaker(mockProvider, new(mockPolicyEvaluator), WithPolicyResolver(resolver))
+
+		responses, err := dm.MakeDecisions(context.Background(), []*DecisionRequest{order1, order2})
+
+		require.NoError(t, err)
+		require.Len(t, responses, 2)
+		for idx, req := range []*DecisionRequest{order1, order2} {
+			assertResponseMatch(t, &DecisionResponse{
+				RequestID: req.RequestID,
+				Decision:  Indeterminate,
+				Status: &Status{
+					Code:    StatusProcessingError,
+					Message: "Failed to retrieve policies: failed to retrieve policies: provider error",
+				},
+				PolicyIdReferences: otherRefs,
+			}, responses[idx])
+		}
+
+		mockProvider.AssertExpectations(t)
+	})
+}
+
+// concurrencyEvaluator records the highest number of concurrent Evaluate calls
+type concurrencyEvaluator struct {
+	mu      sync.Mutex
+	active  int
+	highest int
+}
+
+func (e *concurrencyEvaluator) Evaluate(context.Context, *DecisionRequest, []Policy) (*EvaluationResult, error) {
+	e.mu.Lock()
+	e.active++
+	e.highest = max(e.highest, e.active)
+	e.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	e.mu.Lock()
+	e.active--
+	e.mu.Unlock()
+
+	return &EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}}, nil
+}
+
+// TestDecisionMaker_MakeDecisionsWithMaxConcurrency tests that batched evaluations are bounded by the concurrency limit
+func TestDecisionMaker_MakeDecisionsWithMaxConcurrency(t *testing.T) {
+	tests := map[string]struct {
+		options         []Option
+		expectedHighest int
+	}{
+		"should evaluate at most the configured number of requests at a time": {
+			options:         []Option{WithMaxConcurrency(2)},
+			expectedHighest: 2,
+		},
+		"should evaluate policies under a combining algorithm within the limit": {
+			options:         []Option{WithMaxConcurrency(1), WithCombiningAlgorithm(NewDenyOverrides())},
+			expectedHighest: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider := new(mockPolicyProvider)
+			resolver := new(mockPolicyResolver)
+			evaluator := new(concurrencyEvaluator)
+
+			resolver.On("Resolve", mock.Anything, mock.Anything).
+				Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"}}, nil)
+			provider.On("GetPolicies", mock.Anything, mock.Anything).Return([]policyprovider.PolicyResponse{
+				{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"},
+			}, nil)
+
+			reqs := make([]*DecisionRequest, 0, 10)
+			for range 10 {
+				reqs = append(reqs, &DecisionRequest{RequestID: uuid.New(), Action: Action{ID: "read"}})
+			}
+
+			dm := NewDecisionMaker(provider, evaluator, append(tc.options, WithPolicyResolver(resolver))...)
+			responses, err := dm.MakeDecisions(context.Background(), reqs)
+
+			require.NoError(t, err)
+			require.Len(t, responses, len(reqs))
+			assert.LessOrEqual(t, evaluator.highest, tc.expectedHighest)
+		})
+	}
+}
+
+// TestDecisionMaker_MakeDecisionWithTrace tests the evaluation trace attached to decision responses
+func TestDecisionMaker_MakeDecisionWithTrace(t *testing.T) {
+	policy1 := policyprovider.PolicyResponse{ID: "policy1", Version: "1.0", Content: []byte(`{"policy": "content1"}`)}
+	policy2 := policyprovider.PolicyResponse{ID: "policy2", Version: "1.0", Content: []byte(`{"policy": "content2"}`)}
+	ruleTrace := []RuleTrace{{PolicyID: "policy1", Rule: "data.abac.result", Location: "policy_policy1:3", Matched: true}}
+
+	tests := map[string]struct {
+		requestTrace  bool
+		options       []Option
+		resolverErr   error
+		expectedTrace *Trace
+	}{
+		"should not attach trace when tracing is not requested": {},
+
+		"should attach trace when request sets trace": {
+			requestTrace: true,
+			expectedTrace: &Trace{
+				Resolutions: []ResolutionTrace{
+					{Resolver: "*decisionmaker.mockPolicyResolver", PolicyIdReferences: []PolicyIdReference{{ID: "policy1", Version: "1.0"}}},
+					{Resolver: "*decisionmaker.mockPolicyResolver", PolicyIdReferences: []PolicyIdReference{{ID: "policy2", Version: "1.0"}}},
+				},
+				Policies: []PolicyIdReference{{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"}},
+				Evaluations: []EvaluationTrace{
+					{
+						PolicyIdReferences: []PolicyIdReference{{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"}},
+						Decision:           Permit,
+						Rules:              ruleTrace,
+					},
+				},
+			},
+		},
+
+		"should attach per-policy evaluations when tracing is enabled with a combining algorithm": {
+			options: []Option{WithTrace(), WithCombiningAlgorithm(NewDenyOverrides())},
+			expectedTrace: &Trace{
+				Resolutions: []ResolutionTrace{
+					{Resolver: "*decisionmaker.mockPolicyResolver", PolicyIdReferences: []PolicyIdReference{{ID: "policy1", Version: "1.0"}}},
+					{Resolver: "*decisionmaker.mockPolicyResolver", PolicyIdReferences: []PolicyIdReference{{ID: "policy2", Version: "1.0"}}},
+				},
+				Policies: []PolicyIdReference{{ID: "policy1", Version: "1.0"}, {ID: "policy2", Version: "1.0"}},
+				Evaluations: []EvaluationTrace{
+					{PolicyIdReferences: []PolicyIdReference{{ID: "policy1", Version: "1.0"}}, Decision: Permit, Rules: ruleTrace},
+					{PolicyIdReferences: []PolicyIdReference{{ID: "policy2", Version: "1.0"}}, Decision: Permit, Rules: ruleTrace},
+				},
+			},
+		},
+
+		"should attach resolver errors to trace": {
+			requestTrace: true,
+			resolverErr:  errors.New("resolver error"),
+			expectedTrace: &Trace{
+				Resolutions: []ResolutionTrace{
+					{Resolver: "*decisionmaker.mockPolicyResolver", PolicyIdReferences: []PolicyIdReference{{ID: "policy1", Version: "1.0"}}},
+					{Resolver: "*decisionmaker.mockPolicyResolver", Error: "resolver error"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := &DecisionRequest{
+				RequestID: uuid.New(),
+				Subject:   Subject{ID: "user123", Type: "user"},
+				Resource:  Resource{ID: "resource456", Type: "document"},
+				Action:    Action{ID: "read"},
+				Trace:     tc.requestTrace,
+			}
+
+			mockProvider := new(mockPolicyProvider)
+			mockEvaluator := new(mockPolicyEvaluator)
+			resolver1 := new(mockPolicyResolver)
+			resolver2 := &mockPolicyResolver{delay: 10 * time.Millisecond}
+
+			resolver1.On("Resolve", mock.Anything, request).Return([]PolicyIdReference{{ID: "policy1", Version: "1.0"}}, nil)
+			if tc.resolverErr != nil {
+				resolver2.On("Resolve", mock.Anything, request).Return(nil, tc.resolverErr)
+			} else {
+				resolver2.On("Resolve", mock.Anything, request).Return([]PolicyIdReference{{ID: "policy2", Version: "1.0"}}, nil)
+				mockProvider.On("GetPolicies", mock.Anything, mock.Anything).Return([]policyprovider.PolicyResponse{policy1, policy2}, nil)
+				mockEvaluator.On(
+					"Evaluate",
+					mock.MatchedBy(func(ctx context.Context) bool { return IsTraceEnabled(ctx) == (tc.expectedTrace != nil) }),
+					request,
+					mock.Anything,
+				).Return(&EvaluationResult{Decision: Permit, Status: Status{Code: StatusOK}, Trace: ruleTrace}, nil)
+			}
+
+			options := append([]Option{WithPolicyResolver(resolver1), WithPolicyResolver(resolver2)}, tc.options...)
+			dm := NewDecisionMaker(mockProvider, mockEvaluator, options...)
+
+			response, err := dm.MakeDecision(context.Background(), request)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedTrace, response.Trace)
+			mockProvider.AssertExpectations(t)
+			mockEvaluator.AssertExpectations(t)
+		})
+	}
+}
+
 // TestDecisionMaker_GetPolicies tests the getPolicies helper method
 func TestDecisionMaker_GetPolicies(t *testing.T) {
 	tests := map[string]struct {
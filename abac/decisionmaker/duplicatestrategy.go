@@ -0,0 +1,119 @@
+package decisionmaker
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	versionSegmentBase    = 10
+	versionSegmentBitSize = 64
+)
+
+// DuplicatePolicyStrategy defines the interface for components that reconcile references to the same policy ID
+// returned by policy resolvers
+type DuplicatePolicyStrategy interface {
+	// Merge returns the reference to keep for a policy referenced again by duplicate, or an error if the references
+	// cannot be reconciled.
+	Merge(existing, duplicate PolicyIdReference) (PolicyIdReference, error)
+}
+
+// rejectDuplicates implements a DuplicatePolicyStrategy rejecting every duplicate
+type rejectDuplicates struct{}
+
+// NewRejectDuplicates creates a DuplicatePolicyStrategy failing resolution when a policy ID is referenced more than
+// once. This is the default strategy.
+func NewRejectDuplicates() DuplicatePolicyStrategy {
+	return &rejectDuplicates{}
+}
+
+// Merge rejects the duplicate reference
+func (s *rejectDuplicates) Merge(existing, duplicate PolicyIdReference) (PolicyIdReference, error) {
+	if existing.Version == duplicate.Version {
+		return PolicyIdReference{}, fmt.Errorf(
+			"duplicate policy reference detected: policy '%s' version '%s' returned by multiple processors",
+			existing.ID,
+			existing.Version,
+		)
+	}
+
+	return PolicyIdReference{}, conflictingVersionsError(existing, duplicate)
+}
+
+// dedupeSameVersion implements a DuplicatePolicyStrategy merging references to the same policy version
+type dedupeSameVersion struct{}
+
+// NewDedupeSameVersion creates a DuplicatePolicyStrategy keeping a single reference to a policy version referenced
+// more than once, and failing resolution when different versions of a policy are referenced.
+func NewDedupeSameVersion() DuplicatePolicyStrategy {
+	return &dedupeSameVersion{}
+}
+
+// Merge keeps the existing reference when both reference the same version
+func (s *dedupeSameVersion) Merge(existing, duplicate PolicyIdReference) (PolicyIdReference, error) {
+	if existing.Version != duplicate.Version {
+		return PolicyIdReference{}, conflictingVersionsError(existing, duplicate)
+	}
+
+	return existing, nil
+}
+
+// preferHighestVersion implements a DuplicatePolicyStrategy keeping the highest referenced version
+type preferHighestVersion struct{}
+
+// NewPreferHighestVersion creates a DuplicatePolicyStrategy keeping the highest version of a policy referenced more
+// than once. Versions are compared segment by segment, numerically where both segments are numbers and ignoring a
+// leading "v", so "v10" is higher than "v9" and "1.10" higher than "1.9".
+func NewPreferHighestVersion() DuplicatePolicyStrategy {
+	return &preferHighestVersion{}
+}
+
+// Merge keeps the reference with the highest version
+func (s *preferHighestVersion) Merge(existing, duplicate PolicyIdReference) (PolicyIdReference, error) {
+	if compareVersions(duplicate.Version, existing.Version) > 0 {
+		return duplicate, nil
+	}
+
+	return existing, nil
+}
+
+// conflictingVersionsError reports references to different versions of the same policy
+func conflictingVersionsError(existing, duplicate PolicyIdReference) error {
+	return fmt.Errorf(
+		"duplicate policy ID '%s' found: existing version '%s', conflicting version '%s'",
+		existing.ID,
+		existing.Version,
+		duplicate.Version,
+	)
+}
+
+// compareVersions compares two versions segment by segment, returning -1, 0 or +1
+func compareVersions(a, b string) int {
+	isSeparator := func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	}
+
+	aSegments := strings.FieldsFunc(strings.TrimPrefix(a, "v"), isSeparator)
+	bSegments := strings.FieldsFunc(strings.TrimPrefix(b, "v"), isSeparator)
+
+	for idx := range min(len(aSegments), len(bSegments)) {
+		if c := compareSegments(aSegments[idx], bSegments[idx]); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(aSegments), len(bSegments))
+}
+
+// compareSegments compares version segments numerically when both are numbers, and lexically otherwise
+func compareSegments(a, b string) int {
+	x, aErr := strconv.ParseUint(a, versionSegmentBase, versionSegmentBitSize)
+	y, bErr := strconv.ParseUint(b, versionSegmentBase, versionSegmentBitSize)
+	if aErr == nil && bErr == nil {
+		return cmp.Compare(x, y)
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,95 @@
+package decisionmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDuplicatePolicyStrategy_Merge tests reconciling references to the same policy ID
+func TestDuplicatePolicyStrategy_Merge(t *testing.T) {
+	v1 := PolicyIdReference{ID: "policy1", Version: "v1"}
+	v2 := PolicyIdReference{ID: "policy1", Version: "v2"}
+
+	tests := map[string]struct {
+		strategy      DuplicatePolicyStrategy
+		existing      PolicyIdReference
+		duplicate     PolicyIdReference
+		expectedRef   PolicyIdReference
+		expectedError string
+	}{
+		"reject duplicates should reject same version": {
+			strategy:      NewRejectDuplicates(),
+			existing:      v1,
+			duplicate:     v1,
+			expectedError: "duplicate policy reference detected: policy 'policy1' version 'v1' returned by multiple processors",
+		},
+		"reject duplicates should reject different versions": {
+			strategy:      NewRejectDuplicates(),
+			existing:      v1,
+			duplicate:     v2,
+			expectedError: "duplicate policy ID 'policy1' found: existing version 'v1', conflicting version 'v2'",
+		},
+		"dedupe same version should keep single reference": {
+			strategy:    NewDedupeSameVersion(),
+			existing:    v1,
+			duplicate:   v1,
+			expectedRef: v1,
+		},
+		"dedupe same version should reject different versions": {
+			strategy:      NewDedupeSameVersion(),
+			existing:      v2,
+			duplicate:     v1,
+			expectedError: "duplicate policy ID 'policy1' found: existing version 'v2', conflicting version 'v1'",
+		},
+		"prefer highest version should keep higher duplicate": {
+			strategy:    NewPreferHighestVersion(),
+			existing:    v1,
+			duplicate:   v2,
+			expectedRef: v2,
+		},
+		"prefer highest version should keep higher existing": {
+			strategy:    NewPreferHighestVersion(),
+			existing:    v2,
+			duplicate:   v1,
+			expectedRef: v2,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ref, err := tc.strategy.Merge(tc.existing, tc.duplicate)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRef, ref)
+		})
+	}
+}
+
+// TestCompareVersions tests ordering of policy versions
+func TestCompareVersions(t *testing.T) {
+	tests := map[string]struct {
+		a, b     string
+		expected int
+	}{
+		"should compare numeric segments numerically":     {a: "v10", b: "v9", expected: 1},
+		"should compare dotted versions segment-wise":     {a: "1.9", b: "1.10", expected: -1},
+		"should ignore leading v":                         {a: "v1.2", b: "1.2", expected: 0},
+		"should order longer version after its prefix":    {a: "1.0.1", b: "1.0", expected: 1},
+		"should compare non-numeric segments lexically":   {a: "rev-b", b: "rev-a", expected: 1},
+		"should compare mixed segments lexically":         {a: "1.0-rc1", b: "1.0-1", expected: 1},
+		"should treat identical versions as equal":        {a: "2024.01.15", b: "2024.01.15", expected: 0},
+		"should compare dates written as version strings": {a: "2024.01.15", b: "2024.02.01", expected: -1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, compareVersions(tc.a, tc.b))
+		})
+	}
+}
@@ -10,6 +10,9 @@ type EvaluationResult struct {
 	Status      Status       `json:"status"`
 	Obligations []Obligation `json:"obligations,omitempty"`
 	Advice      []Advice     `json:"advice,omitempty"`
+
+	// Trace lists the rules evaluated, populated by evaluators supporting tracing when IsTraceEnabled reports true
+	Trace []RuleTrace `json:"-"`
 }
 
 // PolicyEvaluator defines the interface for components that evaluate policies against decision requests to produce authorization decisions
@@ -17,3 +20,10 @@ type PolicyEvaluator interface {
 	// Evaluate evaluates a decision request against a set of policies and returns an evaluation result or an error.
 	Evaluate(ctx context.Context, req *DecisionRequest, policies []Policy) (*EvaluationResult, error)
 }
+
+// PolicyValidator is implemented by PolicyEvaluators able to compile policies without evaluating them, so that
+// policies can be checked before they are stored or activated
+type PolicyValidator interface {
+	// Validate compiles the policies and returns an error if any of them cannot be evaluated.
+	Validate(ctx context.Context, policies []Policy) error
+}
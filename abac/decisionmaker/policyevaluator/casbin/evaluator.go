@@ -0,0 +1,192 @@
+package casbin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+// PolicySectionHeader separates the Casbin model from the policy lines in Policy.Content
+const PolicySectionHeader = "[policy]"
+
+// RequestMapper maps a decision request to the values of the Casbin request definition, in order
+type RequestMapper func(req *decisionmaker.DecisionRequest) []any
+
+// evaluator implements PolicyEvaluator using Casbin models and policies
+type evaluator struct {
+	mapper             RequestMapper
+	combiningAlgorithm decisionmaker.CombiningAlgorithm
+}
+
+// Option defines configuration options for the Casbin evaluator
+type Option func(*evaluator)
+
+// NewEvaluator creates a PolicyEvaluator treating policy content as a Casbin model followed by a [policy] section
+// holding policy lines, such as "p, admin, /api/resources, get" and "g, alice, admin".
+// Requests are mapped to (subject ID, resource ID, action ID) and the results of all evaluated policies are combined
+// using deny-overrides unless configured otherwise.
+func NewEvaluator(options ...Option) decisionmaker.PolicyEvaluator {
+	e := &evaluator{
+		mapper:             DefaultRequestMapper,
+		combiningAlgorithm: decisionmaker.NewDenyOverrides(),
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// WithRequestMapper sets how decision requests are mapped to the Casbin request definition
+func WithRequestMapper(mapper RequestMapper) Option {
+	return func(e *evaluator) {
+		e.mapper = mapper
+	}
+}
+
+// WithCombiningAlgorithm sets the algorithm used to combine the results of individual policies
+func WithCombiningAlgorithm(alg decisionmaker.CombiningAlgorithm) Option {
+	return func(e *evaluator) {
+		e.combiningAlgorithm = alg
+	}
+}
+
+// DefaultRequestMapper maps a decision request to "r = sub, obj, act" using the subject, resource and action IDs
+func DefaultRequestMapper(req *decisionmaker.DecisionRequest) []any {
+	return []any{req.Subject.ID, req.Resource.ID, req.Action.ID}
+}
+
+// Evaluate enforces each policy against a decision request and combines their results
+func (e *evaluator) Evaluate(
+	ctx context.Context,
+	req *decisionmaker.DecisionRequest,
+	policies []decisionmaker.Policy,
+) (*decisionmaker.EvaluationResult, error) {
+	if req == nil {
+		return nil, errors.New("decision request cannot be nil")
+	}
+
+	if len(policies) == 0 {
+		return nil, errors.New("no policies provided for evaluation")
+	}
+
+	requestValues := e.mapper(req)
+	tracing := decisionmaker.IsTraceEnabled(ctx)
+	results := make([]decisionmaker.EvaluationResult, 0, len(policies))
+	traces := make([]decisionmaker.RuleTrace, 0)
+
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		enforcer, err := newEnforcer(policy)
+		if err != nil {
+			return nil, fmt.Errorf("policy evaluation failed: %w", err)
+		}
+
+		allowed, explain, err := enforcer.EnforceEx(requestValues...)
+		if err != nil {
+			return nil, fmt.Errorf("policy evaluation failed: policy '%s': %w", policy.ID, err)
+		}
+
+		// A denial without a matched rule means no rule in the policy applies to the request
+		if !allowed && len(explain) == 0 {
+			continue
+		}
+
+		results = append(results, convertResult(policy.ID, allowed, explain))
+
+		if tracing {
+			traces = append(traces, decisionmaker.RuleTrace{
+				PolicyID: policy.ID,
+				Rule:     strings.Join(explain, ", "),
+				Matched:  true,
+			})
+		}
+	}
+
+	result := e.combiningAlgorithm.Combine(results)
+	if tracing {
+		result.Trace = traces
+	}
+
+	return result, nil
+}
+
+// Validate loads the model and policy lines of each policy into an enforcer
+func (e *evaluator) Validate(ctx context.Context, policies []decisionmaker.Policy) error {
+	if len(policies) == 0 {
+		return errors.New("no policies provided for validation")
+	}
+
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, err := newEnforcer(policy); err != nil {
+			return fmt.Errorf("policy validation failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// newEnforcer builds a Casbin enforcer from the model and policy lines in the policy content
+func newEnforcer(policy decisionmaker.Policy) (*casbin.Enforcer, error) {
+	modelText, policyText, found := strings.Cut(string(policy.Content), PolicySectionHeader)
+	if !found {
+		return nil, fmt.Errorf("policy '%s' has no %s section", policy.ID, PolicySectionHeader)
+	}
+
+	m, err := model.NewModelFromString(modelText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load model of policy '%s': %w", policy.ID, err)
+	}
+
+	for _, line := range strings.Split(policyText, "\n") {
+		if err := persist.LoadPolicyLine(strings.TrimSpace(line), m); err != nil {
+			return nil, fmt.Errorf("failed to load policy line %q of policy '%s': %w", line, policy.ID, err)
+		}
+	}
+
+	enforcer, err := casbin.NewEnforcer(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create enforcer for policy '%s': %w", policy.ID, err)
+	}
+
+	if err := enforcer.BuildRoleLinks(); err != nil {
+		return nil, fmt.Errorf("failed to build role links of policy '%s': %w", policy.ID, err)
+	}
+
+	return enforcer, nil
+}
+
+// convertResult maps the Casbin enforcement outcome and the matched policy rule to an EvaluationResult
+func convertResult(policyID string, allowed bool, explain []string) decisionmaker.EvaluationResult {
+	decision := decisionmaker.Deny
+	if allowed {
+		decision = decisionmaker.Permit
+	}
+
+	message := fmt.Sprintf("Policy '%s' matched rule [%s]", policyID, strings.Join(explain, ", "))
+	if len(explain) == 0 {
+		message = fmt.Sprintf("Policy '%s' allowed the request", policyID)
+	}
+
+	return decisionmaker.EvaluationResult{
+		Decision: decision,
+		Status: decisionmaker.Status{
+			Code:    decisionmaker.StatusOK,
+			Message: message,
+		},
+	}
+}
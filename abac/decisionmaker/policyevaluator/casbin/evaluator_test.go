@@ -0,0 +1,227 @@
+package casbin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	tests := map[string]struct {
+		options        []Option
+		request        *decisionmaker.DecisionRequest
+		policies       []decisionmaker.Policy
+		expectedResult *decisionmaker.EvaluationResult
+		expectedError  string
+	}{
+		"nil request should return error": {
+			request:       nil,
+			policies:      []decisionmaker.Policy{getRBACPolicy()},
+			expectedError: "decision request cannot be nil",
+		},
+
+		"empty policies should return error": {
+			request:       newTestRequest("alice", "/api/orders", "get"),
+			policies:      []decisionmaker.Policy{},
+			expectedError: "no policies provided for evaluation",
+		},
+
+		"policy without policy section should return error": {
+			request:       newTestRequest("alice", "/api/orders", "get"),
+			policies:      []decisionmaker.Policy{{ID: "invalid", Content: []byte(rbacModel)}},
+			expectedError: "policy evaluation failed: policy 'invalid' has no [policy] section",
+		},
+
+		"invalid model should return error": {
+			request:       newTestRequest("alice", "/api/orders", "get"),
+			policies:      []decisionmaker.Policy{{ID: "invalid", Content: []byte("[request_definition]\n[policy]\n")}},
+			expectedError: "policy evaluation failed: failed to load model of policy 'invalid'",
+		},
+
+		"unknown policy type should return error": {
+			request: newTestRequest("alice", "/api/orders", "get"),
+			policies: []decisionmaker.Policy{{
+				ID:      "invalid",
+				Content: []byte(rbacModel + "[policy]\nx, admin, /api/orders, get\n"),
+			}},
+			expectedError: `policy evaluation failed: failed to load policy line "x, admin, /api/orders, get" of policy 'invalid'`,
+		},
+
+		"user with role should get permit decision": {
+			request:  newTestRequest("alice", "/api/orders", "get"),
+			policies: []decisionmaker.Policy{getRBACPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Policy 'rbac' matched rule [admin, /api/orders, get, allow]",
+				},
+			},
+		},
+
+		"user without matching rule should get not applicable decision": {
+			request:  newTestRequest("bob", "/api/orders", "get"),
+			policies: []decisionmaker.Policy{getRBACPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.NotApplicable,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusPolicyNotFound,
+					Message: "No applicable policies found for the request",
+				},
+			},
+		},
+
+		"matched deny rule should get deny decision": {
+			request:  newTestRequest("alice", "/api/orders", "delete"),
+			policies: []decisionmaker.Policy{getRBACPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Deny,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Policy 'rbac' matched rule [admin, /api/orders, delete, deny]",
+				},
+			},
+		},
+
+		"configured request mapper should be used": {
+			options: []Option{WithRequestMapper(func(req *decisionmaker.DecisionRequest) []any {
+				return []any{req.Subject.Attributes["role"], req.Resource.ID, req.Action.ID}
+			})},
+			request: func() *decisionmaker.DecisionRequest {
+				req := newTestRequest("bob", "/api/orders", "get")
+				req.Subject.Attributes = map[string]any{"role": "admin"}
+				return req
+			}(),
+			policies: []decisionmaker.Policy{getRBACPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Policy 'rbac' matched rule [admin, /api/orders, get, allow]",
+				},
+			},
+		},
+
+		"configured combining algorithm should be used": {
+			options: []Option{WithCombiningAlgorithm(decisionmaker.NewPermitOverrides())},
+			request: newTestRequest("alice", "/api/orders", "delete"),
+			policies: []decisionmaker.Policy{
+				getRBACPolicy(),
+				{ID: "owner", Content: []byte(rbacModel + "[policy]\np, alice, /api/orders, delete, allow\n")},
+			},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Policy 'owner' matched rule [alice, /api/orders, delete, allow]",
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := NewEvaluator(tc.options...).Evaluate(context.Background(), tc.request, tc.policies)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestEvaluator_EvaluateWithTrace(t *testing.T) {
+	ctx := decisionmaker.WithTraceContext(context.Background())
+	result, err := NewEvaluator().Evaluate(ctx, newTestRequest("alice", "/api/orders", "get"), []decisionmaker.Policy{getRBACPolicy()})
+
+	require.NoError(t, err)
+	assert.Equal(t, []decisionmaker.RuleTrace{
+		{PolicyID: "rbac", Rule: "admin, /api/orders, get, allow", Matched: true},
+	}, result.Trace)
+}
+
+func TestEvaluator_Validate(t *testing.T) {
+	tests := map[string]struct {
+		policies      []decisionmaker.Policy
+		expectedError string
+	}{
+		"valid policy should pass validation": {
+			policies: []decisionmaker.Policy{getRBACPolicy()},
+		},
+
+		"empty policies should return error": {
+			policies:      []decisionmaker.Policy{},
+			expectedError: "no policies provided for validation",
+		},
+
+		"policy without policy section should return error": {
+			policies:      []decisionmaker.Policy{getRBACPolicy(), {ID: "invalid", Content: []byte(rbacModel)}},
+			expectedError: "policy validation failed: policy 'invalid' has no [policy] section",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			validator, ok := NewEvaluator().(decisionmaker.PolicyValidator)
+			require.True(t, ok)
+
+			err := validator.Validate(context.Background(), tc.policies)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+const rbacModel = `
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act, eft
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act
+`
+
+func newTestRequest(subjectID, resourceID, action string) *decisionmaker.DecisionRequest {
+	return &decisionmaker.DecisionRequest{
+		Subject:  decisionmaker.Subject{ID: subjectID, Type: "user"},
+		Resource: decisionmaker.Resource{ID: resourceID, Type: "api"},
+		Action:   decisionmaker.Action{ID: action},
+	}
+}
+
+func getRBACPolicy() decisionmaker.Policy {
+	return decisionmaker.Policy{
+		ID:      "rbac",
+		Version: "1.0",
+		Content: []byte(rbacModel + `
+[policy]
+# roles
+g, alice, admin
+
+# permissions
+p, admin, /api/orders, get, allow
+p, admin, /api/orders, delete, deny
+`),
+	}
+}
@@ -0,0 +1,243 @@
+package cedar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/internal/lru"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+)
+
+const defaultCacheSize = 128
+
+// evaluator implements PolicyEvaluator using Cedar policies
+type evaluator struct {
+	cache *lru.Cache
+}
+
+// Option defines configuration options for the Cedar evaluator
+type Option func(*evaluator)
+
+// NewEvaluator creates a PolicyEvaluator treating policy content as a Cedar policy set, written in the core Cedar
+// language without extension types or policy templates.
+// The request subject, action and resource become the principal, action and resource entities, and the environment
+// becomes the context record. As in Cedar, a satisfied forbid policy denies the request, a satisfied permit policy
+// permits it otherwise, and policies failing to evaluate are skipped and reported in the status. Requests no policy
+// applies to are NotApplicable. Parsed policies are cached per policy, keyed by policy ID, version and content hash.
+func NewEvaluator(options ...Option) decisionmaker.PolicyEvaluator {
+	e := &evaluator{
+		cache: lru.New(defaultCacheSize),
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// WithCacheSize bounds the number of policies whose parsed Cedar policies are cached, evicting the least recently used
+// one when full. A size of zero or less disables caching, so policies are parsed on every evaluation.
+func WithCacheSize(size int) Option {
+	return func(e *evaluator) {
+		if size <= 0 {
+			e.cache = nil
+			return
+		}
+
+		e.cache = lru.New(size)
+	}
+}
+
+// Evaluate runs the Cedar policies of the policies against a decision request and maps the Cedar decision and its
+// diagnostics to the result
+func (e *evaluator) Evaluate(
+	ctx context.Context,
+	req *decisionmaker.DecisionRequest,
+	policies []decisionmaker.Policy,
+) (*decisionmaker.EvaluationResult, error) {
+	if req == nil {
+		return nil, errors.New("decision request cannot be nil")
+	}
+
+	if len(policies) == 0 {
+		return nil, errors.New("no policies provided for evaluation")
+	}
+
+	evalCtx, err := newEvalContext(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tracing := decisionmaker.IsTraceEnabled(ctx)
+	var diagnostics diagnostics
+	traces := make([]decisionmaker.RuleTrace, 0)
+
+	for _, policy := range policies {
+		parsed, err := e.parsedPolicy(policy)
+		if err != nil {
+			return nil, fmt.Errorf("policy evaluation failed: %w", err)
+		}
+
+		for _, cedarPolicy := range parsed {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			satisfied, err := cedarPolicy.satisfied(evalCtx)
+			diagnostics.record(policy.ID, cedarPolicy, satisfied, err)
+
+			if tracing {
+				traces = append(traces, decisionmaker.RuleTrace{PolicyID: policy.ID, Rule: cedarPolicy.id, Matched: satisfied})
+			}
+		}
+	}
+
+	result := diagnostics.result()
+	if tracing {
+		result.Trace = traces
+	}
+
+	return result, nil
+}
+
+// parsedPolicy returns the parsed Cedar policies of the policy from the cache, parsing and caching them on a miss
+func (e *evaluator) parsedPolicy(p decisionmaker.Policy) ([]policy, error) {
+	if e.cache == nil {
+		return parsePolicy(p)
+	}
+
+	key := cacheKey(p)
+	if parsed, ok := e.cache.Get(key); ok {
+		return parsed.([]policy), nil
+	}
+
+	parsed, err := parsePolicy(p)
+	if err != nil {
+		return nil, err
+	}
+
+	e.cache.Add(key, parsed)
+	return parsed, nil
+}
+
+// cacheKey identifies a policy by ID, version and content hash
+func cacheKey(policy decisionmaker.Policy) string {
+	hash := policy.ContentHash
+	if hash == "" {
+		hash = policyprovider.HashContent(policy.Content)
+	}
+
+	return fmt.Sprintf("%s@%s#%s", policy.ID, policy.Version, hash)
+}
+
+// Validate parses the Cedar policies of each policy, without caching them
+func (e *evaluator) Validate(ctx context.Context, policies []decisionmaker.Policy) error {
+	if len(policies) == 0 {
+		return errors.New("no policies provided for validation")
+	}
+
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, err := parsePolicy(policy); err != nil {
+			return fmt.Errorf("policy validation failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// parsePolicy parses the policy content as a Cedar policy set
+func parsePolicy(p decisionmaker.Policy) ([]policy, error) {
+	parsed, err := parsePolicies(string(p.Content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy '%s': %w", p.ID, err)
+	}
+
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("policy '%s' has no Cedar policies", p.ID)
+	}
+
+	return parsed, nil
+}
+
+// satisfied reports whether the scope and conditions of the policy hold for the request
+func (p policy) satisfied(ctx *evalContext) (bool, error) {
+	for _, constraint := range p.scope {
+		if ok, err := evalAs[bool](ctx, constraint); err != nil || !ok {
+			return false, err
+		}
+	}
+
+	for _, condition := range p.conditions {
+		ok, err := evalAs[bool](ctx, condition.body)
+		if err != nil || ok == condition.unless {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// diagnostics collects the Cedar policies determining a decision and the errors of those failing to evaluate
+type diagnostics struct {
+	permits []string
+	forbids []string
+	errs    []string
+}
+
+// record adds the outcome of evaluating a Cedar policy of the policy with the given ID
+func (d *diagnostics) record(policyID string, p policy, satisfied bool, err error) {
+	name := fmt.Sprintf("'%s' in policy '%s'", p.id, policyID)
+
+	switch {
+	case err != nil:
+		d.errs = append(d.errs, fmt.Sprintf("%s: %v", name, err))
+	case satisfied && p.effect == effectForbid:
+		d.forbids = append(d.forbids, name)
+	case satisfied:
+		d.permits = append(d.permits, name)
+	}
+}
+
+// result maps the Cedar decision to an evaluation result, forbid overriding permit
+func (d *diagnostics) result() *decisionmaker.EvaluationResult {
+	var result decisionmaker.EvaluationResult
+
+	switch {
+	case len(d.forbids) > 0:
+		result.Decision = decisionmaker.Deny
+		result.Status = decisionmaker.Status{Code: decisionmaker.StatusOK, Message: "Forbidden by " + strings.Join(d.forbids, ", ")}
+	case len(d.permits) > 0:
+		result.Decision = decisionmaker.Permit
+		result.Status = decisionmaker.Status{Code: decisionmaker.StatusOK, Message: "Permitted by " + strings.Join(d.permits, ", ")}
+	case len(d.errs) > 0:
+		return &decisionmaker.EvaluationResult{
+			Decision: decisionmaker.Indeterminate,
+			Status: decisionmaker.Status{
+				Code:    decisionmaker.StatusEvaluationError,
+				Message: "Policy evaluation failed: " + strings.Join(d.errs, "; "),
+			},
+		}
+	default:
+		return &decisionmaker.EvaluationResult{
+			Decision: decisionmaker.NotApplicable,
+			Status: decisionmaker.Status{
+				Code:    decisionmaker.StatusPolicyNotFound,
+				Message: "No applicable policies found for the request",
+			},
+		}
+	}
+
+	if len(d.errs) > 0 {
+		result.Status.Message += "; skipped failing " + strings.Join(d.errs, "; ")
+	}
+
+	return &result
+}
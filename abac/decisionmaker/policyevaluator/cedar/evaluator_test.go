@@ -0,0 +1,526 @@
+package cedar
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	tests := map[string]struct {
+		request        *decisionmaker.DecisionRequest
+		policies       []decisionmaker.Policy
+		expectedResult *decisionmaker.EvaluationResult
+		expectedError  string
+	}{
+		"nil request should return error": {
+			request:       nil,
+			policies:      []decisionmaker.Policy{getOwnerPolicy()},
+			expectedError: "decision request cannot be nil",
+		},
+
+		"empty policies should return error": {
+			request:       newTestRequest("user-1", "user-1", "read"),
+			policies:      []decisionmaker.Policy{},
+			expectedError: "no policies provided for evaluation",
+		},
+
+		"malformed policy should return error": {
+			request:       newTestRequest("user-1", "user-1", "read"),
+			policies:      []decisionmaker.Policy{{ID: "malformed", Content: []byte("permit(principal, action)")}},
+			expectedError: `policy evaluation failed: failed to parse policy 'malformed': 1:25: unexpected ")", expected ","`,
+		},
+
+		"policy without cedar policies should return error": {
+			request:       newTestRequest("user-1", "user-1", "read"),
+			policies:      []decisionmaker.Policy{{ID: "empty", Content: []byte("// nothing here")}},
+			expectedError: "policy evaluation failed: policy 'empty' has no Cedar policies",
+		},
+
+		"extension function should return error": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{{ID: "extension", Content: []byte(
+				`permit(principal, action, resource) when { context.ip == ip("10.0.0.1") };`,
+			)}},
+			expectedError: "policy evaluation failed: failed to parse policy 'extension': 1:58: extension function ip: not supported",
+		},
+
+		"duplicate policy ids should return error": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{{ID: "duplicate", Content: []byte(`
+@id("read") permit(principal, action == Action::"read", resource);
+@id("read") permit(principal, action == Action::"list", resource);
+`)}},
+			expectedError: `policy evaluation failed: failed to parse policy 'duplicate': duplicate policy id "read"`,
+		},
+
+		"non long number attribute should return error": {
+			request: func() *decisionmaker.DecisionRequest {
+				req := newTestRequest("user-1", "user-1", "read")
+				req.Environment = map[string]any{"score": 0.5}
+				return req
+			}(),
+			policies:      []decisionmaker.Policy{getOwnerPolicy()},
+			expectedError: "failed to map environment: score: number 0.5 is not a long",
+		},
+
+		"owner should get permit decision": {
+			request:  newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{getOwnerPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Permitted by 'owner-access' in policy 'owner-policy'",
+				},
+			},
+		},
+
+		"non owner should get not applicable decision": {
+			request:  newTestRequest("user-1", "user-2", "read"),
+			policies: []decisionmaker.Policy{getOwnerPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.NotApplicable,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusPolicyNotFound,
+					Message: "No applicable policies found for the request",
+				},
+			},
+		},
+
+		"forbid should override permit across policies": {
+			request:  newTestRequest("user-1", "user-1", "delete"),
+			policies: []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Deny,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Forbidden by 'no-delete' in policy 'delete-policy'",
+				},
+			},
+		},
+
+		"unless clause should exempt matching requests from forbid": {
+			request: func() *decisionmaker.DecisionRequest {
+				req := newTestRequest("user-1", "user-1", "delete")
+				req.Subject.Attributes["roles"] = []string{"admin"}
+				return req
+			}(),
+			policies: []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Permitted by 'owner-access' in policy 'owner-policy', 'admin-access' in policy 'owner-policy'",
+				},
+			},
+		},
+
+		"missing attribute should get indeterminate decision": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{{ID: "region-policy", Content: []byte(
+				`@id("region") permit(principal, action, resource) when { context.region == "au" };`,
+			)}},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Indeterminate,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusEvaluationError,
+					Message: `Policy evaluation failed: 'region' in policy 'region-policy': {} does not have the attribute "region"`,
+				},
+			},
+		},
+
+		"failing policy should be skipped and reported when another policy decides": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{getOwnerPolicy(), {ID: "region-policy", Content: []byte(
+				`@id("region") forbid(principal, action, resource) when { context.region != "au" };`,
+			)}},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code: decisionmaker.StatusOK,
+					Message: "Permitted by 'owner-access' in policy 'owner-policy'; " +
+						`skipped failing 'region' in policy 'region-policy': {} does not have the attribute "region"`,
+				},
+			},
+		},
+
+		"policies without id annotation should be named by position": {
+			request: newTestRequest("user-1", "user-2", "read"),
+			policies: []decisionmaker.Policy{{ID: "positional", Content: []byte(`
+permit(principal, action == Action::"list", resource);
+permit(principal is user, action == Action::"read", resource is order);
+`)}},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Permitted by 'policy1' in policy 'positional'",
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := NewEvaluator().Evaluate(context.Background(), tc.request, tc.policies)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestEvaluator_EvaluateExpressions(t *testing.T) {
+	request := &decisionmaker.DecisionRequest{
+		Subject: decisionmaker.Subject{
+			ID:   "alice",
+			Type: "User",
+			Attributes: map[string]any{
+				"age":     30,
+				"email":   "alice@example.com",
+				"roles":   []string{"editor", "viewer"},
+				"profile": map[string]any{"department": "sales", "manager": map[string]any{"__entity": map[string]any{"type": "User", "id": "bob"}}},
+				"parents": []map[string]any{{"type": "Group", "id": "sales"}},
+			},
+		},
+		Resource: decisionmaker.Resource{
+			ID:         "report-1",
+			Attributes: map[string]any{"owner": "alice", "tags": []string{"q1"}},
+		},
+		Action: decisionmaker.Action{
+			ID:         "edit",
+			Attributes: map[string]any{"parents": []map[string]any{{"type": "Action", "id": "write"}}},
+		},
+		Environment: map[string]any{"hour": 10, "mfa": true},
+	}
+
+	tests := map[string]struct {
+		condition     string
+		expected      bool
+		expectedError string
+	}{
+		"entity equality": {
+			condition: `principal == User::"alice" && resource == Resource::"report-1"`,
+			expected:  true,
+		},
+
+		"entity hierarchy through parents": {
+			condition: `principal in Group::"sales" && action in [Action::"read", Action::"write"]`,
+			expected:  true,
+		},
+
+		"entity not in unrelated group": {
+			condition: `principal in Group::"finance"`,
+			expected:  false,
+		},
+
+		"entity is type in group": {
+			condition: `principal is User in Group::"sales"`,
+			expected:  true,
+		},
+
+		"parents attribute is reserved": {
+			condition: `principal has parents`,
+			expected:  false,
+		},
+
+		"attribute access and has": {
+			condition: `principal has profile && principal.profile.department == "sales"`,
+			expected:  true,
+		},
+
+		"bracket attribute access": {
+			condition: `principal["profile"]["department"] == "sales"`,
+			expected:  true,
+		},
+
+		"entity reference attribute": {
+			condition: `principal.profile.manager == User::"bob"`,
+			expected:  true,
+		},
+
+		"record literal equality": {
+			condition: `{"hour": context.hour, "mfa": true} == context`,
+			expected:  true,
+		},
+
+		"long comparison and arithmetic": {
+			condition: `principal.age >= 18 && principal.age * 2 - 10 == 50 && -context.hour < 0`,
+			expected:  true,
+		},
+
+		"set methods": {
+			condition: `principal.roles.contains("editor") && principal.roles.containsAll(["viewer"]) && !resource.tags.isEmpty()`,
+			expected:  true,
+		},
+
+		"contains any": {
+			condition: `principal.roles.containsAny(["admin", "owner"])`,
+			expected:  false,
+		},
+
+		"set equality ignores order": {
+			condition: `principal.roles == ["viewer", "editor"]`,
+			expected:  true,
+		},
+
+		"like pattern": {
+			condition: `principal.email like "*@example.com" && !(principal.email like "*\*")`,
+			expected:  true,
+		},
+
+		"if then else": {
+			condition: `if context.mfa then resource.owner == principal.age else false`,
+			expected:  false,
+		},
+
+		"or short circuits": {
+			condition: `context.mfa || context.missing`,
+			expected:  true,
+		},
+
+		"and short circuits": {
+			condition: `!context.mfa && context.missing`,
+			expected:  false,
+		},
+
+		"has on missing attribute": {
+			condition: `context has missing || resource has "owner"`,
+			expected:  true,
+		},
+
+		"type error": {
+			condition:     `principal.age < "18"`,
+			expectedError: "type error: expected long, got string",
+		},
+
+		"missing attribute": {
+			condition:     `resource.missing`,
+			expectedError: `Resource::"report-1" does not have the attribute "missing"`,
+		},
+
+		"overflow": {
+			condition:     `9223372036854775807 + 1 > 0`,
+			expectedError: "overflow while evaluating 9223372036854775807 + 1",
+		},
+
+		"non bool condition": {
+			condition:     `context.hour`,
+			expectedError: "type error: expected bool, got long",
+		},
+
+		"values of different types are unequal": {
+			condition: `principal.age == "30"`,
+			expected:  false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := decisionmaker.Policy{
+				ID:      "expression-policy",
+				Content: []byte(`@id("expression") permit(principal, action, resource) when { ` + tc.condition + ` };`),
+			}
+
+			result, err := NewEvaluator().Evaluate(context.Background(), request, []decisionmaker.Policy{policy})
+			require.NoError(t, err)
+
+			if tc.expectedError != "" {
+				assert.Equal(t, decisionmaker.Indeterminate, result.Decision)
+				assert.Contains(t, result.Status.Message, tc.expectedError)
+				return
+			}
+
+			expected := decisionmaker.NotApplicable
+			if tc.expected {
+				expected = decisionmaker.Permit
+			}
+
+			assert.Equal(t, expected, result.Decision, result.Status.Message)
+		})
+	}
+}
+
+func TestEvaluator_EvaluateWithTrace(t *testing.T) {
+	ctx := decisionmaker.WithTraceContext(context.Background())
+	result, err := NewEvaluator().Evaluate(
+		ctx,
+		newTestRequest("user-1", "user-1", "delete"),
+		[]decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, decisionmaker.Deny, result.Decision)
+	assert.Equal(t, []decisionmaker.RuleTrace{
+		{PolicyID: "owner-policy", Rule: "owner-access", Matched: true},
+		{PolicyID: "owner-policy", Rule: "admin-access", Matched: false},
+		{PolicyID: "delete-policy", Rule: "no-delete", Matched: true},
+	}, result.Trace)
+}
+
+func TestEvaluator_Validate(t *testing.T) {
+	tests := map[string]struct {
+		policies      []decisionmaker.Policy
+		expectedError string
+	}{
+		"valid policies should pass validation": {
+			policies: []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()},
+		},
+
+		"empty policies should return error": {
+			policies:      []decisionmaker.Policy{},
+			expectedError: "no policies provided for validation",
+		},
+
+		"malformed policy should return error": {
+			policies: []decisionmaker.Policy{{ID: "malformed", Content: []byte(
+				`permit(principal, action, resource) when { "unterminated };`,
+			)}},
+			expectedError: "policy validation failed: failed to parse policy 'malformed': 1:44: unterminated string literal",
+		},
+
+		"unknown variable should return error": {
+			policies: []decisionmaker.Policy{{ID: "unknown", Content: []byte(
+				`permit(principal, action, resource) when { subject.id == "a" };`,
+			)}},
+			expectedError: "policy validation failed: failed to parse policy 'unknown': 1:44: unknown variable subject",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			validator, ok := NewEvaluator().(decisionmaker.PolicyValidator)
+			require.True(t, ok)
+
+			err := validator.Validate(context.Background(), tc.policies)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEvaluator_PolicyCache(t *testing.T) {
+	request := newTestRequest("user-1", "user-1", "read")
+
+	changedOwnerPolicy := getOwnerPolicy()
+	changedOwnerPolicy.Content = append([]byte("// changed\n"), changedOwnerPolicy.Content...)
+
+	tests := map[string]struct {
+		options         []Option
+		evaluations     [][]decisionmaker.Policy
+		expectedEntries int
+	}{
+		"should reuse parsed policies for the same policy": {
+			evaluations: [][]decisionmaker.Policy{
+				{getOwnerPolicy(), getDeletePolicy()},
+				{getDeletePolicy()},
+			},
+			expectedEntries: 2,
+		},
+
+		"should parse policies again when policy content changes": {
+			evaluations: [][]decisionmaker.Policy{
+				{getOwnerPolicy()},
+				{changedOwnerPolicy},
+			},
+			expectedEntries: 2,
+		},
+
+		"should evict parsed policies beyond the cache size": {
+			options: []Option{WithCacheSize(1)},
+			evaluations: [][]decisionmaker.Policy{
+				{getOwnerPolicy()},
+				{changedOwnerPolicy},
+			},
+			expectedEntries: 1,
+		},
+
+		"should not cache invalid policies": {
+			evaluations: [][]decisionmaker.Policy{
+				{{ID: "invalid", Content: []byte("permit(")}},
+			},
+			expectedEntries: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEvaluator(tc.options...)
+
+			for _, policies := range tc.evaluations {
+				_, _ = e.Evaluate(context.Background(), request, policies)
+			}
+
+			assert.Equal(t, tc.expectedEntries, e.(*evaluator).cache.Len())
+		})
+	}
+
+	t.Run("should disable cache when size is zero", func(t *testing.T) {
+		e := NewEvaluator(WithCacheSize(0))
+
+		result, err := e.Evaluate(context.Background(), request, []decisionmaker.Policy{getOwnerPolicy()})
+
+		require.NoError(t, err)
+		assert.Equal(t, decisionmaker.Permit, result.Decision)
+		assert.Nil(t, e.(*evaluator).cache)
+	})
+}
+
+func newTestRequest(subjectID, ownerID, action string) *decisionmaker.DecisionRequest {
+	return &decisionmaker.DecisionRequest{
+		Subject: decisionmaker.Subject{
+			ID:         subjectID,
+			Type:       "user",
+			Attributes: map[string]any{"roles": []string{"customer"}},
+		},
+		Resource: decisionmaker.Resource{
+			ID:         "order-1",
+			Type:       "order",
+			Attributes: map[string]any{"owner": map[string]any{"__entity": map[string]any{"type": "user", "id": ownerID}}},
+		},
+		Action: decisionmaker.Action{ID: action},
+	}
+}
+
+func getOwnerPolicy() decisionmaker.Policy {
+	return decisionmaker.Policy{
+		ID:      "owner-policy",
+		Version: "1.0",
+		Content: []byte(`
+@id("owner-access")
+permit(principal is user, action, resource is order)
+when { resource.owner == principal };
+
+@id("admin-access")
+permit(principal, action, resource)
+when { principal.roles.contains("admin") };
+`),
+	}
+}
+
+func getDeletePolicy() decisionmaker.Policy {
+	return decisionmaker.Policy{
+		ID:      "delete-policy",
+		Version: "1.0",
+		Content: []byte(`
+@id("no-delete")
+forbid(principal, action == Action::"delete", resource)
+unless { principal.roles.contains("admin") };
+`),
+	}
+}
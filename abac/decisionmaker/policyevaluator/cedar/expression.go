@@ -0,0 +1,436 @@
+package cedar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// evalContext holds the variables and entities of the request a policy is evaluated against
+type evalContext struct {
+	variables map[string]value // principal, action, resource and context
+	entities  map[entityUID]entity
+}
+
+// expr is a Cedar expression
+type expr interface {
+	eval(ctx *evalContext) (value, error)
+}
+
+type (
+	literalExpr  struct{ value value }
+	variableExpr struct{ name string }
+	setExpr      struct{ items []expr }
+	recordExpr   struct{ fields map[string]expr }
+	notExpr      struct{ operand expr }
+	negExpr      struct{ operand expr }
+	andExpr      struct{ left, right expr }
+	orExpr       struct{ left, right expr }
+	ifExpr       struct{ cond, then, otherwise expr }
+	compareExpr  struct {
+		op          string // One of ==, !=, <, <=, > and >=
+		left, right expr
+	}
+	arithExpr struct {
+		op          string // One of +, - and *
+		left, right expr
+	}
+	inExpr  struct{ left, right expr }
+	hasExpr struct {
+		operand   expr
+		attribute string
+	}
+	accessExpr struct {
+		operand   expr
+		attribute string
+	}
+	likeExpr struct {
+		operand expr
+		pattern []patternChar
+	}
+	isExpr struct {
+		operand    expr
+		entityType string
+		in         expr // Optional entity or set of entities the operand must be in
+	}
+	methodExpr struct {
+		operand expr
+		name    string // One of contains, containsAll, containsAny and isEmpty
+		args    []expr
+	}
+)
+
+// patternChar is a character of a like pattern, or a wildcard matching any sequence of characters
+type patternChar struct {
+	char     rune
+	wildcard bool
+}
+
+func (e literalExpr) eval(*evalContext) (value, error) {
+	return e.value, nil
+}
+
+func (e variableExpr) eval(ctx *evalContext) (value, error) {
+	return ctx.variables[e.name], nil
+}
+
+func (e setExpr) eval(ctx *evalContext) (value, error) {
+	items := make(set, 0, len(e.items))
+	for _, item := range e.items {
+		v, err := item.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, v)
+	}
+
+	return items, nil
+}
+
+func (e recordExpr) eval(ctx *evalContext) (value, error) {
+	fields := make(record, len(e.fields))
+	for name, field := range e.fields {
+		v, err := field.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[name] = v
+	}
+
+	return fields, nil
+}
+
+func (e notExpr) eval(ctx *evalContext) (value, error) {
+	v, err := evalAs[bool](ctx, e.operand)
+	return !v, err
+}
+
+func (e negExpr) eval(ctx *evalContext) (value, error) {
+	v, err := evalAs[int64](ctx, e.operand)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == math.MinInt64 {
+		return nil, errors.New("overflow while negating long")
+	}
+
+	return -v, nil
+}
+
+func (e andExpr) eval(ctx *evalContext) (value, error) {
+	left, err := evalAs[bool](ctx, e.left)
+	if err != nil || !left {
+		return false, err
+	}
+
+	return evalAs[bool](ctx, e.right)
+}
+
+func (e orExpr) eval(ctx *evalContext) (value, error) {
+	left, err := evalAs[bool](ctx, e.left)
+	if err != nil || left {
+		return left, err
+	}
+
+	return evalAs[bool](ctx, e.right)
+}
+
+func (e ifExpr) eval(ctx *evalContext) (value, error) {
+	cond, err := evalAs[bool](ctx, e.cond)
+	if err != nil {
+		return nil, err
+	}
+
+	if cond {
+		return e.then.eval(ctx)
+	}
+
+	return e.otherwise.eval(ctx)
+}
+
+func (e compareExpr) eval(ctx *evalContext) (value, error) {
+	if e.op == "==" || e.op == "!=" {
+		left, right, err := evalPair(ctx, e.left, e.right)
+		if err != nil {
+			return nil, err
+		}
+
+		return equal(left, right) == (e.op == "=="), nil
+	}
+
+	left, err := evalAs[int64](ctx, e.left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := evalAs[int64](ctx, e.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "<":
+		return left < right, nil
+	case "<=":
+		return left <= right, nil
+	case ">":
+		return left > right, nil
+	default:
+		return left >= right, nil
+	}
+}
+
+func (e arithExpr) eval(ctx *evalContext) (value, error) {
+	left, err := evalAs[int64](ctx, e.left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := evalAs[int64](ctx, e.right)
+	if err != nil {
+		return nil, err
+	}
+
+	var result int64
+	var overflow bool
+	switch e.op {
+	case "+":
+		result = left + right
+		overflow = (right > 0 && result < left) || (right < 0 && result > left)
+	case "-":
+		result = left - right
+		overflow = (right > 0 && result > left) || (right < 0 && result < left)
+	default:
+		result = left * right
+		overflow = left != 0 && (result/left != right || (left == -1 && right == math.MinInt64))
+	}
+
+	if overflow {
+		return nil, fmt.Errorf("overflow while evaluating %d %s %d", left, e.op, right)
+	}
+
+	return result, nil
+}
+
+func (e inExpr) eval(ctx *evalContext) (value, error) {
+	left, err := evalAs[entityUID](ctx, e.left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := e.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.in(left, right)
+}
+
+func (e hasExpr) eval(ctx *evalContext) (value, error) {
+	v, err := e.operand.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case record:
+		_, ok := v[e.attribute]
+		return ok, nil
+	case entityUID:
+		_, ok := ctx.entities[v].attributes[e.attribute]
+		return ok, nil
+	default:
+		return nil, typeError("entity or record", v)
+	}
+}
+
+func (e accessExpr) eval(ctx *evalContext) (value, error) {
+	v, err := e.operand.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes record
+	switch v := v.(type) {
+	case record:
+		attributes = v
+	case entityUID:
+		attributes = ctx.entities[v].attributes
+	default:
+		return nil, typeError("entity or record", v)
+	}
+
+	attribute, ok := attributes[e.attribute]
+	if !ok {
+		return nil, fmt.Errorf("%s does not have the attribute %q", format(v), e.attribute)
+	}
+
+	return attribute, nil
+}
+
+func (e likeExpr) eval(ctx *evalContext) (value, error) {
+	v, err := evalAs[string](ctx, e.operand)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchPattern(e.pattern, []rune(v)), nil
+}
+
+func (e isExpr) eval(ctx *evalContext) (value, error) {
+	v, err := evalAs[entityUID](ctx, e.operand)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Type != e.entityType || e.in == nil {
+		return v.Type == e.entityType, nil
+	}
+
+	ancestor, err := e.in.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.in(v, ancestor)
+}
+
+func (e methodExpr) eval(ctx *evalContext) (value, error) {
+	operand, err := evalAs[set](ctx, e.operand)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.name == "isEmpty" {
+		return len(operand) == 0, nil
+	}
+
+	if e.name == "contains" {
+		arg, err := e.args[0].eval(ctx)
+		return err == nil && contains(operand, arg), err
+	}
+
+	arg, err := evalAs[set](ctx, e.args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if e.name == "containsAll" {
+		return containsAll(operand, arg), nil
+	}
+
+	for _, item := range arg {
+		if contains(operand, item) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// in reports whether the entity is, or descends from, the ancestor entity or any entity of the ancestor set
+func (ctx *evalContext) in(uid entityUID, ancestor value) (bool, error) {
+	ancestors, ok := ancestor.(set)
+	if !ok {
+		ancestors = set{ancestor}
+	}
+
+	targets := make(map[entityUID]bool, len(ancestors))
+	for _, item := range ancestors {
+		target, ok := item.(entityUID)
+		if !ok {
+			return false, typeError("entity or set of entities", ancestor)
+		}
+
+		targets[target] = true
+	}
+
+	// Walk the hierarchy breadth first, guarding against cycles
+	visited := map[entityUID]bool{uid: true}
+	queue := []entityUID{uid}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if targets[current] {
+			return true, nil
+		}
+
+		for _, parent := range ctx.entities[current].parents {
+			if !visited[parent] {
+				visited[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// evalAs evaluates the expression and checks that its value has the Cedar type represented by T
+func evalAs[T bool | int64 | string | entityUID | set](ctx *evalContext, e expr) (T, error) {
+	var zero T
+
+	v, err := e.eval(ctx)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := v.(T)
+	if !ok {
+		return zero, typeError(typeName(zero), v)
+	}
+
+	return typed, nil
+}
+
+// evalPair evaluates two operands in order
+func evalPair(ctx *evalContext, left, right expr) (value, value, error) {
+	l, err := left.eval(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := right.eval(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return l, r, nil
+}
+
+// typeError reports a value of an unexpected type
+func typeError(expected string, v value) error {
+	return fmt.Errorf("type error: expected %s, got %s", expected, typeName(v))
+}
+
+// matchPattern reports whether the string matches the like pattern, backtracking to the last wildcard on a mismatch
+func matchPattern(pattern []patternChar, s []rune) bool {
+	p, idx := 0, 0
+	lastWildcard, lastMatch := -1, 0
+
+	for idx < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p].wildcard:
+			lastWildcard, lastMatch = p, idx
+			p++
+		case p < len(pattern) && pattern[p].char == s[idx]:
+			p++
+			idx++
+		case lastWildcard >= 0:
+			p = lastWildcard + 1
+			lastMatch++
+			idx = lastMatch
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p].wildcard {
+		p++
+	}
+
+	return p == len(pattern)
+}
@@ -0,0 +1,219 @@
+package cedar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenInt
+	tokenPunct
+)
+
+// token is a lexical token of a Cedar policy, with the position it starts at
+type token struct {
+	kind tokenKind
+	text string // Identifier name, string literal body with its escape sequences, integer digits or punctuation
+	pos  position
+}
+
+// position is a line and column in the policy content, both starting at 1
+type position struct {
+	line   int
+	column int
+}
+
+func (p position) String() string {
+	return fmt.Sprintf("%d:%d", p.line, p.column)
+}
+
+// punctuation lists the operators and delimiters, longest first so two-character operators win
+var punctuation = []string{
+	"::", "==", "!=", "<=", ">=", "&&", "||",
+	"(", ")", "[", "]", "{", "}", ",", ";", ".", "<", ">", "!", "-", "+", "*", "@", ":", "?",
+}
+
+// tokenize splits the policy content into tokens, skipping whitespace and line comments
+func tokenize(content string) ([]token, error) {
+	l := &lexer{content: content, pos: position{line: 1, column: 1}}
+	tokens := make([]token, 0)
+
+	for {
+		l.skipSpaceAndComments()
+		if l.offset >= len(l.content) {
+			return append(tokens, token{kind: tokenEOF, pos: l.pos}), nil
+		}
+
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, tok)
+	}
+}
+
+// lexer reads tokens from the policy content
+type lexer struct {
+	content string
+	offset  int
+	pos     position
+}
+
+// advance moves past n bytes of the content, which must not span a line break
+func (l *lexer) advance(n int) {
+	l.offset += n
+	l.pos.column += n
+}
+
+func (l *lexer) skipSpaceAndComments() {
+	for l.offset < len(l.content) {
+		r, size := utf8.DecodeRuneInString(l.content[l.offset:])
+		switch {
+		case r == '\n':
+			l.offset += size
+			l.pos = position{line: l.pos.line + 1, column: 1}
+		case unicode.IsSpace(r):
+			l.advance(size)
+		case strings.HasPrefix(l.content[l.offset:], "//"):
+			end := strings.IndexByte(l.content[l.offset:], '\n')
+			if end < 0 {
+				end = len(l.content) - l.offset
+			}
+			l.advance(end)
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	start := l.pos
+	rest := l.content[l.offset:]
+
+	switch c := rest[0]; {
+	case c == '"':
+		size, err := stringLength(rest)
+		if err != nil {
+			return token{}, fmt.Errorf("%s: %w", start, err)
+		}
+
+		l.advance(size)
+		return token{kind: tokenString, text: rest[1 : size-1], pos: start}, nil
+	case c >= '0' && c <= '9':
+		size := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if size < 0 {
+			size = len(rest)
+		}
+
+		l.advance(size)
+		return token{kind: tokenInt, text: rest[:size], pos: start}, nil
+	case isIdentStart(rune(c)):
+		size := strings.IndexFunc(rest, func(r rune) bool { return !isIdentStart(r) && (r < '0' || r > '9') })
+		if size < 0 {
+			size = len(rest)
+		}
+
+		l.advance(size)
+		return token{kind: tokenIdent, text: rest[:size], pos: start}, nil
+	}
+
+	for _, punct := range punctuation {
+		if strings.HasPrefix(rest, punct) {
+			l.advance(len(punct))
+			return token{kind: tokenPunct, text: punct, pos: start}, nil
+		}
+	}
+
+	r, _ := utf8.DecodeRuneInString(rest)
+	return token{}, fmt.Errorf("%s: unexpected character %q", start, r)
+}
+
+// isIdentStart reports whether r may start an identifier, which is made of ASCII letters, digits and underscores
+func isIdentStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// stringLength returns the length in bytes of the double quoted string literal at the start of s
+func stringLength(s string) (int, error) {
+	for offset := 1; offset < len(s); offset++ {
+		switch s[offset] {
+		case '"':
+			return offset + 1, nil
+		case '\n':
+			return 0, errors.New("unterminated string literal")
+		case '\\':
+			offset++
+		}
+	}
+
+	return 0, errors.New("unterminated string literal")
+}
+
+// escapes maps the single character escape sequences of string literals to their values
+var escapes = map[byte]rune{'n': '\n', 'r': '\r', 't': '\t', '0': 0, '\\': '\\', '"': '"', '\'': '\''}
+
+// unescape decodes the escape sequences of a string literal body. When wildcards is set, unescaped '*' characters
+// are reported at their byte offsets in the decoded string, and "\*" stands for a literal '*'.
+func unescape(body string, wildcards bool) (string, []int, error) {
+	var decoded strings.Builder
+	var stars []int
+
+	for offset := 0; offset < len(body); {
+		switch c := body[offset]; {
+		case c == '*' && wildcards:
+			stars = append(stars, decoded.Len())
+			decoded.WriteByte(c)
+			offset++
+		case c == '\\':
+			r, size, err := unescapeSequence(body[offset:], wildcards)
+			if err != nil {
+				return "", nil, err
+			}
+
+			decoded.WriteRune(r)
+			offset += size
+		default:
+			decoded.WriteByte(c)
+			offset++
+		}
+	}
+
+	return decoded.String(), stars, nil
+}
+
+// unescapeSequence reads the escape sequence at the start of s, returning the rune it stands for and its length
+func unescapeSequence(s string, wildcards bool) (rune, int, error) {
+	if len(s) < 2 {
+		return 0, 0, errors.New("invalid escape sequence in string literal")
+	}
+
+	if r, ok := escapes[s[1]]; ok {
+		return r, 2, nil
+	}
+
+	if s[1] == '*' && wildcards {
+		return '*', 2, nil
+	}
+
+	if s[1] == 'u' && len(s) > 2 && s[2] == '{' {
+		end := strings.IndexByte(s, '}')
+		if end > 3 {
+			code, err := strconv.ParseUint(s[3:end], 16, 32)
+			if err == nil && utf8.ValidRune(rune(code)) {
+				return rune(code), end + 1, nil
+			}
+		}
+	}
+
+	return 0, 0, fmt.Errorf("invalid escape sequence %q in string literal", s[:2])
+}
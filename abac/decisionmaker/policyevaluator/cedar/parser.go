@@ -0,0 +1,355 @@
+package cedar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// effect is the effect of a Cedar policy when it is satisfied
+type effect string
+
+const (
+	effectPermit effect = "permit"
+	effectForbid effect = "forbid"
+)
+
+// policy is a parsed Cedar policy
+type policy struct {
+	id         string
+	effect     effect
+	scope      []expr // Principal, action and resource constraints, all of which must hold
+	conditions []condition
+}
+
+// condition is a when or unless clause of a policy
+type condition struct {
+	body   expr
+	unless bool
+}
+
+// variables are the names bound in every policy
+var variables = map[string]bool{"principal": true, "action": true, "resource": true, "context": true}
+
+// methods maps the supported set methods to their number of arguments
+var methods = map[string]int{"contains": 1, "containsAll": 1, "containsAny": 1, "isEmpty": 0}
+
+// parsePolicies parses the content of a Cedar policy set. Policies without an @id annotation are identified by their
+// position in the set, as policy0, policy1 and so on.
+func parsePolicies(content string) ([]policy, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	policies := make([]policy, 0)
+	ids := make(map[string]bool)
+
+	for p.peek().kind != tokenEOF {
+		parsed, err := p.policy(len(policies))
+		if err != nil {
+			return nil, err
+		}
+
+		if ids[parsed.id] {
+			return nil, fmt.Errorf("duplicate policy id %q", parsed.id)
+		}
+
+		ids[parsed.id] = true
+		policies = append(policies, parsed)
+	}
+
+	return policies, nil
+}
+
+// parser is a recursive descent parser over the tokens of a policy set
+type parser struct {
+	tokens []token
+	offset int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.offset]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.offset]
+	if tok.kind != tokenEOF {
+		p.offset++
+	}
+
+	return tok
+}
+
+// peekIs reports whether the next token is the given punctuation or keyword
+func (p *parser) peekIs(text string) bool {
+	tok := p.peek()
+	return (tok.kind == tokenPunct || tok.kind == tokenIdent) && tok.text == text
+}
+
+// accept consumes the next token when it is the given punctuation or keyword
+func (p *parser) accept(text string) bool {
+	if p.peekIs(text) {
+		p.offset++
+		return true
+	}
+
+	return false
+}
+
+// expect consumes the next token, which must be the given punctuation or keyword
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected(fmt.Sprintf("%q", text))
+	}
+
+	return nil
+}
+
+// unexpected reports the next token where something else was expected
+func (p *parser) unexpected(expected string) error {
+	tok := p.peek()
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("%s: unexpected end of policy, expected %s", tok.pos, expected)
+	}
+
+	return fmt.Errorf("%s: unexpected %q, expected %s", tok.pos, tok.text, expected)
+}
+
+func (p *parser) ident() (string, error) {
+	tok := p.peek()
+	if tok.kind != tokenIdent {
+		return "", p.unexpected("identifier")
+	}
+
+	p.offset++
+	return tok.text, nil
+}
+
+func (p *parser) str(wildcards bool) (string, []int, error) {
+	tok := p.peek()
+	if tok.kind != tokenString {
+		return "", nil, p.unexpected("string literal")
+	}
+
+	p.offset++
+	decoded, stars, err := unescape(tok.text, wildcards)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", tok.pos, err)
+	}
+
+	return decoded, stars, nil
+}
+
+func (p *parser) policy(idx int) (policy, error) {
+	parsed := policy{id: "policy" + strconv.Itoa(idx)}
+	if err := p.annotations(&parsed); err != nil {
+		return policy{}, err
+	}
+
+	effectName := p.peek().text
+	if !p.accept(string(effectPermit)) && !p.accept(string(effectForbid)) {
+		return policy{}, p.unexpected(`"permit" or "forbid"`)
+	}
+
+	parsed.effect = effect(effectName)
+
+	scope, err := p.scope()
+	if err != nil {
+		return policy{}, err
+	}
+
+	parsed.scope = scope
+	for p.peekIs("when") || p.peekIs("unless") {
+		unless := p.next().text == "unless"
+		body, err := p.block()
+		if err != nil {
+			return policy{}, err
+		}
+
+		parsed.conditions = append(parsed.conditions, condition{body: body, unless: unless})
+	}
+
+	return parsed, p.expect(";")
+}
+
+// annotations parses the annotations of a policy, taking the policy ID from @id
+func (p *parser) annotations(parsed *policy) error {
+	seen := make(map[string]bool)
+	for p.accept("@") {
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+
+		if seen[name] {
+			return fmt.Errorf("duplicate annotation @%s", name)
+		}
+		seen[name] = true
+
+		var annotation string
+		if p.accept("(") {
+			if annotation, _, err = p.str(false); err != nil {
+				return err
+			}
+
+			if err := p.expect(")"); err != nil {
+				return err
+			}
+		}
+
+		if name == "id" {
+			parsed.id = annotation
+		}
+	}
+
+	return nil
+}
+
+// scope parses the principal, action and resource constraints of a policy
+func (p *parser) scope() ([]expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	scope := make([]expr, 0)
+	for idx, variable := range []string{"principal", "action", "resource"} {
+		if idx > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := p.expect(variable); err != nil {
+			return nil, err
+		}
+
+		constraint, err := p.scopeConstraint(variable)
+		if err != nil {
+			return nil, err
+		}
+
+		if constraint != nil {
+			scope = append(scope, constraint)
+		}
+	}
+
+	return scope, p.expect(")")
+}
+
+// scopeConstraint parses the optional constraint following a scope variable, returning nil when there is none
+func (p *parser) scopeConstraint(variable string) (expr, error) {
+	operand := variableExpr{name: variable}
+
+	switch {
+	case p.accept("=="):
+		uid, err := p.entityRef()
+		return compareExpr{op: "==", left: operand, right: literalExpr{value: uid}}, err
+	case p.accept("in"):
+		if variable == "action" && p.peekIs("[") {
+			ancestors, err := p.primary()
+			return inExpr{left: operand, right: ancestors}, err
+		}
+
+		uid, err := p.entityRef()
+		return inExpr{left: operand, right: literalExpr{value: uid}}, err
+	case variable != "action" && p.accept("is"):
+		entityType, err := p.path()
+		if err != nil || !p.accept("in") {
+			return isExpr{operand: operand, entityType: entityType}, err
+		}
+
+		uid, err := p.entityRef()
+		return isExpr{operand: operand, entityType: entityType, in: literalExpr{value: uid}}, err
+	default:
+		return nil, nil
+	}
+}
+
+// block parses the braced expression of a when or unless clause
+func (p *parser) block() (expr, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	body, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	return body, p.expect("}")
+}
+
+// path parses a possibly namespaced entity type name, such as Photo or PhotoApp::Photo
+func (p *parser) path() (string, error) {
+	name, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+
+	for p.peekIs("::") && p.tokens[p.offset+1].kind == tokenIdent {
+		p.offset++
+		segment, _ := p.ident()
+		name += "::" + segment
+	}
+
+	return name, nil
+}
+
+// entityRef parses an entity reference, such as User::"alice"
+func (p *parser) entityRef() (entityUID, error) {
+	entityType, err := p.path()
+	if err != nil {
+		return entityUID{}, err
+	}
+
+	if err := p.expect("::"); err != nil {
+		return entityUID{}, err
+	}
+
+	id, _, err := p.str(false)
+	return entityUID{Type: entityType, ID: id}, err
+}
+
+// errUnsupported reports Cedar language features outside the supported subset
+var errUnsupported = errors.New("not supported")
+
+// name parses a variable, or an entity reference starting with the given identifier
+func (p *parser) name(tok token) (expr, error) {
+	if variables[tok.text] && !p.peekIs("::") {
+		return variableExpr{name: tok.text}, nil
+	}
+
+	p.offset--
+	entityType, err := p.path()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.peekIs("("):
+		return nil, fmt.Errorf("%s: extension function %s: %w", tok.pos, entityType, errUnsupported)
+	case !p.peekIs("::"):
+		return nil, fmt.Errorf("%s: unknown variable %s", tok.pos, entityType)
+	}
+
+	p.offset++
+	id, _, err := p.str(false)
+	return literalExpr{value: entityUID{Type: entityType, ID: id}}, err
+}
+
+// patternFromString converts a decoded like pattern and the offsets of its wildcards into pattern characters
+func patternFromString(decoded string, stars []int) []patternChar {
+	wildcards := make(map[int]bool, len(stars))
+	for _, offset := range stars {
+		wildcards[offset] = true
+	}
+
+	pattern := make([]patternChar, 0, len(decoded))
+	for offset, char := range decoded {
+		pattern = append(pattern, patternChar{char: char, wildcard: wildcards[offset]})
+	}
+
+	return pattern
+}
@@ -0,0 +1,312 @@
+package cedar
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// relationOperators lists the binary operators of relations, other than in
+var relationOperators = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// expr parses an expression, from the lowest precedence: if-then-else, ||, &&, relations, + and -, *, unary
+// operators and member access
+func (p *parser) expr() (expr, error) {
+	if !p.accept("if") {
+		return p.or()
+	}
+
+	cond, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+
+	then, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect("else"); err != nil {
+		return nil, err
+	}
+
+	otherwise, err := p.expr()
+	return ifExpr{cond: cond, then: then, otherwise: otherwise}, err
+}
+
+func (p *parser) or() (expr, error) {
+	left, err := p.and()
+	for err == nil && p.accept("||") {
+		var right expr
+		right, err = p.and()
+		left = orExpr{left: left, right: right}
+	}
+
+	return left, err
+}
+
+func (p *parser) and() (expr, error) {
+	left, err := p.relation()
+	for err == nil && p.accept("&&") {
+		var right expr
+		right, err = p.relation()
+		left = andExpr{left: left, right: right}
+	}
+
+	return left, err
+}
+
+func (p *parser) relation() (expr, error) {
+	left, err := p.add()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenPunct && relationOperators[tok.text]:
+		p.offset++
+		right, err := p.add()
+		return compareExpr{op: tok.text, left: left, right: right}, err
+	case p.accept("in"):
+		right, err := p.add()
+		return inExpr{left: left, right: right}, err
+	case p.accept("has"):
+		attribute, err := p.attributeName()
+		return hasExpr{operand: left, attribute: attribute}, err
+	case p.accept("like"):
+		decoded, stars, err := p.str(true)
+		return likeExpr{operand: left, pattern: patternFromString(decoded, stars)}, err
+	case p.accept("is"):
+		return p.is(left)
+	default:
+		return left, nil
+	}
+}
+
+// is parses the entity type, and optional ancestor, following the is operator
+func (p *parser) is(operand expr) (expr, error) {
+	entityType, err := p.path()
+	if err != nil || !p.accept("in") {
+		return isExpr{operand: operand, entityType: entityType}, err
+	}
+
+	ancestor, err := p.add()
+	return isExpr{operand: operand, entityType: entityType, in: ancestor}, err
+}
+
+// attributeName parses an attribute name, written as an identifier or a string literal
+func (p *parser) attributeName() (string, error) {
+	if p.peek().kind == tokenString {
+		name, _, err := p.str(false)
+		return name, err
+	}
+
+	return p.ident()
+}
+
+func (p *parser) add() (expr, error) {
+	left, err := p.mult()
+	for err == nil && (p.peekIs("+") || p.peekIs("-")) {
+		op := p.next().text
+		var right expr
+		right, err = p.mult()
+		left = arithExpr{op: op, left: left, right: right}
+	}
+
+	return left, err
+}
+
+func (p *parser) mult() (expr, error) {
+	left, err := p.unary()
+	for err == nil && p.accept("*") {
+		var right expr
+		right, err = p.unary()
+		left = arithExpr{op: "*", left: left, right: right}
+	}
+
+	return left, err
+}
+
+func (p *parser) unary() (expr, error) {
+	switch {
+	case p.accept("!"):
+		operand, err := p.unary()
+		return notExpr{operand: operand}, err
+	case p.peekIs("-") && p.tokens[p.offset+1].kind == tokenInt:
+		// A negated integer literal, so the smallest long can be written
+		p.offset++
+		literal, err := p.long("-")
+		if err != nil {
+			return nil, err
+		}
+
+		return p.member(literal)
+	case p.accept("-"):
+		operand, err := p.unary()
+		return negExpr{operand: operand}, err
+	default:
+		operand, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+
+		return p.member(operand)
+	}
+}
+
+// long parses an integer literal with the given sign
+func (p *parser) long(sign string) (expr, error) {
+	tok := p.next()
+	n, err := strconv.ParseInt(sign+tok.text, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: integer literal %s%s is out of range", tok.pos, sign, tok.text)
+	}
+
+	return literalExpr{value: n}, nil
+}
+
+// member parses the attribute accesses and method calls following an operand
+func (p *parser) member(operand expr) (expr, error) {
+	for {
+		switch {
+		case p.accept("."):
+			tok := p.peek()
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+
+			if !p.peekIs("(") {
+				operand = accessExpr{operand: operand, attribute: name}
+				continue
+			}
+
+			if operand, err = p.method(tok, operand, name); err != nil {
+				return nil, err
+			}
+		case p.accept("["):
+			attribute, _, err := p.str(false)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+
+			operand = accessExpr{operand: operand, attribute: attribute}
+		default:
+			return operand, nil
+		}
+	}
+}
+
+// method parses the arguments of a method call on the operand
+func (p *parser) method(tok token, operand expr, name string) (expr, error) {
+	arity, ok := methods[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: method %s: %w", tok.pos, name, errUnsupported)
+	}
+
+	args, err := p.list("(", ")")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) != arity {
+		return nil, fmt.Errorf("%s: method %s expects %d argument(s), got %d", tok.pos, name, arity, len(args))
+	}
+
+	return methodExpr{operand: operand, name: name, args: args}, nil
+}
+
+func (p *parser) primary() (expr, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenInt:
+		return p.long("")
+	case tok.kind == tokenString:
+		s, _, err := p.str(false)
+		return literalExpr{value: s}, err
+	case p.accept("true"), p.accept("false"):
+		return literalExpr{value: tok.text == "true"}, nil
+	case tok.kind == tokenIdent:
+		p.offset++
+		return p.name(tok)
+	case p.accept("("):
+		inner, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		return inner, p.expect(")")
+	case p.peekIs("["):
+		items, err := p.list("[", "]")
+		return setExpr{items: items}, err
+	case p.accept("{"):
+		return p.record()
+	default:
+		return nil, p.unexpected("expression")
+	}
+}
+
+// list parses a comma separated list of expressions between the open and close delimiters
+func (p *parser) list(open, closing string) ([]expr, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+
+	items := make([]expr, 0)
+	for !p.accept(closing) {
+		if len(items) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		item, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// record parses the fields of a record literal, after its opening brace
+func (p *parser) record() (expr, error) {
+	fields := make(map[string]expr)
+	for !p.accept("}") {
+		if len(fields) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		tok := p.peek()
+		name, err := p.attributeName()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := fields[name]; exists {
+			return nil, fmt.Errorf("%s: duplicate record attribute %q", tok.pos, name)
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		if fields[name], err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+
+	return recordExpr{fields: fields}, nil
+}
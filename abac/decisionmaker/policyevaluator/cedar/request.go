@@ -0,0 +1,125 @@
+package cedar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+)
+
+// Entity types given to request entities. The subject and resource keep their own type when the request sets one.
+const (
+	DefaultPrincipalType = "Principal"
+	DefaultResourceType  = "Resource"
+	ActionType           = "Action"
+)
+
+// ParentsAttribute is the reserved subject, action and resource attribute listing the direct parents of the entity, as
+// {"type": ..., "id": ...} objects, for the in operator. It is not exposed as an attribute of the entity.
+const ParentsAttribute = "parents"
+
+// newEvalContext maps the subject, action, resource and environment of the request to the principal, action, resource
+// and context of a Cedar evaluation
+func newEvalContext(req *decisionmaker.DecisionRequest) (*evalContext, error) {
+	ctx := &evalContext{
+		variables: make(map[string]value, len(variables)),
+		entities:  make(map[entityUID]entity, 3),
+	}
+
+	requestEntities := []struct {
+		variable   string
+		uid        entityUID
+		attributes map[string]any
+	}{
+		{"principal", entityUID{Type: typeOrDefault(req.Subject.Type, DefaultPrincipalType), ID: req.Subject.ID}, req.Subject.Attributes},
+		{"action", entityUID{Type: ActionType, ID: req.Action.ID}, req.Action.Attributes},
+		{"resource", entityUID{Type: typeOrDefault(req.Resource.Type, DefaultResourceType), ID: req.Resource.ID}, req.Resource.Attributes},
+	}
+
+	for _, requestEntity := range requestEntities {
+		converted, err := newEntity(requestEntity.attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map %s attributes: %w", requestEntity.variable, err)
+		}
+
+		ctx.variables[requestEntity.variable] = requestEntity.uid
+		ctx.entities[requestEntity.uid] = converted
+	}
+
+	environment, err := normalize(req.Environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map environment: %w", err)
+	}
+
+	contextRecord, err := recordFromJSON(environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map environment: %w", err)
+	}
+
+	ctx.variables["context"] = contextRecord
+	return ctx, nil
+}
+
+func typeOrDefault(entityType, defaultType string) string {
+	if entityType == "" {
+		return defaultType
+	}
+
+	return entityType
+}
+
+// newEntity converts request attributes into an entity, taking its parents from the reserved parents attribute
+func newEntity(attributes map[string]any) (entity, error) {
+	normalized, err := normalize(attributes)
+	if err != nil {
+		return entity{}, err
+	}
+
+	var parents []entityUID
+	if raw, ok := normalized[ParentsAttribute]; ok {
+		refs, ok := raw.([]any)
+		if !ok {
+			return entity{}, fmt.Errorf("%s must be a list of entity references", ParentsAttribute)
+		}
+
+		for idx, ref := range refs {
+			parent, err := entityFromJSON(ref)
+			if err != nil {
+				return entity{}, fmt.Errorf("%s[%d]: %w", ParentsAttribute, idx, err)
+			}
+
+			parents = append(parents, parent)
+		}
+
+		delete(normalized, ParentsAttribute)
+	}
+
+	converted, err := recordFromJSON(normalized)
+	if err != nil {
+		return entity{}, err
+	}
+
+	return entity{attributes: converted, parents: parents}, nil
+}
+
+// normalize round-trips the attributes through JSON, so they hold only JSON types with numbers kept as json.Number
+func normalize(attributes map[string]any) (map[string]any, error) {
+	normalized := make(map[string]any, len(attributes))
+	if len(attributes) == 0 {
+		return normalized, nil
+	}
+
+	content, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attributes: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+	}
+
+	return normalized, nil
+}
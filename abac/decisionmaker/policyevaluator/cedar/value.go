@@ -0,0 +1,207 @@
+package cedar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// value is a Cedar value: bool, int64 (long), string, entityUID, set or record
+type value any
+
+// entityUID identifies an entity by type and ID, written Type::"id" in policies
+type entityUID struct {
+	Type string
+	ID   string
+}
+
+func (e entityUID) String() string {
+	return e.Type + "::" + strconv.Quote(e.ID)
+}
+
+// set is a Cedar set; its order carries no meaning
+type set []value
+
+// record is a Cedar record
+type record map[string]value
+
+// entity is an entity known to an evaluation, with its attributes and direct parents
+type entity struct {
+	attributes record
+	parents    []entityUID
+}
+
+// typeName returns the Cedar name of the value type, used in type errors
+func typeName(v value) string {
+	switch v.(type) {
+	case bool:
+		return "bool"
+	case int64:
+		return "long"
+	case string:
+		return "string"
+	case entityUID:
+		return "entity"
+	case set:
+		return "set"
+	case record:
+		return "record"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// equal reports whether two values are equal; values of different types are never equal
+func equal(a, b value) bool {
+	switch a := a.(type) {
+	case set:
+		b, ok := b.(set)
+		return ok && containsAll(a, b) && containsAll(b, a)
+	case record:
+		b, ok := b.(record)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for key, av := range a {
+			bv, ok := b[key]
+			if !ok || !equal(av, bv) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
+
+// contains reports whether the set holds a value equal to v
+func contains(s set, v value) bool {
+	for _, item := range s {
+		if equal(item, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsAll reports whether s holds every value of other
+func containsAll(s, other set) bool {
+	for _, item := range other {
+		if !contains(s, item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// format renders the value in Cedar syntax, with record keys sorted
+func format(v value) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case set:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, format(item))
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case record:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := make([]string, 0, len(v))
+		for _, key := range keys {
+			items = append(items, strconv.Quote(key)+": "+format(v[key]))
+		}
+
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fromJSON converts a value decoded from JSON with json.Number into a Cedar value. Null values are left out of
+// records and sets, and objects of the form {"__entity": {"type": ..., "id": ...}} become entity references, as in the
+// Cedar JSON entity format.
+func fromJSON(v any) (value, bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false, nil
+	case bool, string:
+		return v, true, nil
+	case json.Number:
+		n, err := strconv.ParseInt(v.String(), 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("number %s is not a long", v)
+		}
+
+		return n, true, nil
+	case []any:
+		s := make(set, 0, len(v))
+		for idx, item := range v {
+			converted, ok, err := fromJSON(item)
+			if err != nil {
+				return nil, false, fmt.Errorf("[%d]: %w", idx, err)
+			}
+
+			if ok {
+				s = append(s, converted)
+			}
+		}
+
+		return s, true, nil
+	case map[string]any:
+		if ref, ok := v["__entity"]; ok && len(v) == 1 {
+			uid, err := entityFromJSON(ref)
+			return uid, err == nil, err
+		}
+
+		r, err := recordFromJSON(v)
+		return r, err == nil, err
+	default:
+		return nil, false, fmt.Errorf("unsupported value of type %T", v)
+	}
+}
+
+// recordFromJSON converts a JSON object into a Cedar record
+func recordFromJSON(v map[string]any) (record, error) {
+	r := make(record, len(v))
+	for key, item := range v {
+		converted, ok, err := fromJSON(item)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		if ok {
+			r[key] = converted
+		}
+	}
+
+	return r, nil
+}
+
+// entityFromJSON converts a JSON object of the form {"type": ..., "id": ...} into an entity reference
+func entityFromJSON(v any) (entityUID, error) {
+	ref, ok := v.(map[string]any)
+	if !ok {
+		return entityUID{}, fmt.Errorf("entity reference must be an object with type and id")
+	}
+
+	entityType, typeOK := ref["type"].(string)
+	id, idOK := ref["id"].(string)
+	if !typeOK || !idOK || entityType == "" {
+		return entityUID{}, fmt.Errorf("entity reference must be an object with type and id")
+	}
+
+	return entityUID{Type: entityType, ID: id}, nil
+}
@@ -0,0 +1,79 @@
+package cel
+
+import (
+	"fmt"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/google/cel-go/cel"
+	"sigs.k8s.io/yaml"
+)
+
+// Document is the content of a CEL policy, written in YAML or JSON
+type Document struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule applies its effect, obligations and advice when its CEL condition evaluates to true
+type Rule struct {
+	ID          string                     `json:"id"`
+	Condition   string                     `json:"condition"`
+	Effect      decisionmaker.Decision     `json:"effect"`
+	Obligations []decisionmaker.Obligation `json:"obligations,omitempty"`
+	Advice      []decisionmaker.Advice     `json:"advice,omitempty"`
+}
+
+// compiledRule is a rule with its condition compiled into a CEL program
+type compiledRule struct {
+	Rule
+	program cel.Program
+}
+
+// compilePolicy parses the policy content as a Document and compiles the condition of each rule
+func compilePolicy(env *cel.Env, policy decisionmaker.Policy) ([]compiledRule, error) {
+	var doc Document
+	if err := yaml.Unmarshal(policy.Content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse policy '%s': %w", policy.ID, err)
+	}
+
+	if len(doc.Rules) == 0 {
+		return nil, fmt.Errorf("policy '%s' has no rules", policy.ID)
+	}
+
+	rules := make([]compiledRule, 0, len(doc.Rules))
+	for idx, rule := range doc.Rules {
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("rules[%d]", idx)
+		}
+
+		program, err := compileRule(env, rule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile rule '%s' in policy '%s': %w", rule.ID, policy.ID, err)
+		}
+
+		rules = append(rules, compiledRule{Rule: rule, program: program})
+	}
+
+	return rules, nil
+}
+
+// compileRule validates the rule effect and compiles its condition, which must evaluate to a bool
+func compileRule(env *cel.Env, rule Rule) (cel.Program, error) {
+	if rule.Effect != decisionmaker.Permit && rule.Effect != decisionmaker.Deny {
+		return nil, fmt.Errorf("invalid effect %q, must be one of: Permit, Deny", rule.Effect)
+	}
+
+	if rule.Condition == "" {
+		return nil, fmt.Errorf("condition cannot be empty")
+	}
+
+	ast, issues := env.Compile(rule.Condition)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if outputType := ast.OutputType(); !outputType.IsExactType(cel.BoolType) && !outputType.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("condition must evaluate to bool, got %s", outputType)
+	}
+
+	return env.Program(ast)
+}
@@ -0,0 +1,249 @@
+package cel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/internal/lru"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+const defaultCacheSize = 128
+
+// Variables bound in rule conditions, holding the JSON form of the matching DecisionRequest field
+const (
+	SubjectVariable     = "subject"
+	ResourceVariable    = "resource"
+	ActionVariable      = "action"
+	EnvironmentVariable = "environment"
+)
+
+// variables lists the names bound in every rule condition
+var variables = []string{SubjectVariable, ResourceVariable, ActionVariable, EnvironmentVariable}
+
+// evaluator implements PolicyEvaluator using Common Expression Language (CEL) rules
+type evaluator struct {
+	env                *cel.Env
+	combiningAlgorithm decisionmaker.CombiningAlgorithm
+	cache              *lru.Cache
+}
+
+// Option defines configuration options for the CEL evaluator
+type Option func(*evaluator)
+
+// NewEvaluator creates a PolicyEvaluator treating policy content as a YAML or JSON Document of CEL rules.
+// Rules of all evaluated policies are combined using deny-overrides unless another algorithm is configured. Compiled
+// rules are cached per policy, keyed by policy ID, version and content hash.
+func NewEvaluator(options ...Option) (decisionmaker.PolicyEvaluator, error) {
+	variableType := cel.MapType(cel.StringType, cel.DynType)
+
+	envOptions := make([]cel.EnvOption, 0, len(variables))
+	for _, variable := range variables {
+		envOptions = append(envOptions, cel.Variable(variable, variableType))
+	}
+
+	env, err := cel.NewEnv(envOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	e := &evaluator{
+		env:                env,
+		combiningAlgorithm: decisionmaker.NewDenyOverrides(),
+		cache:              lru.New(defaultCacheSize),
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e, nil
+}
+
+// WithCombiningAlgorithm sets the algorithm used to combine the results of individual rules
+func WithCombiningAlgorithm(alg decisionmaker.CombiningAlgorithm) Option {
+	return func(e *evaluator) {
+		e.combiningAlgorithm = alg
+	}
+}
+
+// WithCacheSize bounds the number of policies whose compiled rules are cached, evicting the least recently used one
+// when full. A size of zero or less disables caching, so policies are parsed and compiled on every evaluation.
+func WithCacheSize(size int) Option {
+	return func(e *evaluator) {
+		if size <= 0 {
+			e.cache = nil
+			return
+		}
+
+		e.cache = lru.New(size)
+	}
+}
+
+// Evaluate runs the rules of the policies against a decision request and combines their results
+func (e *evaluator) Evaluate(
+	ctx context.Context,
+	req *decisionmaker.DecisionRequest,
+	policies []decisionmaker.Policy,
+) (*decisionmaker.EvaluationResult, error) {
+	if req == nil {
+		return nil, errors.New("decision request cannot be nil")
+	}
+
+	if len(policies) == 0 {
+		return nil, errors.New("no policies provided for evaluation")
+	}
+
+	activation, err := newActivation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tracing := decisionmaker.IsTraceEnabled(ctx)
+	results := make([]decisionmaker.EvaluationResult, 0)
+	traces := make([]decisionmaker.RuleTrace, 0)
+
+	for _, policy := range policies {
+		rules, err := e.compiledPolicy(policy)
+		if err != nil {
+			return nil, fmt.Errorf("policy evaluation failed: %w", err)
+		}
+
+		for _, rule := range rules {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			result := evaluateRule(ctx, policy.ID, rule, activation)
+			if result != nil {
+				results = append(results, *result)
+			}
+
+			if tracing {
+				traces = append(traces, decisionmaker.RuleTrace{
+					PolicyID: policy.ID,
+					Rule:     rule.ID,
+					Matched:  result != nil && result.Decision == rule.Effect,
+				})
+			}
+		}
+	}
+
+	result := e.combiningAlgorithm.Combine(results)
+	if tracing {
+		result.Trace = traces
+	}
+
+	return result, nil
+}
+
+// compiledPolicy returns the compiled rules of the policy from the cache, compiling and caching them on a miss
+func (e *evaluator) compiledPolicy(policy decisionmaker.Policy) ([]compiledRule, error) {
+	if e.cache == nil {
+		return compilePolicy(e.env, policy)
+	}
+
+	key := cacheKey(policy)
+	if rules, ok := e.cache.Get(key); ok {
+		return rules.([]compiledRule), nil
+	}
+
+	rules, err := compilePolicy(e.env, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	e.cache.Add(key, rules)
+	return rules, nil
+}
+
+// cacheKey identifies a policy by ID, version and content hash
+func cacheKey(policy decisionmaker.Policy) string {
+	hash := policy.ContentHash
+	if hash == "" {
+		hash = policyprovider.HashContent(policy.Content)
+	}
+
+	return fmt.Sprintf("%s@%s#%s", policy.ID, policy.Version, hash)
+}
+
+// Validate parses each policy document and compiles its rule conditions, without caching them
+func (e *evaluator) Validate(ctx context.Context, policies []decisionmaker.Policy) error {
+	if len(policies) == 0 {
+		return errors.New("no policies provided for validation")
+	}
+
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, err := compilePolicy(e.env, policy); err != nil {
+			return fmt.Errorf("policy validation failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// evaluateRule evaluates the rule condition, returning the rule effect when it holds and nil when it does not apply
+func evaluateRule(ctx context.Context, policyID string, rule compiledRule, activation map[string]any) *decisionmaker.EvaluationResult {
+	value, _, err := rule.program.ContextEval(ctx, activation)
+	if err == nil && !types.IsBool(value) {
+		err = fmt.Errorf("condition evaluated to %s, expected bool", value.Type().TypeName())
+	}
+
+	if err != nil {
+		return &decisionmaker.EvaluationResult{
+			Decision: decisionmaker.Indeterminate,
+			Status: decisionmaker.Status{
+				Code:    decisionmaker.StatusEvaluationError,
+				Message: fmt.Sprintf("Rule '%s' in policy '%s' evaluation failed: %v", rule.ID, policyID, err),
+			},
+		}
+	}
+
+	if value != types.True {
+		return nil
+	}
+
+	return &decisionmaker.EvaluationResult{
+		Decision: rule.Effect,
+		Status: decisionmaker.Status{
+			Code:    decisionmaker.StatusOK,
+			Message: fmt.Sprintf("Rule '%s' in policy '%s' matched", rule.ID, policyID),
+		},
+		Obligations: rule.Obligations,
+		Advice:      rule.Advice,
+	}
+}
+
+// newActivation binds the JSON form of the request subject, resource, action and environment to the rule variables
+func newActivation(req *decisionmaker.DecisionRequest) (map[string]any, error) {
+	content, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal decision request: %w", err)
+	}
+
+	var input map[string]any
+	if err := json.Unmarshal(content, &input); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decision request: %w", err)
+	}
+
+	activation := make(map[string]any, len(variables))
+	for _, variable := range variables {
+		value, ok := input[variable].(map[string]any)
+		if !ok {
+			value = make(map[string]any)
+		}
+
+		activation[variable] = value
+	}
+
+	return activation, nil
+}
@@ -0,0 +1,393 @@
+package cel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluator_Evaluate(t *testing.T) {
+	tests := map[string]struct {
+		options        []Option
+		request        *decisionmaker.DecisionRequest
+		policies       []decisionmaker.Policy
+		expectedResult *decisionmaker.EvaluationResult
+		expectedError  string
+	}{
+		"nil request should return error": {
+			request:       nil,
+			policies:      []decisionmaker.Policy{getOwnerPolicy()},
+			expectedError: "decision request cannot be nil",
+		},
+
+		"empty policies should return error": {
+			request:       newTestRequest("user-1", "user-1", "read"),
+			policies:      []decisionmaker.Policy{},
+			expectedError: "no policies provided for evaluation",
+		},
+
+		"malformed policy should return error": {
+			request:       newTestRequest("user-1", "user-1", "read"),
+			policies:      []decisionmaker.Policy{{ID: "malformed", Content: []byte("rules: [")}},
+			expectedError: "policy evaluation failed: failed to parse policy 'malformed'",
+		},
+
+		"policy without rules should return error": {
+			request:       newTestRequest("user-1", "user-1", "read"),
+			policies:      []decisionmaker.Policy{{ID: "empty", Content: []byte("rules: []")}},
+			expectedError: "policy evaluation failed: policy 'empty' has no rules",
+		},
+
+		"invalid effect should return error": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{{ID: "invalid", Content: []byte(`
+rules:
+  - id: allow
+    condition: "true"
+    effect: Allow
+`)}},
+			expectedError: `policy evaluation failed: failed to parse policy 'invalid'`,
+		},
+
+		"invalid condition should return error": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{{ID: "invalid", Content: []byte(`
+rules:
+  - id: broken
+    condition: "subject.id =="
+    effect: Permit
+`)}},
+			expectedError: "policy evaluation failed: failed to compile rule 'broken' in policy 'invalid'",
+		},
+
+		"non bool condition should return error": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{{ID: "invalid", Content: []byte(`
+rules:
+  - condition: "1 + 1"
+    effect: Permit
+`)}},
+			expectedError: "policy evaluation failed: failed to compile rule 'rules[0]' in policy 'invalid': condition must evaluate to bool, got int",
+		},
+
+		"owner should get permit decision with obligations": {
+			request:  newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{getOwnerPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Rule 'owner-access' in policy 'owner-policy' matched",
+				},
+				Obligations: []decisionmaker.Obligation{
+					{ID: "audit_logging", Attributes: map[string]any{"level": "INFO"}},
+				},
+			},
+		},
+
+		"non owner should get not applicable decision": {
+			request:  newTestRequest("user-1", "user-2", "read"),
+			policies: []decisionmaker.Policy{getOwnerPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.NotApplicable,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusPolicyNotFound,
+					Message: "No applicable policies found for the request",
+				},
+			},
+		},
+
+		"deny rule should override permit rule across policies": {
+			request:  newTestRequest("user-1", "user-1", "delete"),
+			policies: []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Deny,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Rule 'no-delete' in policy 'delete-policy' matched",
+				},
+				Advice: []decisionmaker.Advice{
+					{ID: "contact_support", Attributes: map[string]any{"reason": "orders cannot be deleted"}},
+				},
+			},
+		},
+
+		"configured combining algorithm should be used": {
+			options:  []Option{WithCombiningAlgorithm(decisionmaker.NewFirstApplicable())},
+			request:  newTestRequest("user-1", "user-1", "delete"),
+			policies: []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Rule 'owner-access' in policy 'owner-policy' matched",
+				},
+				Obligations: []decisionmaker.Obligation{
+					{ID: "audit_logging", Attributes: map[string]any{"level": "INFO"}},
+				},
+			},
+		},
+
+		"missing attribute should get indeterminate decision": {
+			request: newTestRequest("user-1", "user-1", "read"),
+			policies: []decisionmaker.Policy{{ID: "json-policy", Content: []byte(
+				`{"rules": [{"id": "region", "condition": "environment.region == 'au'", "effect": "Permit"}]}`,
+			)}},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Indeterminate,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusEvaluationError,
+					Message: "Rule 'region' in policy 'json-policy' evaluation failed: no such key: region",
+				},
+			},
+		},
+
+		"numeric attributes should compare with integer literals": {
+			request: func() *decisionmaker.DecisionRequest {
+				req := newTestRequest("user-1", "user-2", "read")
+				req.Environment = map[string]any{"hour": 10}
+				return req
+			}(),
+			policies: []decisionmaker.Policy{{ID: "hours-policy", Content: []byte(`
+rules:
+  - id: business-hours
+    condition: "environment.hour >= 9 && environment.hour < 17"
+    effect: Permit
+`)}},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusOK,
+					Message: "Rule 'business-hours' in policy 'hours-policy' matched",
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := NewEvaluator(tc.options...)
+			require.NoError(t, err)
+
+			result, err := e.Evaluate(context.Background(), tc.request, tc.policies)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestEvaluator_EvaluateWithTrace(t *testing.T) {
+	e, err := NewEvaluator()
+	require.NoError(t, err)
+
+	ctx := decisionmaker.WithTraceContext(context.Background())
+	result, err := e.Evaluate(ctx, newTestRequest("user-1", "user-1", "delete"), []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()})
+
+	require.NoError(t, err)
+	assert.Equal(t, decisionmaker.Deny, result.Decision)
+	assert.Equal(t, []decisionmaker.RuleTrace{
+		{PolicyID: "owner-policy", Rule: "owner-access", Matched: true},
+		{PolicyID: "owner-policy", Rule: "admin-access", Matched: false},
+		{PolicyID: "delete-policy", Rule: "no-delete", Matched: true},
+	}, result.Trace)
+}
+
+func TestEvaluator_Validate(t *testing.T) {
+	tests := map[string]struct {
+		policies      []decisionmaker.Policy
+		expectedError string
+	}{
+		"valid policies should pass validation": {
+			policies: []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()},
+		},
+
+		"empty policies should return error": {
+			policies:      []decisionmaker.Policy{},
+			expectedError: "no policies provided for validation",
+		},
+
+		"malformed policy should return error": {
+			policies:      []decisionmaker.Policy{{ID: "malformed", Content: []byte("rules: [")}},
+			expectedError: "policy validation failed: failed to parse policy 'malformed'",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator, err := NewEvaluator()
+			require.NoError(t, err)
+
+			validator, ok := evaluator.(decisionmaker.PolicyValidator)
+			require.True(t, ok)
+
+			err = validator.Validate(context.Background(), tc.policies)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEvaluator_ProgramCache(t *testing.T) {
+	request := newTestRequest("user-1", "user-1", "read")
+
+	changedOwnerPolicy := getOwnerPolicy()
+	changedOwnerPolicy.Content = append([]byte("# changed\n"), changedOwnerPolicy.Content...)
+
+	tests := map[string]struct {
+		options         []Option
+		evaluations     [][]decisionmaker.Policy
+		expectedEntries int
+	}{
+		"should reuse compiled rules for the same policy": {
+			evaluations: [][]decisionmaker.Policy{
+				{getOwnerPolicy(), getDeletePolicy()},
+				{getDeletePolicy()},
+			},
+			expectedEntries: 2,
+		},
+
+		"should compile rules again when policy content changes": {
+			evaluations: [][]decisionmaker.Policy{
+				{getOwnerPolicy()},
+				{changedOwnerPolicy},
+			},
+			expectedEntries: 2,
+		},
+
+		"should evict compiled rules beyond the cache size": {
+			options: []Option{WithCacheSize(1)},
+			evaluations: [][]decisionmaker.Policy{
+				{getOwnerPolicy()},
+				{changedOwnerPolicy},
+			},
+			expectedEntries: 1,
+		},
+
+		"should not cache invalid policies": {
+			evaluations: [][]decisionmaker.Policy{
+				{{ID: "invalid", Content: []byte("rules: [")}},
+			},
+			expectedEntries: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := NewEvaluator(tc.options...)
+			require.NoError(t, err)
+
+			for _, policies := range tc.evaluations {
+				_, _ = e.Evaluate(context.Background(), request, policies)
+			}
+
+			assert.Equal(t, tc.expectedEntries, e.(*evaluator).cache.Len())
+		})
+	}
+
+	t.Run("should disable cache when size is zero", func(t *testing.T) {
+		e, err := NewEvaluator(WithCacheSize(0))
+		require.NoError(t, err)
+
+		result, err := e.Evaluate(context.Background(), request, []decisionmaker.Policy{getOwnerPolicy()})
+
+		require.NoError(t, err)
+		assert.Equal(t, decisionmaker.Permit, result.Decision)
+		assert.Nil(t, e.(*evaluator).cache)
+	})
+}
+
+func BenchmarkEvaluator_Evaluate(b *testing.B) {
+	request := newTestRequest("user-1", "user-1", "read")
+	policies := []decisionmaker.Policy{getOwnerPolicy(), getDeletePolicy()}
+
+	benchmarks := map[string][]Option{
+		"cached":   nil,
+		"uncached": {WithCacheSize(0)},
+	}
+
+	for name, options := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			e, err := NewEvaluator(options...)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := e.Evaluate(ctx, request, policies); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func newTestRequest(subjectID, ownerID, action string) *decisionmaker.DecisionRequest {
+	return &decisionmaker.DecisionRequest{
+		Subject: decisionmaker.Subject{
+			ID:         subjectID,
+			Type:       "user",
+			Attributes: map[string]any{"roles": []string{"customer"}},
+		},
+		Resource: decisionmaker.Resource{
+			ID:         "order-1",
+			Type:       "order",
+			Attributes: map[string]any{"user_id": ownerID},
+		},
+		Action: decisionmaker.Action{ID: action},
+	}
+}
+
+func getOwnerPolicy() decisionmaker.Policy {
+	return decisionmaker.Policy{
+		ID:      "owner-policy",
+		Version: "1.0",
+		Content: []byte(`
+rules:
+  - id: owner-access
+    condition: resource.attributes.user_id == subject.id
+    effect: Permit
+    obligations:
+      - id: audit_logging
+        attributes:
+          level: INFO
+  - id: admin-access
+    condition: "'admin' in subject.attributes.roles"
+    effect: Permit
+`),
+	}
+}
+
+func getDeletePolicy() decisionmaker.Policy {
+	return decisionmaker.Policy{
+		ID:      "delete-policy",
+		Version: "1.0",
+		Content: []byte(`
+rules:
+  - id: no-delete
+    condition: action.id == 'delete'
+    effect: Deny
+    advice:
+      - id: contact_support
+        attributes:
+          reason: orders cannot be deleted
+`),
+	}
+}
@@ -5,20 +5,77 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/internal/lru"
+	"github.com/CameronXie/access-control-explorer/abac/internal/sharedcall"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/open-policy-agent/opa/v1/topdown"
+	"github.com/open-policy-agent/opa/v1/util"
+)
+
+const (
+	defaultCacheSize      = 128
+	defaultPrepareTimeout = 30 * time.Second
+	dataDocumentIDSuffix  = ".json"
+	missingAttributesVar  = "missing_attributes"
 )
 
 // evaluator implements PolicyEvaluator using Open Policy Agent (OPA) Rego
 type evaluator struct {
-	query string
+	query        string
+	missingQuery string
+	cache        *lru.Cache
+	prepare      *sharedcall.Group
+}
+
+// Option defines configuration options for the OPA evaluator
+type Option func(*evaluator)
+
+// NewEvaluator creates a PolicyEvaluator instance with the specified Rego query.
+// Policies are loaded as Rego modules, except those with a ".json" ID, such as the data.json of an OPA bundle, which
+// are merged into the base documents under data. Prepared queries are cached per set of policies, keyed by policy ID,
+// version and content hash.
+func NewEvaluator(query string, options ...Option) decisionmaker.PolicyEvaluator {
+	e := &evaluator{
+		query:   query,
+		cache:   lru.New(defaultCacheSize),
+		prepare: sharedcall.New(defaultPrepareTimeout),
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
 }
 
-// NewEvaluator creates a PolicyEvaluator instance with the specified Rego query
-func NewEvaluator(query string) decisionmaker.PolicyEvaluator {
-	return &evaluator{
-		query: query,
+// WithCacheSize bounds the number of cached prepared queries, evicting the least recently used one when full.
+// A size of zero or less disables caching, so policies are parsed and compiled on every evaluation.
+func WithCacheSize(size int) Option {
+	return func(e *evaluator) {
+		if size <= 0 {
+			e.cache = nil
+			return
+		}
+
+		e.cache = lru.New(size)
+	}
+}
+
+// WithMissingAttributesRule collects the attribute designators reported by the rule at the given reference, such as
+// data.abac.missing, alongside the query result. The rule is conventionally a set of {"category", "path"} objects for
+// the request attributes the policies needed but could not find. When it reports any attribute, the evaluation results
+// in an Indeterminate decision with StatusMissingAttribute listing them; an undefined rule reports none.
+func WithMissingAttributesRule(ref string) Option {
+	return func(e *evaluator) {
+		e.missingQuery = ref
 	}
 }
 
@@ -36,21 +93,22 @@ func (e *evaluator) Evaluate(
 		return nil, errors.New("no policies provided for evaluation")
 	}
 
-	// Build Rego configuration
-	regoArgs := []func(*rego.Rego){
-		rego.Query(e.query),
-		rego.Input(req),
+	query, err := e.preparedQuery(ctx, policies)
+	if err != nil {
+		return nil, fmt.Errorf("policy evaluation failed: %w", err)
 	}
 
-	// Add policies as Rego modules
-	for _, policy := range policies {
-		moduleName := fmt.Sprintf("policy_%s", policy.ID)
-		regoArgs = append(regoArgs, rego.Module(moduleName, string(policy.Content)))
+	evalArgs := []rego.EvalOption{rego.EvalInput(req)}
+
+	// Record evaluation events when tracing is requested
+	var tracer *topdown.BufferTracer
+	if decisionmaker.IsTraceEnabled(ctx) {
+		tracer = topdown.NewBufferTracer()
+		evalArgs = append(evalArgs, rego.EvalQueryTracer(tracer))
 	}
 
 	// Execute policy evaluation
-	instance := rego.New(regoArgs...)
-	resultSet, err := instance.Eval(ctx)
+	resultSet, err := query.Eval(ctx, evalArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("policy evaluation failed: %w", err)
 	}
@@ -60,7 +118,195 @@ func (e *evaluator) Evaluate(
 	}
 
 	// Convert result to EvaluationResult
-	return convertResult(resultSet[0].Expressions[0].Value)
+	result, err := convertResult(resultSet[0].Expressions[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = e.reportMissingAttributes(result, resultSet[0].Bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	if tracer != nil {
+		result.Trace = convertTrace(*tracer, policyIDs(policies))
+	}
+
+	return result, nil
+}
+
+// Validate parses and compiles the policies with the evaluator query, without caching the prepared query
+func (e *evaluator) Validate(ctx context.Context, policies []decisionmaker.Policy) error {
+	if len(policies) == 0 {
+		return errors.New("no policies provided for validation")
+	}
+
+	if _, err := e.prepareQuery(ctx, policies); err != nil {
+		return fmt.Errorf("policy validation failed: %w", err)
+	}
+
+	return nil
+}
+
+// preparedQuery returns the prepared query for the policies from the cache, preparing and caching it on a miss.
+// Concurrent misses for the same policies share a single preparation, which carries on when the caller that started it
+// gives up.
+func (e *evaluator) preparedQuery(ctx context.Context, policies []decisionmaker.Policy) (rego.PreparedEvalQuery, error) {
+	if e.cache == nil {
+		return e.prepareQuery(ctx, policies)
+	}
+
+	key := cacheKey(policies)
+	if query, ok := e.cache.Get(key); ok {
+		return query.(rego.PreparedEvalQuery), nil
+	}
+
+	value, err := e.prepare.Do(ctx, key, func(ctx context.Context) (any, error) {
+		query, err := e.prepareQuery(ctx, policies)
+		if err != nil {
+			return nil, err
+		}
+
+		e.cache.Add(key, query)
+		return query, nil
+	})
+	if err != nil {
+		return rego.PreparedEvalQuery{}, err
+	}
+
+	return value.(rego.PreparedEvalQuery), nil
+}
+
+// prepareQuery parses and compiles the policies as Rego modules into a query ready for evaluation
+func (e *evaluator) prepareQuery(ctx context.Context, policies []decisionmaker.Policy) (rego.PreparedEvalQuery, error) {
+	// Build Rego configuration
+	regoArgs := []func(*rego.Rego){
+		rego.Query(e.fullQuery()),
+	}
+
+	// Add policies as Rego modules, and data documents as base documents
+	data := make(map[string]any)
+	for _, policy := range policies {
+		if !isDataDocument(policy) {
+			regoArgs = append(regoArgs, rego.Module(moduleName(policy), string(policy.Content)))
+			continue
+		}
+
+		var document map[string]any
+		if err := util.UnmarshalJSON(policy.Content, &document); err != nil {
+			return rego.PreparedEvalQuery{}, fmt.Errorf("failed to parse data document '%s': %w", policy.ID, err)
+		}
+
+		mergeDocuments(data, document)
+	}
+
+	if len(data) > 0 {
+		regoArgs = append(regoArgs, rego.Store(inmem.NewFromObject(data)))
+	}
+
+	return rego.New(regoArgs...).PrepareForEval(ctx)
+}
+
+// fullQuery returns the evaluator query, extended to bind the attributes reported by the missing attributes rule.
+// The comprehension keeps the query defined when the rule is undefined.
+func (e *evaluator) fullQuery() string {
+	if e.missingQuery == "" {
+		return e.query
+	}
+
+	return fmt.Sprintf("%s; %s := [attribute | attribute := %s[_]]", e.query, missingAttributesVar, e.missingQuery)
+}
+
+// isDataDocument reports whether the policy holds a JSON data document rather than a Rego module
+func isDataDocument(policy decisionmaker.Policy) bool {
+	return strings.HasSuffix(policy.ID, dataDocumentIDSuffix)
+}
+
+// mergeDocuments deep merges the source document into the destination, with source values taking precedence
+func mergeDocuments(dst, src map[string]any) {
+	for key, value := range src {
+		srcObject, srcIsObject := value.(map[string]any)
+		dstObject, dstIsObject := dst[key].(map[string]any)
+		if srcIsObject && dstIsObject {
+			mergeDocuments(dstObject, srcObject)
+			continue
+		}
+
+		dst[key] = value
+	}
+}
+
+// cacheKey identifies a set of policies by ID, version and content hash, regardless of their order.
+// The hash reported by the provider is used when present, so content is only hashed when the provider does not.
+func cacheKey(policies []decisionmaker.Policy) string {
+	keys := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		hash := policy.ContentHash
+		if hash == "" {
+			hash = policyprovider.HashContent(policy.Content)
+		}
+
+		keys = append(keys, fmt.Sprintf("%s@%s#%s", policy.ID, policy.Version, hash))
+	}
+
+	sort.Strings(keys)
+	return strings.Join(keys, "\n")
+}
+
+// moduleName returns the Rego module name under which the policy is loaded
+func moduleName(policy decisionmaker.Policy) string {
+	return fmt.Sprintf("policy_%s", policy.ID)
+}
+
+// policyIDs maps Rego module names to the IDs of the policies loaded under them
+func policyIDs(policies []decisionmaker.Policy) map[string]string {
+	ids := make(map[string]string, len(policies))
+	for _, policy := range policies {
+		ids[moduleName(policy)] = policy.ID
+	}
+
+	return ids
+}
+
+// convertTrace summarises OPA trace events into one RuleTrace per evaluated rule, in evaluation order.
+// A rule matched if its body succeeded at least once; rules skipped by OPA's rule indexing are not reported.
+func convertTrace(events []*topdown.Event, policyIDs map[string]string) []decisionmaker.RuleTrace {
+	rules := make([]decisionmaker.RuleTrace, 0)
+	indexes := make(map[string]int)
+
+	for _, event := range events {
+		rule, ok := event.Node.(*ast.Rule)
+		if !ok || event.Location == nil || (event.Op != topdown.EnterOp && event.Op != topdown.ExitOp) {
+			continue
+		}
+
+		location := event.Location.String()
+		idx, exists := indexes[location]
+		if !exists {
+			idx = len(rules)
+			indexes[location] = idx
+			rules = append(rules, decisionmaker.RuleTrace{
+				PolicyID: policyIDs[event.Location.File],
+				Rule:     ruleName(rule),
+				Location: location,
+			})
+		}
+
+		if event.Op == topdown.ExitOp {
+			rules[idx].Matched = true
+		}
+	}
+
+	return rules
+}
+
+// ruleName returns the full path of the rule, falling back to its head when the rule is detached from its module
+func ruleName(rule *ast.Rule) string {
+	if rule.Module == nil {
+		return rule.Head.Ref().String()
+	}
+
+	return rule.Ref().String()
 }
 
 // convertResult transforms OPA evaluation output to EvaluationResult struct
@@ -77,3 +323,63 @@ func convertResult(value any) (*decisionmaker.EvaluationResult, error) {
 
 	return &result, nil
 }
+
+// reportMissingAttributes replaces the result with an Indeterminate result listing the attributes reported by the
+// missing attributes rule, if any
+func (e *evaluator) reportMissingAttributes(
+	result *decisionmaker.EvaluationResult,
+	bindings rego.Vars,
+) (*decisionmaker.EvaluationResult, error) {
+	if e.missingQuery == "" {
+		return result, nil
+	}
+
+	attributes, err := convertMissingAttributes(bindings[missingAttributesVar])
+	if err != nil || len(attributes) == 0 {
+		return result, err
+	}
+
+	names := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		names = append(names, attribute.String())
+	}
+
+	return &decisionmaker.EvaluationResult{
+		Decision: decisionmaker.Indeterminate,
+		Status: decisionmaker.Status{
+			Code:              decisionmaker.StatusMissingAttribute,
+			Message:           fmt.Sprintf("Missing attributes: %s", strings.Join(names, ", ")),
+			MissingAttributes: attributes,
+		},
+	}, nil
+}
+
+// convertMissingAttributes transforms the attributes reported by the missing attributes rule to AttributeDesignators
+func convertMissingAttributes(value any) ([]decisionmaker.AttributeDesignator, error) {
+	attributesBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal missing attributes: %w", err)
+	}
+
+	var attributes []decisionmaker.AttributeDesignator
+	if err := json.Unmarshal(attributesBytes, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal missing attributes: %w", err)
+	}
+
+	for idx, attribute := range attributes {
+		switch attribute.Category {
+		case policyprovider.AttributeCategorySubject,
+			policyprovider.AttributeCategoryResource,
+			policyprovider.AttributeCategoryAction,
+			policyprovider.AttributeCategoryEnvironment:
+		default:
+			return nil, fmt.Errorf("missing attribute at index %d has unknown category %q", idx, attribute.Category)
+		}
+
+		if attribute.Path == "" {
+			return nil, fmt.Errorf("missing attribute at index %d has no path", idx)
+		}
+	}
+
+	return attributes, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEvaluator_Evaluate(t *testing.T) {
@@ -117,6 +118,30 @@ func TestEvaluator_Evaluate(t *testing.T) {
 				},
 			},
 		},
+
+		"invalid data document should return error": {
+			query:   "data.abac.result",
+			request: newTestRequest([]string{"admin"}, "read"),
+			policies: []decisionmaker.Policy{
+				getDataPolicy(),
+				{ID: "invalid.json", Content: []byte("[")},
+			},
+			expectedError: "policy evaluation failed: failed to parse data document 'invalid.json'",
+		},
+
+		"data documents should be merged into base documents": {
+			query:   "data.abac.result",
+			request: newTestRequest([]string{"auditor"}, "read"),
+			policies: []decisionmaker.Policy{
+				getDataPolicy(),
+				{ID: "roles/data.json", Content: []byte(`{"roles": {"auditor": {"actions": ["read"]}}}`)},
+				{ID: "extra/data.json", Content: []byte(`{"roles": {"viewer": {"actions": ["read"]}}}`)},
+			},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status:   decisionmaker.Status{Code: "OK", Message: "Access granted by roles data"},
+			},
+		},
 	}
 
 	for name, tc := range tests {
@@ -137,6 +162,288 @@ func TestEvaluator_Evaluate(t *testing.T) {
 	}
 }
 
+func TestEvaluator_EvaluateWithTrace(t *testing.T) {
+	tests := map[string]struct {
+		ctx           context.Context
+		request       *decisionmaker.DecisionRequest
+		policies      []decisionmaker.Policy
+		expectedTrace []decisionmaker.RuleTrace
+	}{
+		"should not record trace when tracing is not requested": {
+			ctx:      context.Background(),
+			request:  newTestRequest([]string{"admin"}, "read"),
+			policies: []decisionmaker.Policy{getSubjectPolicy()},
+		},
+
+		"should record matched rules when tracing is requested": {
+			ctx:      decisionmaker.WithTraceContext(context.Background()),
+			request:  newTestRequest([]string{"admin"}, "read"),
+			policies: []decisionmaker.Policy{getSubjectPolicy()},
+			expectedTrace: []decisionmaker.RuleTrace{
+				{PolicyID: "subject-policy", Rule: "data.abac.result", Location: "policy_subject-policy:24", Matched: true},
+				{PolicyID: "subject-policy", Rule: "data.abac.user_is_admin", Location: "policy_subject-policy:44", Matched: true},
+			},
+		},
+
+		"should record failed rules and skip rules excluded by indexing when tracing is requested": {
+			ctx:      decisionmaker.WithTraceContext(context.Background()),
+			request:  newTestRequest([]string{"customer"}, "create"),
+			policies: []decisionmaker.Policy{getSubjectPolicy(), getResourcePolicy()},
+			expectedTrace: []decisionmaker.RuleTrace{
+				{PolicyID: "subject-policy", Rule: "data.abac.result", Location: "policy_subject-policy:24", Matched: false},
+				{PolicyID: "subject-policy", Rule: "data.abac.user_is_admin", Location: "policy_subject-policy:44", Matched: false},
+				{PolicyID: "subject-policy", Rule: "data.abac.result", Location: "policy_subject-policy:6", Matched: true},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := NewEvaluator("data.abac.result").Evaluate(tc.ctx, tc.request, tc.policies)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTrace, result.Trace)
+		})
+	}
+}
+
+func TestEvaluator_EvaluateWithMissingAttributesRule(t *testing.T) {
+	policy := decisionmaker.Policy{
+		ID: "missing-policy",
+		Content: []byte(`
+package abac
+
+default result := {"decision": "Deny", "status": {"code": "OK"}}
+
+result := {"decision": "Permit", "status": {"code": "OK"}} if {
+	"admin" in input.subject.attributes.roles
+}
+
+missing contains {"category": "subject", "path": "roles"} if {
+	not input.subject.attributes.roles
+}
+
+missing contains {"category": "environment", "path": "region"} if {
+	input.action.id == "delete"
+	not input.environment.region
+}
+`),
+	}
+
+	tests := map[string]struct {
+		request        *decisionmaker.DecisionRequest
+		policies       []decisionmaker.Policy
+		expectedResult *decisionmaker.EvaluationResult
+		expectedError  string
+	}{
+		"should return query result when no attributes are missing": {
+			request:        newTestRequest([]string{"admin"}, "read"),
+			policies:       []decisionmaker.Policy{policy},
+			expectedResult: &decisionmaker.EvaluationResult{Decision: decisionmaker.Permit, Status: decisionmaker.Status{Code: "OK"}},
+		},
+		"should return query result when missing attributes rule is undefined": {
+			request:  newTestRequest([]string{"admin"}, "read"),
+			policies: []decisionmaker.Policy{getSubjectPolicy()},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Permit,
+				Status:   decisionmaker.Status{Code: "OK", Message: "Access granted - Administrative privileges verified for user"},
+				Obligations: []decisionmaker.Obligation{{
+					ID: "audit_logging",
+					Attributes: map[string]any{
+						"level":   "INFO",
+						"message": "Administrative access granted to user with verified admin role",
+					},
+				}},
+			},
+		},
+		"should return indeterminate result listing missing attributes": {
+			request: &decisionmaker.DecisionRequest{
+				Subject: decisionmaker.Subject{ID: "user123", Type: "user"},
+				Action:  decisionmaker.Action{ID: "delete"},
+			},
+			policies: []decisionmaker.Policy{policy},
+			expectedResult: &decisionmaker.EvaluationResult{
+				Decision: decisionmaker.Indeterminate,
+				Status: decisionmaker.Status{
+					Code:    decisionmaker.StatusMissingAttribute,
+					Message: "Missing attributes: environment.region, subject.roles",
+					MissingAttributes: []decisionmaker.AttributeDesignator{
+						{Category: "environment", Path: "region"},
+						{Category: "subject", Path: "roles"},
+					},
+				},
+			},
+		},
+		"should return error when missing attribute has unknown category": {
+			request: newTestRequest([]string{"admin"}, "read"),
+			policies: []decisionmaker.Policy{{
+				ID: "invalid",
+				Content: []byte(`
+package abac
+
+result := {"decision": "Permit", "status": {"code": "OK"}}
+
+missing contains {"category": "tenant", "path": "id"}
+`),
+			}},
+			expectedError: `missing attribute at index 0 has unknown category "tenant"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEvaluator("data.abac.result", WithMissingAttributesRule("data.abac.missing"))
+			result, err := e.Evaluate(context.Background(), tc.request, tc.policies)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestEvaluator_PreparedQueryCache(t *testing.T) {
+	request := newTestRequest([]string{"admin"}, "read")
+
+	changedSubjectPolicy := getSubjectPolicy()
+	changedSubjectPolicy.Content = append([]byte("# changed\n"), changedSubjectPolicy.Content...)
+
+	tests := map[string]struct {
+		options         []Option
+		evaluations     [][]decisionmaker.Policy
+		expectedEntries int
+	}{
+		"should reuse prepared query for the same policies in any order": {
+			evaluations: [][]decisionmaker.Policy{
+				{getSubjectPolicy(), getResourcePolicy()},
+				{getResourcePolicy(), getSubjectPolicy()},
+			},
+			expectedEntries: 1,
+		},
+
+		"should prepare a new query when policy content changes": {
+			evaluations: [][]decisionmaker.Policy{
+				{getSubjectPolicy()},
+				{changedSubjectPolicy},
+			},
+			expectedEntries: 2,
+		},
+
+		"should evict prepared queries beyond the cache size": {
+			options: []Option{WithCacheSize(1)},
+			evaluations: [][]decisionmaker.Policy{
+				{getSubjectPolicy()},
+				{changedSubjectPolicy},
+			},
+			expectedEntries: 1,
+		},
+
+		"should not cache invalid policies": {
+			evaluations: [][]decisionmaker.Policy{
+				{{ID: "invalid", Content: []byte("package")}},
+			},
+			expectedEntries: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEvaluator("data.abac.result", tc.options...).(*evaluator)
+
+			for _, policies := range tc.evaluations {
+				_, _ = e.Evaluate(context.Background(), request, policies)
+			}
+
+			assert.Equal(t, tc.expectedEntries, e.cache.Len())
+		})
+	}
+
+	t.Run("should disable cache when size is zero", func(t *testing.T) {
+		e := NewEvaluator("data.abac.result", WithCacheSize(0)).(*evaluator)
+
+		result, err := e.Evaluate(context.Background(), request, []decisionmaker.Policy{getSubjectPolicy()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, decisionmaker.Permit, result.Decision)
+		assert.Nil(t, e.cache)
+	})
+}
+
+func TestEvaluator_Validate(t *testing.T) {
+	tests := map[string]struct {
+		policies      []decisionmaker.Policy
+		expectedError string
+	}{
+		"valid policies should pass validation": {
+			policies: []decisionmaker.Policy{getSubjectPolicy(), getResourcePolicy()},
+		},
+
+		"empty policies should return error": {
+			policies:      []decisionmaker.Policy{},
+			expectedError: "no policies provided for validation",
+		},
+
+		"invalid policy should return error": {
+			policies:      []decisionmaker.Policy{{ID: "invalid", Content: []byte("package")}},
+			expectedError: "policy validation failed: 1 error occurred: policy_invalid:1: rego_parse_error: unexpected eof token",
+		},
+
+		"conflicting policies should return error": {
+			policies:      []decisionmaker.Policy{getSubjectPolicy(), getDataPolicy()},
+			expectedError: "multiple default rules data.abac.result found",
+		},
+
+		"invalid data document should return error": {
+			policies:      []decisionmaker.Policy{{ID: "data.json", Content: []byte("{")}},
+			expectedError: "policy validation failed: failed to parse data document 'data.json'",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			validator, ok := NewEvaluator("data.abac.result").(decisionmaker.PolicyValidator)
+			require.True(t, ok)
+
+			err := validator.Validate(context.Background(), tc.policies)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func BenchmarkEvaluator_Evaluate(b *testing.B) {
+	request := newTestRequest([]string{"customer"}, "update")
+	policies := []decisionmaker.Policy{getSubjectPolicy(), getResourcePolicy()}
+
+	benchmarks := map[string][]Option{
+		"cached":   nil,
+		"uncached": {WithCacheSize(0)},
+	}
+
+	for name, options := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			e := NewEvaluator("data.abac.result", options...)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := e.Evaluate(ctx, request, policies); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func TestEvaluator_ConvertResult(t *testing.T) {
 	tests := map[string]struct {
 		input          any
@@ -247,6 +554,24 @@ user_is_admin if {
 	}
 }
 
+// getDataPolicy returns a Rego policy permitting the actions listed for the subject's roles in data.roles
+func getDataPolicy() decisionmaker.Policy {
+	content := `
+package abac
+
+default result := {"decision": "NotApplicable", "status": {"code": "PolicyNotFound", "message": "No role grants the action"}}
+
+result := {"decision": "Permit", "status": {"code": "OK", "message": "Access granted by roles data"}} if {
+	some role in input.subject.attributes.roles
+	input.action.id in data.roles[role].actions
+}`
+	return decisionmaker.Policy{
+		ID:      "data-policy",
+		Version: "1.0",
+		Content: []byte(content),
+	}
+}
+
 // getResourcePolicy returns a Rego policy that handles resource-specific restrictions
 // Implements customer access limitations for product updates
 func getResourcePolicy() decisionmaker.Policy {
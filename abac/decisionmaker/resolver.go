@@ -0,0 +1,95 @@
+package decisionmaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// registeredResolver is a PolicyResolver registered with the decision maker, with its error handling and timeout
+type registeredResolver struct {
+	resolver PolicyResolver
+	name     string
+	optional bool
+	timeout  time.Duration
+}
+
+// ResolverOption defines configuration options for a registered PolicyResolver
+type ResolverOption func(*registeredResolver)
+
+// OptionalResolver marks the resolver as optional: when it fails, its policy references are left out and the decision
+// is made from the other resolvers. Resolvers are required by default, and a failing required resolver results in an
+// Indeterminate decision.
+func OptionalResolver() ResolverOption {
+	return func(r *registeredResolver) {
+		r.optional = true
+	}
+}
+
+// WithResolverTimeout bounds how long the resolver may take, failing it once the timeout elapses
+func WithResolverTimeout(timeout time.Duration) ResolverOption {
+	return func(r *registeredResolver) {
+		r.timeout = timeout
+	}
+}
+
+// WithResolverName sets the name identifying the resolver in decision statuses and traces, its type by default
+func WithResolverName(name string) ResolverOption {
+	return func(r *registeredResolver) {
+		r.name = name
+	}
+}
+
+// newRegisteredResolver registers the resolver with the given options
+func newRegisteredResolver(resolver PolicyResolver, options ...ResolverOption) registeredResolver {
+	r := registeredResolver{
+		resolver: resolver,
+		name:     fmt.Sprintf("%T", resolver),
+	}
+
+	for _, option := range options {
+		option(&r)
+	}
+
+	return r
+}
+
+// resolve calls the resolver, giving up once its timeout elapses even if the resolver does not observe the context
+func (r registeredResolver) resolve(ctx context.Context, req *DecisionRequest) ([]PolicyIdReference, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if r.timeout <= 0 {
+		return r.resolver.Resolve(ctx, req)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	type result struct {
+		refs []PolicyIdReference
+		err  error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		refs, err := r.resolver.Resolve(timeoutCtx, req)
+		done <- result{refs: refs, err: err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-timeoutCtx.Done():
+		res.err = timeoutCtx.Err()
+	}
+
+	// Report the resolver's own timeout, rather than a deadline or cancellation of the caller
+	if res.err != nil && ctx.Err() == nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s: %w", r.timeout, context.DeadlineExceeded)
+	}
+
+	return res.refs, res.err
+}
@@ -0,0 +1,274 @@
+package decisionmaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/internal/sharedcall"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+)
+
+const (
+	defaultTargetRefreshInterval = 30 * time.Second
+	defaultTargetRefreshTimeout  = 30 * time.Second
+	targetRefreshKey             = "policies"
+)
+
+// targetIndexKey buckets policies by the resource type and action ID of their target, empty when the target accepts any
+type targetIndexKey struct {
+	resourceType string
+	actionID     string
+}
+
+// indexedPolicy is a listed policy with its position in the listing, which orders resolved references
+type indexedPolicy struct {
+	position int
+	metadata policyprovider.PolicyMetadata
+}
+
+// targetResolver resolves the policies whose target matches a request from an index of the policies listed by a
+// PolicyLister
+type targetResolver struct {
+	lister          policyprovider.PolicyLister
+	refreshInterval time.Duration
+	now             func() time.Time
+	onError         func(error)
+	refresh         *sharedcall.Group
+
+	mu       sync.RWMutex
+	index    map[targetIndexKey][]indexedPolicy
+	loadedAt time.Time
+}
+
+// TargetResolverOption defines configuration options for the target PolicyResolver
+type TargetResolverOption func(*targetResolver)
+
+// NewTargetResolver creates a PolicyResolver returning the listed policies whose target matches the request, in
+// listing order. The policies are listed on first use and again once the refresh interval has elapsed, so policies
+// added to the provider are resolved without registering a resolver for each of them. Concurrent refreshes share a
+// single listing, and requests keep being resolved against the previous listing when a refresh fails.
+func NewTargetResolver(lister policyprovider.PolicyLister, options ...TargetResolverOption) PolicyResolver {
+	r := &targetResolver{
+		lister:          lister,
+		refreshInterval: defaultTargetRefreshInterval,
+		now:             time.Now,
+		onError:         func(error) {},
+		refresh:         sharedcall.New(defaultTargetRefreshTimeout),
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// WithTargetRefreshInterval sets how long listed policies are used before being listed again, 30 seconds by default
+func WithTargetRefreshInterval(interval time.Duration) TargetResolverOption {
+	return func(r *targetResolver) {
+		r.refreshInterval = interval
+	}
+}
+
+// WithTargetErrorHandler sets the function called when listing the policies fails, including refreshes failing while
+// the previous listing is still served
+func WithTargetErrorHandler(handler func(error)) TargetResolverOption {
+	return func(r *targetResolver) {
+		r.onError = handler
+	}
+}
+
+// Resolve returns references to the listed policies whose target matches the request
+func (r *targetResolver) Resolve(ctx context.Context, req *DecisionRequest) ([]PolicyIdReference, error) {
+	if req == nil {
+		return nil, errors.New("decision request cannot be nil")
+	}
+
+	index, err := r.currentIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the buckets of targets accepting the request's resource type and action ID, or any of them
+	candidates := make([]indexedPolicy, 0)
+	for _, resourceType := range slices.Compact([]string{req.Resource.Type, ""}) {
+		for _, actionID := range slices.Compact([]string{req.Action.ID, ""}) {
+			candidates = append(candidates, index[targetIndexKey{resourceType: resourceType, actionID: actionID}]...)
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b indexedPolicy) int {
+		return a.position - b.position
+	})
+
+	policyRefs := make([]PolicyIdReference, 0, len(candidates))
+	for _, candidate := range candidates {
+		target := candidate.metadata.Target
+		if len(target.SubjectTypes) > 0 && !slices.Contains(target.SubjectTypes, req.Subject.Type) {
+			continue
+		}
+
+		if !matchesAttributes(req, target.Attributes) {
+			continue
+		}
+
+		policyRefs = append(policyRefs, PolicyIdReference{
+			ID:      candidate.metadata.ID,
+			Version: candidate.metadata.Version,
+		})
+	}
+
+	return policyRefs, nil
+}
+
+// currentIndex returns the policy index, listing the policies again when it is older than the refresh interval.
+// The previous index is returned when listing fails, and the error only when there is no previous index.
+func (r *targetResolver) currentIndex(ctx context.Context) (map[targetIndexKey][]indexedPolicy, error) {
+	r.mu.RLock()
+	index, loadedAt := r.index, r.loadedAt
+	r.mu.RUnlock()
+
+	if index != nil && r.now().Sub(loadedAt) < r.refreshInterval {
+		return index, nil
+	}
+
+	refreshed, err := r.refresh.Do(ctx, targetRefreshKey, func(ctx context.Context) (any, error) {
+		policies, err := r.lister.ListPolicies(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to list policies: %w", err)
+			r.onError(err)
+			return nil, err
+		}
+
+		refreshed := indexPolicies(policies)
+
+		r.mu.Lock()
+		r.index, r.loadedAt = refreshed, r.now()
+		r.mu.Unlock()
+
+		return refreshed, nil
+	})
+	if err != nil {
+		if index != nil {
+			return index, nil
+		}
+
+		return nil, err
+	}
+
+	return refreshed.(map[targetIndexKey][]indexedPolicy), nil
+}
+
+// indexPolicies buckets policies under every resource type and action ID pair accepted by their target
+func indexPolicies(policies []policyprovider.PolicyMetadata) map[targetIndexKey][]indexedPolicy {
+	index := make(map[targetIndexKey][]indexedPolicy)
+
+	for position, policy := range policies {
+		resourceTypes := policy.Target.ResourceTypes
+		if len(resourceTypes) == 0 {
+			resourceTypes = []string{""}
+		}
+
+		actionIDs := policy.Target.ActionIDs
+		if len(actionIDs) == 0 {
+			actionIDs = []string{""}
+		}
+
+		for _, resourceType := range slices.Compact(slices.Sorted(slices.Values(resourceTypes))) {
+			for _, actionID := range slices.Compact(slices.Sorted(slices.Values(actionIDs))) {
+				key := targetIndexKey{resourceType: resourceType, actionID: actionID}
+				index[key] = append(index[key], indexedPolicy{position: position, metadata: policy})
+			}
+		}
+	}
+
+	return index
+}
+
+// matchesAttributes reports whether the request satisfies every attribute predicate
+func matchesAttributes(req *DecisionRequest, matches []policyprovider.AttributeMatch) bool {
+	for _, match := range matches {
+		value, ok := requestAttributes(req, match.Category)[match.Attribute]
+		if !ok || !matchesValue(value, match.Values) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// requestAttributes returns the attributes of the request in the given category
+func requestAttributes(req *DecisionRequest, category policyprovider.AttributeCategory) map[string]any {
+	switch category {
+	case policyprovider.AttributeCategorySubject:
+		return req.Subject.Attributes
+	case policyprovider.AttributeCategoryResource:
+		return req.Resource.Attributes
+	case policyprovider.AttributeCategoryAction:
+		return req.Action.Attributes
+	case policyprovider.AttributeCategoryEnvironment:
+		return req.Environment
+	default:
+		return nil
+	}
+}
+
+// matchesValue reports whether the value, or any element of a list value, equals one of the accepted values.
+// Any value matches when no values are accepted.
+func matchesValue(value any, accepted []any) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+
+	if list := reflect.ValueOf(value); list.Kind() == reflect.Slice {
+		for idx := range list.Len() {
+			if matchesValue(list.Index(idx).Interface(), accepted) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return slices.ContainsFunc(accepted, func(candidate any) bool {
+		return equalValues(value, candidate)
+	})
+}
+
+// equalValues compares attribute values, treating numbers of different types as equal when their values are
+func equalValues(a, b any) bool {
+	x, aIsNumber := toNumber(a)
+	y, bIsNumber := toNumber(b)
+	if aIsNumber && bIsNumber {
+		return x == y
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// toNumber converts a numeric attribute value to a float64
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
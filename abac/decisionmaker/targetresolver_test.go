@@ -0,0 +1,243 @@
+package decisionmaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+)
+
+type mockPolicyLister struct {
+	mock.Mock
+}
+
+func (m *mockPolicyLister) ListPolicies(ctx context.Context) ([]policyprovider.PolicyMetadata, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]policyprovider.PolicyMetadata), args.Error(1)
+}
+
+// TestTargetResolver_Resolve tests resolving policies by their targets
+func TestTargetResolver_Resolve(t *testing.T) {
+	policies := []policyprovider.PolicyMetadata{
+		{ID: "default", Version: "v1"},
+		{
+			ID:      "rbac",
+			Version: "v2",
+			Target: policyprovider.PolicyTarget{
+				Attributes: []policyprovider.AttributeMatch{{Category: policyprovider.AttributeCategorySubject, Attribute: "roles"}},
+			},
+		},
+		{
+			ID:      "orders",
+			Version: "v1",
+			Target: policyprovider.PolicyTarget{
+				SubjectTypes:  []string{"user"},
+				ResourceTypes: []string{"order"},
+				ActionIDs:     []string{"read", "update"},
+			},
+		},
+		{
+			ID:      "business-hours",
+			Version: "v3",
+			Target: policyprovider.PolicyTarget{
+				ActionIDs: []string{"update"},
+				Attributes: []policyprovider.AttributeMatch{
+					{Category: policyprovider.AttributeCategoryEnvironment, Attribute: "region", Values: []any{"au", "nz"}},
+					{Category: policyprovider.AttributeCategoryResource, Attribute: "priority", Values: []any{1, 2}},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		request       *DecisionRequest
+		listError     error
+		expectedRefs  []PolicyIdReference
+		expectedError string
+	}{
+		"should resolve policies with empty targets for any request": {
+			request:      &DecisionRequest{Action: Action{ID: "read"}},
+			expectedRefs: []PolicyIdReference{{ID: "default", Version: "v1"}},
+		},
+		"should resolve policies whose attribute is present": {
+			request: &DecisionRequest{
+				Subject: Subject{Attributes: map[string]any{"roles": []string{"admin"}}},
+				Action:  Action{ID: "read"},
+			},
+			expectedRefs: []PolicyIdReference{{ID: "default", Version: "v1"}, {ID: "rbac", Version: "v2"}},
+		},
+		"should resolve policies matching subject type, resource type and action in listing order": {
+			request: &DecisionRequest{
+				Subject:  Subject{Type: "user", Attributes: map[string]any{"roles": []any{"viewer"}}},
+				Resource: Resource{Type: "order"},
+				Action:   Action{ID: "update"},
+			},
+			expectedRefs: []PolicyIdReference{{ID: "default", Version: "v1"}, {ID: "rbac", Version: "v2"}, {ID: "orders", Version: "v1"}},
+		},
+		"should not resolve policies when subject type does not match": {
+			request: &DecisionRequest{
+				Subject:  Subject{Type: "service"},
+				Resource: Resource{Type: "order"},
+				Action:   Action{ID: "read"},
+			},
+			expectedRefs: []PolicyIdReference{{ID: "default", Version: "v1"}},
+		},
+		"should resolve policies when attribute values match": {
+			request: &DecisionRequest{
+				Resource:    Resource{Attributes: map[string]any{"priority": json.Number("2")}},
+				Action:      Action{ID: "update"},
+				Environment: map[string]any{"region": "nz"},
+			},
+			expectedRefs: []PolicyIdReference{{ID: "default", Version: "v1"}, {ID: "business-hours", Version: "v3"}},
+		},
+		"should not resolve policies when an attribute value does not match": {
+			request: &DecisionRequest{
+				Resource:    Resource{Attributes: map[string]any{"priority": 3}},
+				Action:      Action{ID: "update"},
+				Environment: map[string]any{"region": "nz"},
+			},
+			expectedRefs: []PolicyIdReference{{ID: "default", Version: "v1"}},
+		},
+		"should return error when request is nil": {
+			expectedError: "decision request cannot be nil",
+		},
+		"should return error when policies cannot be listed": {
+			request:       &DecisionRequest{Action: Action{ID: "read"}},
+			listError:     errors.New("connection refused"),
+			expectedError: "failed to list policies: connection refused",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			lister := new(mockPolicyLister)
+			if tc.listError != nil {
+				lister.On("ListPolicies", mock.Anything).Return(nil, tc.listError)
+			} else {
+				lister.On("ListPolicies", mock.Anything).Return(policies, nil)
+			}
+
+			refs, err := NewTargetResolver(lister).Resolve(context.Background(), tc.request)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, refs)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRefs, refs)
+		})
+	}
+}
+
+// TestTargetResolver_Refresh tests that policies are listed again once the refresh interval has elapsed
+func TestTargetResolver_Refresh(t *testing.T) {
+	lister := new(mockPolicyLister)
+	lister.On("ListPolicies", mock.Anything).Return([]policyprovider.PolicyMetadata{{ID: "default", Version: "v1"}}, nil).Once()
+	lister.On("ListPolicies", mock.Anything).Return([]policyprovider.PolicyMetadata{{ID: "default", Version: "v2"}}, nil).Once()
+
+	now := time.Now()
+	resolver := NewTargetResolver(lister, WithTargetRefreshInterval(time.Minute)).(*targetResolver)
+	resolver.now = func() time.Time { return now }
+
+	for _, step := range []struct {
+		elapsed         time.Duration
+		expectedVersion string
+	}{
+		{elapsed: 0, expectedVersion: "v1"},
+		{elapsed: 59 * time.Second, expectedVersion: "v1"},
+		{elapsed: time.Second, expectedVersion: "v2"},
+	} {
+		now = now.Add(step.elapsed)
+
+		refs, err := resolver.Resolve(context.Background(), &DecisionRequest{Action: Action{ID: "read"}})
+		require.NoError(t, err)
+		assert.Equal(t, []PolicyIdReference{{ID: "default", Version: step.expectedVersion}}, refs)
+	}
+
+	lister.AssertExpectations(t)
+}
+
+// TestTargetResolver_RefreshFailure tests that the previous listing is served when a refresh fails
+func TestTargetResolver_RefreshFailure(t *testing.T) {
+	lister := new(mockPolicyLister)
+	lister.On("ListPolicies", mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	lister.On("ListPolicies", mock.Anything).Return([]policyprovider.PolicyMetadata{{ID: "default", Version: "v1"}}, nil).Once()
+	lister.On("ListPolicies", mock.Anything).Return(nil, errors.New("connection refused"))
+
+	var handled []error
+	now := time.Now()
+	resolver := NewTargetResolver(
+		lister,
+		WithTargetRefreshInterval(time.Minute),
+		WithTargetErrorHandler(func(err error) { handled = append(handled, err) }),
+	).(*targetResolver)
+	resolver.now = func() time.Time { return now }
+	req := &DecisionRequest{Action: Action{ID: "read"}}
+
+	// Without a previous listing the error is returned
+	_, err := resolver.Resolve(context.Background(), req)
+	assert.EqualError(t, err, "failed to list policies: connection refused")
+
+	refs, err := resolver.Resolve(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []PolicyIdReference{{ID: "default", Version: "v1"}}, refs)
+
+	now = now.Add(time.Minute)
+	refs, err = resolver.Resolve(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []PolicyIdReference{{ID: "default", Version: "v1"}}, refs)
+
+	require.Len(t, handled, 2)
+	assert.EqualError(t, handled[1], "failed to list policies: connection refused")
+	lister.AssertNumberOfCalls(t, "ListPolicies", 3)
+}
+
+// TestTargetResolver_SharesConcurrentRefreshes tests that concurrent requests share a single listing
+func TestTargetResolver_SharesConcurrentRefreshes(t *testing.T) {
+	const callers = 10
+
+	release := make(chan struct{})
+	lister := new(mockPolicyLister)
+	lister.On("ListPolicies", mock.Anything).
+		Run(func(mock.Arguments) { <-release }).
+		Return([]policyprovider.PolicyMetadata{{ID: "default", Version: "v1"}}, nil).
+		Once()
+
+	resolver := NewTargetResolver(lister)
+
+	var wg sync.WaitGroup
+	results := make([][]PolicyIdReference, callers)
+	errs := make([]error, callers)
+	for idx := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[idx], errs[idx] = resolver.Resolve(context.Background(), &DecisionRequest{Action: Action{ID: "read"}})
+		}()
+	}
+
+	// Give callers time to join the listing in flight before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for idx := range callers {
+		require.NoError(t, errs[idx])
+		assert.Equal(t, []PolicyIdReference{{ID: "default", Version: "v1"}}, results[idx])
+	}
+
+	lister.AssertExpectations(t)
+}
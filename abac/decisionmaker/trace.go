@@ -0,0 +1,109 @@
+package decisionmaker
+
+import (
+	"context"
+)
+
+// Trace explains how a decision was reached, covering policy resolution, retrieval and evaluation
+type Trace struct {
+	Resolutions []ResolutionTrace   `json:"resolutions"`
+	Policies    []PolicyIdReference `json:"policies"`
+	Evaluations []EvaluationTrace   `json:"evaluations"`
+}
+
+// ResolutionTrace records the policy references returned by a single PolicyResolver
+type ResolutionTrace struct {
+	Resolver           string              `json:"resolver"`
+	Optional           bool                `json:"optional,omitempty"`
+	PolicyIdReferences []PolicyIdReference `json:"policyIdReferences"`
+	Error              string              `json:"error,omitempty"`
+}
+
+// EvaluationTrace records a single PolicyEvaluator call and the rules it evaluated
+type EvaluationTrace struct {
+	PolicyIdReferences []PolicyIdReference `json:"policyIdReferences"`
+	Decision           Decision            `json:"decision"`
+	Rules              []RuleTrace         `json:"rules,omitempty"`
+	Error              string              `json:"error,omitempty"`
+}
+
+// RuleTrace records whether a rule in a policy matched during evaluation
+type RuleTrace struct {
+	PolicyID string `json:"policyId"`
+	Rule     string `json:"rule"`
+	Location string `json:"location,omitempty"`
+	Matched  bool   `json:"matched"`
+}
+
+// traceContextKey is the context key used to signal that evaluation tracing is requested
+type traceContextKey struct{}
+
+// WithTraceContext returns a context signalling PolicyEvaluators to record rule traces in EvaluationResult.Trace
+func WithTraceContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, true)
+}
+
+// IsTraceEnabled reports whether evaluation tracing is requested for the context
+func IsTraceEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(traceContextKey{}).(bool)
+	return enabled
+}
+
+// setResolutions records the resolution traces, one per resolver in registration order
+func (t *Trace) setResolutions(resolvers []registeredResolver, refs [][]PolicyIdReference, errs []error) {
+	if t == nil {
+		return
+	}
+
+	t.Resolutions = make([]ResolutionTrace, 0, len(resolvers))
+	for idx, resolver := range resolvers {
+		resolution := ResolutionTrace{
+			Resolver:           resolver.name,
+			Optional:           resolver.optional,
+			PolicyIdReferences: refs[idx],
+		}
+
+		if errs[idx] != nil {
+			resolution.Error = errs[idx].Error()
+		}
+
+		t.Resolutions = append(t.Resolutions, resolution)
+	}
+}
+
+// setPolicies records the policies retrieved from the provider
+func (t *Trace) setPolicies(policies []Policy) {
+	if t == nil {
+		return
+	}
+
+	t.Policies = make([]PolicyIdReference, 0, len(policies))
+	for _, policy := range policies {
+		t.Policies = append(t.Policies, PolicyIdReference{ID: policy.ID, Version: policy.Version})
+	}
+}
+
+// addEvaluation records the outcome of a single evaluator call
+func (t *Trace) addEvaluation(policies []Policy, result *EvaluationResult, err error) {
+	if t == nil {
+		return
+	}
+
+	evaluation := EvaluationTrace{
+		PolicyIdReferences: make([]PolicyIdReference, 0, len(policies)),
+		Decision:           Indeterminate,
+	}
+
+	for _, policy := range policies {
+		evaluation.PolicyIdReferences = append(evaluation.PolicyIdReferences, PolicyIdReference{ID: policy.ID, Version: policy.Version})
+	}
+
+	if err != nil {
+		evaluation.Error = err.Error()
+	} else {
+		evaluation.Decision = result.Decision
+		evaluation.Rules = result.Trace
+	}
+
+	t.Evaluations = append(t.Evaluations, evaluation)
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/CameronXie/access-control-explorer/abac/internal/lru"
+	"github.com/CameronXie/access-control-explorer/abac/internal/sharedcall"
+)
+
+const (
+	defaultTTL          = time.Minute
+	defaultNotFoundTTL  = 10 * time.Second
+	defaultMaxEntries   = 1000
+	defaultFetchTimeout = 30 * time.Second
+)
+
+// cachedInfo is a cached info response, or not found error
+type cachedInfo struct {
+	info map[string]any
+	err  error
+}
+
+// infoProvider decorates an InfoProvider with an info cache
+type infoProvider struct {
+	next        infoprovider.InfoProvider
+	ttl         time.Duration
+	ttls        map[string]time.Duration
+	notFoundTTL time.Duration
+	maxEntries  int
+	now         func() time.Time
+	fetch       *sharedcall.Group
+	cache       *lru.Cache
+}
+
+// Option defines configuration options for the caching InfoProvider
+type Option func(*infoProvider)
+
+// New creates an InfoProvider that caches the info retrieved from next, keyed on the info type and the JSON encoding
+// of the request params and context, so params of different types with the same content share an entry.
+// Not found errors, matching infoprovider.ErrNotFound, are cached as well, and simultaneous lookups of the same info
+// share a single call to next, which keeps running for up to 30 seconds when the caller that started it gives up.
+// Other errors are not cached, and neither are requests whose params or context cannot be encoded as JSON, such as
+// the *http.Request params of the environment provider, which are passed to next on every call.
+func New(next infoprovider.InfoProvider, options ...Option) infoprovider.InfoProvider {
+	p := &infoProvider{
+		next:        next,
+		ttl:         defaultTTL,
+		ttls:        make(map[string]time.Duration),
+		notFoundTTL: defaultNotFoundTTL,
+		maxEntries:  defaultMaxEntries,
+		now:         time.Now,
+		fetch:       sharedcall.New(defaultFetchTimeout),
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	p.cache = lru.New(p.maxEntries, lru.WithClock(func() time.Time { return p.now() }))
+	return p
+}
+
+// WithTTL sets how long info is cached, one minute by default
+func WithTTL(ttl time.Duration) Option {
+	return func(p *infoProvider) {
+		p.ttl = ttl
+	}
+}
+
+// WithInfoTypeTTL sets how long info of the given type is cached, overriding the default TTL. A TTL of zero or less
+// disables caching for the type.
+func WithInfoTypeTTL(infoType string, ttl time.Duration) Option {
+	return func(p *infoProvider) {
+		p.ttls[infoType] = ttl
+	}
+}
+
+// WithNotFoundTTL sets how long not found errors are cached, ten seconds by default. A TTL of zero or less disables
+// negative caching.
+func WithNotFoundTTL(ttl time.Duration) Option {
+	return func(p *infoProvider) {
+		p.notFoundTTL = ttl
+	}
+}
+
+// WithMaxEntries bounds the number of cached entries, evicting the least recently used one when full, 1000 by default.
+// A bound of zero or less disables caching.
+func WithMaxEntries(maxEntries int) Option {
+	return func(p *infoProvider) {
+		p.maxEntries = maxEntries
+	}
+}
+
+// GetInfo returns the cached info for the request where available, and fetches it from the wrapped provider otherwise.
+// Each caller receives its own deep copy of the info map.
+func (p *infoProvider) GetInfo(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	key, err := cacheKey(req)
+	if err != nil {
+		return p.next.GetInfo(ctx, req)
+	}
+
+	value, ok := p.cache.Get(key)
+	entry, _ := value.(cachedInfo)
+	if !ok {
+		entry, err = p.load(ctx, key, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+
+	return &infoprovider.GetInfoResponse{Info: cloneInfo(entry.info)}, nil
+}
+
+// load fetches the info from the wrapped provider and caches it, sharing the fetch with concurrent callers
+func (p *infoProvider) load(ctx context.Context, key string, req *infoprovider.GetInfoRequest) (cachedInfo, error) {
+	value, err := p.fetch.Do(ctx, key, func(ctx context.Context) (any, error) {
+		resp, err := p.next.GetInfo(ctx, req)
+		if err != nil {
+			if !errors.Is(err, infoprovider.ErrNotFound) {
+				return nil, err
+			}
+
+			entry := cachedInfo{err: err}
+			p.cache.AddWithTTL(key, entry, p.notFoundTTL)
+			return entry, nil
+		}
+
+		entry := cachedInfo{info: resp.Info}
+		p.cache.AddWithTTL(key, entry, p.infoTypeTTL(req.InfoType))
+		return entry, nil
+	})
+	if err != nil {
+		return cachedInfo{}, err
+	}
+
+	return value.(cachedInfo), nil
+}
+
+// infoTypeTTL returns how long info of the given type is cached
+func (p *infoProvider) infoTypeTTL(infoType string) time.Duration {
+	if ttl, ok := p.ttls[infoType]; ok {
+		return ttl
+	}
+
+	return p.ttl
+}
+
+// cacheKey identifies the request by info type and the JSON encoding of its params and context
+func cacheKey(req *infoprovider.GetInfoRequest) (string, error) {
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode params for info type %s: %w", req.InfoType, err)
+	}
+
+	reqContext, err := json.Marshal(req.Context)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode context for info type %s: %w", req.InfoType, err)
+	}
+
+	return fmt.Sprintf("%s\n%s\n%s", req.InfoType, params, reqContext), nil
+}
+
+// cloneInfo returns a deep copy of the info, copying nested maps and slices of any type
+func cloneInfo(info map[string]any) map[string]any {
+	clone, _ := cloneValue(reflect.ValueOf(info)).Interface().(map[string]any)
+	return clone
+}
+
+// cloneValue returns a deep copy of maps and slices, including those held in interfaces, and other values as they are
+func cloneValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.New(value.Type()).Elem()
+		clone.Set(cloneValue(value.Elem()))
+		return clone
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.MakeMapWithSize(value.Type(), value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			clone.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+
+		return clone
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+
+		clone := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for idx := range value.Len() {
+			clone.Index(idx).Set(cloneValue(value.Index(idx)))
+		}
+
+		return clone
+	default:
+		return value
+	}
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockInfoProvider is a mock implementation of InfoProvider
+type mockInfoProvider struct {
+	mock.Mock
+}
+
+func (m *mockInfoProvider) GetInfo(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*infoprovider.GetInfoResponse), args.Error(1)
+}
+
+func TestInfoProvider_GetInfo(t *testing.T) {
+	user := &infoprovider.GetInfoRequest{InfoType: "user", Params: "user123"}
+	roles := &infoprovider.GetInfoRequest{InfoType: "rbac", Params: []string{"admin", "viewer"}}
+	decodedRoles := &infoprovider.GetInfoRequest{InfoType: "rbac", Params: []any{"admin", "viewer"}}
+	notFound := fmt.Errorf("user user456: %w", infoprovider.ErrNotFound)
+	unencodable := &infoprovider.GetInfoRequest{InfoType: "environment", Params: make(chan struct{})}
+
+	testCases := map[string]struct {
+		setupMock      func(*mockInfoProvider)
+		options        []Option
+		requests       []*infoprovider.GetInfoRequest
+		expectedResult *infoprovider.GetInfoResponse
+		expectedError  string
+	}{
+		"should fetch info once and serve it from the cache": {
+			setupMock: func(m *mockInfoProvider) {
+				m.On("GetInfo", mock.Anything, user).Return(&infoprovider.GetInfoResponse{Info: map[string]any{"name": "Alice"}}, nil).Once()
+			},
+			requests:       []*infoprovider.GetInfoRequest{user, user},
+			expectedResult: &infoprovider.GetInfoResponse{Info: map[string]any{"name": "Alice"}},
+		},
+		"should share an entry between params with the same content": {
+			setupMock: func(m *mockInfoProvider) {
+				m.On("GetInfo", mock.Anything, roles).Return(&infoprovider.GetInfoResponse{Info: map[string]any{"descendants": 2}}, nil).Once()
+			},
+			requests:       []*infoprovider.GetInfoRequest{roles, decodedRoles},
+			expectedResult: &infoprovider.GetInfoResponse{Info: map[string]any{"descendants": 2}},
+		},
+		"should cache not found errors": {
+			setupMock: func(m *mockInfoProvider) {
+				m.On("GetInfo", mock.Anything, user).Return(nil, notFound).Once()
+			},
+			requests:      []*infoprovider.GetInfoRequest{user, user},
+			expectedError: "user user456: info not found",
+		},
+		"should not cache not found errors when negative caching is disabled": {
+			setupMock: func(m *mockInfoProvider) {
+				m.On("GetInfo", mock.Anything, user).Return(nil, notFound).Twice()
+			},
+			options:       []Option{WithNotFoundTTL(0)},
+			requests:      []*infoprovider.GetInfoRequest{user, user},
+			expectedError: "user user456: info not found",
+		},
+		"should not cache other errors": {
+			setupMock: func(m *mockInfoProvider) {
+				m.On("GetInfo", mock.Anything, user).Return(nil, errors.New("connection refused")).Twice()
+			},
+			requests:      []*infoprovider.GetInfoRequest{user, user},
+			expectedError: "connection refused",
+		},
+		"should not cache info types with caching disabled": {
+			setupMock: func(m *mockInfoProvider) {
+				m.On("GetInfo", mock.Anything, user).Return(&infoprovider.GetInfoResponse{Info: map[string]any{"name": "Alice"}}, nil).Twice()
+			},
+			options:        []Option{WithInfoTypeTTL("user", 0)},
+			requests:       []*infoprovider.GetInfoRequest{user, user},
+			expectedResult: &infoprovider.GetInfoResponse{Info: map[string]any{"name": "Alice"}},
+		},
+		"should not cache requests whose params cannot be encoded": {
+			setupMock: func(m *mockInfoProvider) {
+				m.On("GetInfo", mock.Anything, unencodable).Return(&infoprovider.GetInfoResponse{Info: map[string]any{"time": "now"}}, nil).Twice()
+			},
+			requests:       []*infoprovider.GetInfoRequest{unencodable, unencodable},
+			expectedResult: &infoprovider.GetInfoResponse{Info: map[string]any{"time": "now"}},
+		},
+		"should return error when request is nil": {
+			setupMock:     func(*mockInfoProvider) {},
+			requests:      []*infoprovider.GetInfoRequest{nil},
+			expectedError: "request cannot be nil",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			next := new(mockInfoProvider)
+			tc.setupMock(next)
+
+			provider := New(next, tc.options...)
+
+			var result *infoprovider.GetInfoResponse
+			var err error
+			for _, req := range tc.requests {
+				result, err = provider.GetInfo(context.Background(), req)
+			}
+
+			next.AssertExpectations(t)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestInfoProvider_CopiesCachedInfo(t *testing.T) {
+	req := &infoprovider.GetInfoRequest{InfoType: "user", Params: "user123"}
+
+	next := new(mockInfoProvider)
+	next.On("GetInfo", mock.Anything, req).Return(&infoprovider.GetInfoResponse{Info: map[string]any{
+		"name":    "Alice",
+		"roles":   []string{"admin"},
+		"manager": map[string]any{"id": "user456", "tags": []any{"lead"}},
+	}}, nil).Once()
+
+	provider := New(next)
+
+	first, err := provider.GetInfo(context.Background(), req)
+	require.NoError(t, err)
+	first.Info["name"] = "Mallory"
+	first.Info["roles"].([]string)[0] = "viewer"
+	first.Info["manager"].(map[string]any)["id"] = "user789"
+	first.Info["manager"].(map[string]any)["tags"].([]any)[0] = "member"
+
+	second, err := provider.GetInfo(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":    "Alice",
+		"roles":   []string{"admin"},
+		"manager": map[string]any{"id": "user456", "tags": []any{"lead"}},
+	}, second.Info)
+}
+
+func TestInfoProvider_InfoTypeTTL(t *testing.T) {
+	user := &infoprovider.GetInfoRequest{InfoType: "user", Params: "user123"}
+	roles := &infoprovider.GetInfoRequest{InfoType: "rbac", Params: []string{"admin"}}
+
+	next := new(mockInfoProvider)
+	next.On("GetInfo", mock.Anything, user).Return(&infoprovider.GetInfoResponse{Info: map[string]any{}}, nil).Times(3)
+	next.On("GetInfo", mock.Anything, roles).Return(&infoprovider.GetInfoResponse{Info: map[string]any{}}, nil).Once()
+
+	now := time.Now()
+	provider := New(next, WithTTL(time.Minute), WithInfoTypeTTL("rbac", time.Hour)).(*infoProvider)
+	provider.now = func() time.Time { return now }
+
+	// User info expires every minute, while role info is cached for an hour
+	for _, elapsed := range []time.Duration{0, 30 * time.Second, 30 * time.Second, time.Minute} {
+		now = now.Add(elapsed)
+
+		for _, req := range []*infoprovider.GetInfoRequest{user, roles} {
+			_, err := provider.GetInfo(context.Background(), req)
+			require.NoError(t, err)
+		}
+	}
+
+	next.AssertExpectations(t)
+}
+
+// infoProviderFunc is an InfoProvider calling the function, for lookups whose result depends on the context
+type infoProviderFunc func(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error)
+
+func (f infoProviderFunc) GetInfo(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+	return f(ctx, req)
+}
+
+func TestInfoProvider_SharedFetchOutlivesCancelledCaller(t *testing.T) {
+	req := &infoprovider.GetInfoRequest{InfoType: "rbac", Params: []string{"admin"}}
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var calls atomic.Int32
+	next := infoProviderFunc(func(ctx context.Context, _ *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-release:
+			return &infoprovider.GetInfoResponse{Info: map[string]any{"descendants": []string{"admin"}}}, nil
+		}
+	})
+
+	provider := New(next)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := provider.GetInfo(firstCtx, req)
+		firstErr <- err
+	}()
+	<-started
+
+	secondResult := make(chan *infoprovider.GetInfoResponse, 1)
+	secondErr := make(chan error, 1)
+	go func() {
+		result, err := provider.GetInfo(context.Background(), req)
+		secondResult <- result
+		secondErr <- err
+	}()
+
+	// Give the second caller time to join the fetch in flight before the first one cancels
+	time.Sleep(20 * time.Millisecond)
+	cancelFirst()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	close(release)
+	require.NoError(t, <-secondErr)
+	assert.Equal(t, []string{"admin"}, (<-secondResult).Info["descendants"])
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,211 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+)
+
+// InfoType is the info type environment attributes are conventionally requested under
+const InfoType = "environment"
+
+// Environment attribute names
+const (
+	AttributeCurrentTime      = "current_time"        // RFC 3339 time in the configured location
+	AttributeCurrentDate      = "current_date"        // Date as YYYY-MM-DD
+	AttributeCurrentDayOfWeek = "current_day_of_week" // Day of week, such as Monday
+	AttributeCurrentHour      = "current_hour"        // Hour of day, from 0 to 23
+	AttributeClientIP         = "client_ip"           // Client IP address, omitted when it cannot be determined
+	AttributeNetworkZones     = "network_zones"       // Sorted names of the network zones containing the client IP
+	AttributeTLS              = "tls"                 // Whether the request was received over TLS
+	AttributeRequestMethod    = "request_method"      // HTTP method
+	AttributeRequestHost      = "request_host"        // Host the request was sent to
+	AttributeRequestPath      = "request_path"        // URL path
+	AttributeRequestHeaders   = "request_headers"     // Allowed request headers, by canonical name
+)
+
+const forwardedForHeader = "X-Forwarded-For"
+
+// infoProvider provides attributes of the environment of an HTTP request: the current time, the client network and
+// request metadata
+type infoProvider struct {
+	now            func() time.Time
+	location       *time.Location
+	precision      time.Duration
+	networkZones   map[string][]netip.Prefix
+	trustedProxies []netip.Prefix
+	headers        []string
+}
+
+// Option defines configuration options for the environment InfoProvider
+type Option func(*infoProvider)
+
+// New creates an InfoProvider returning the environment attributes of the *http.Request given as params.
+// Request headers are only included when allowed with WithHeaders, so credentials are not exposed to policies.
+func New(options ...Option) infoprovider.InfoProvider {
+	p := &infoProvider{
+		now:          time.Now,
+		location:     time.UTC,
+		networkZones: make(map[string][]netip.Prefix),
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// WithClock sets the clock the current time is read from, time.Now by default
+func WithClock(now func() time.Time) Option {
+	return func(p *infoProvider) {
+		p.now = now
+	}
+}
+
+// WithLocation sets the location time attributes are reported in, UTC by default
+func WithLocation(location *time.Location) Option {
+	return func(p *infoProvider) {
+		p.location = location
+	}
+}
+
+// WithTimePrecision truncates the current time to a multiple of the precision, such as a minute, so decisions cached on
+// the environment are reused within it
+func WithTimePrecision(precision time.Duration) Option {
+	return func(p *infoProvider) {
+		p.precision = precision
+	}
+}
+
+// WithNetworkZones names network zones by the prefixes they cover, such as "office" for 10.0.0.0/8
+func WithNetworkZones(zones map[string][]netip.Prefix) Option {
+	return func(p *infoProvider) {
+		for name, prefixes := range zones {
+			p.networkZones[name] = append(p.networkZones[name], prefixes...)
+		}
+	}
+}
+
+// WithTrustedProxies sets the proxies whose X-Forwarded-For header is trusted to carry the client IP
+func WithTrustedProxies(prefixes ...netip.Prefix) Option {
+	return func(p *infoProvider) {
+		p.trustedProxies = append(p.trustedProxies, prefixes...)
+	}
+}
+
+// WithHeaders allows request headers to be included in the environment attributes
+func WithHeaders(names ...string) Option {
+	return func(p *infoProvider) {
+		for _, name := range names {
+			p.headers = append(p.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// GetInfo returns the environment attributes of the HTTP request given as params
+func (p *infoProvider) GetInfo(_ context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	r, ok := req.Params.(*http.Request)
+	if !ok || r == nil {
+		return nil, fmt.Errorf("environment params must be an *http.Request, got %T", req.Params)
+	}
+
+	now := p.now().Truncate(p.precision).In(p.location)
+	info := map[string]any{
+		AttributeCurrentTime:      now.Format(time.RFC3339),
+		AttributeCurrentDate:      now.Format(time.DateOnly),
+		AttributeCurrentDayOfWeek: now.Weekday().String(),
+		AttributeCurrentHour:      now.Hour(),
+		AttributeTLS:              r.TLS != nil,
+		AttributeRequestMethod:    r.Method,
+		AttributeRequestHost:      r.Host,
+		AttributeRequestPath:      r.URL.Path,
+		AttributeNetworkZones:     make([]string, 0),
+	}
+
+	if clientIP, ok := p.clientIP(r); ok {
+		info[AttributeClientIP] = clientIP.String()
+		info[AttributeNetworkZones] = p.zonesOf(clientIP)
+	}
+
+	headers := make(map[string]string, len(p.headers))
+	for _, name := range p.headers {
+		if values := r.Header.Values(name); len(values) > 0 {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+	info[AttributeRequestHeaders] = headers
+
+	return &infoprovider.GetInfoResponse{Info: info}, nil
+}
+
+// clientIP returns the IP of the client, following the X-Forwarded-For header from right to left while the request
+// came through trusted proxies
+func (p *infoProvider) clientIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	addr = addr.Unmap()
+	if !p.trusted(addr) {
+		return addr, true
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for idx := len(forwarded) - 1; idx >= 0; idx-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[idx]))
+		if err != nil {
+			// The rest of the chain cannot be trusted past a malformed hop
+			return addr, true
+		}
+
+		addr = hop.Unmap()
+		if !p.trusted(addr) {
+			return addr, true
+		}
+	}
+
+	return addr, true
+}
+
+// trusted reports whether the address belongs to a trusted proxy
+func (p *infoProvider) trusted(addr netip.Addr) bool {
+	return containsAddr(p.trustedProxies, addr)
+}
+
+// zonesOf returns the sorted names of the network zones containing the address
+func (p *infoProvider) zonesOf(addr netip.Addr) []string {
+	zones := make([]string, 0)
+	for name, prefixes := range p.networkZones {
+		if containsAddr(prefixes, addr) {
+			zones = append(zones, name)
+		}
+	}
+
+	slices.Sort(zones)
+	return zones
+}
+
+// containsAddr reports whether any of the prefixes contains the address
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	return slices.ContainsFunc(prefixes, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
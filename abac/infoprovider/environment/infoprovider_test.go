@@ -0,0 +1,159 @@
+package environment
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfoProvider_GetInfo(t *testing.T) {
+	now := time.Date(2025, 3, 14, 23, 30, 15, 0, time.UTC)
+	sydney := time.FixedZone("AEDT", int((11 * time.Hour).Seconds()))
+
+	zones := WithNetworkZones(map[string][]netip.Prefix{
+		"office":   {netip.MustParsePrefix("10.0.0.0/8")},
+		"internal": {netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.0.0/16")},
+	})
+
+	testCases := map[string]struct {
+		options        []Option
+		setupRequest   func(r *http.Request)
+		expectedResult map[string]any
+	}{
+		"should return time and request attributes": {
+			setupRequest: func(r *http.Request) {
+				r.RemoteAddr = "203.0.113.7:51234"
+				r.Header.Set("User-Agent", "curl")
+			},
+			expectedResult: map[string]any{
+				AttributeCurrentTime:      "2025-03-14T23:30:15Z",
+				AttributeCurrentDate:      "2025-03-14",
+				AttributeCurrentDayOfWeek: "Friday",
+				AttributeCurrentHour:      23,
+				AttributeClientIP:         "203.0.113.7",
+				AttributeNetworkZones:     []string{},
+				AttributeTLS:              false,
+				AttributeRequestMethod:    http.MethodGet,
+				AttributeRequestHost:      "api.example.com",
+				AttributeRequestPath:      "/orders/1",
+				AttributeRequestHeaders:   map[string]string{},
+			},
+		},
+		"should report time in the configured location and precision": {
+			options: []Option{WithLocation(sydney), WithTimePrecision(time.Minute)},
+			expectedResult: map[string]any{
+				AttributeCurrentTime:      "2025-03-15T10:30:00+11:00",
+				AttributeCurrentDate:      "2025-03-15",
+				AttributeCurrentDayOfWeek: "Saturday",
+				AttributeCurrentHour:      10,
+			},
+		},
+		"should report network zones, TLS and allowed headers": {
+			options: []Option{zones, WithHeaders("user-agent", "X-Tenant")},
+			setupRequest: func(r *http.Request) {
+				r.RemoteAddr = "10.1.2.3:443"
+				r.TLS = &tls.ConnectionState{}
+				r.Header.Set("Authorization", "Bearer token")
+				r.Header.Set("User-Agent", "curl")
+				r.Header.Add("X-Tenant", "a")
+				r.Header.Add("X-Tenant", "b")
+			},
+			expectedResult: map[string]any{
+				AttributeClientIP:       "10.1.2.3",
+				AttributeNetworkZones:   []string{"internal", "office"},
+				AttributeTLS:            true,
+				AttributeRequestHeaders: map[string]string{"User-Agent": "curl", "X-Tenant": "a, b"},
+			},
+		},
+		"should follow forwarded addresses through trusted proxies": {
+			options: []Option{zones, WithTrustedProxies(netip.MustParsePrefix("192.168.0.0/16"))},
+			setupRequest: func(r *http.Request) {
+				r.RemoteAddr = "192.168.1.1:8080"
+				r.Header.Set("X-Forwarded-For", "198.51.100.1, 10.1.2.3, 192.168.1.2")
+			},
+			expectedResult: map[string]any{
+				AttributeClientIP:     "10.1.2.3",
+				AttributeNetworkZones: []string{"internal", "office"},
+			},
+		},
+		"should ignore forwarded addresses from untrusted clients": {
+			options: []Option{WithTrustedProxies(netip.MustParsePrefix("192.168.0.0/16"))},
+			setupRequest: func(r *http.Request) {
+				r.RemoteAddr = "203.0.113.7:51234"
+				r.Header.Set("X-Forwarded-For", "10.1.2.3")
+			},
+			expectedResult: map[string]any{
+				AttributeClientIP: "203.0.113.7",
+			},
+		},
+		"should stop at a malformed forwarded address": {
+			options: []Option{WithTrustedProxies(netip.MustParsePrefix("192.168.0.0/16"))},
+			setupRequest: func(r *http.Request) {
+				r.RemoteAddr = "192.168.1.1:8080"
+				r.Header.Set("X-Forwarded-For", "10.1.2.3, unknown")
+			},
+			expectedResult: map[string]any{
+				AttributeClientIP: "192.168.1.1",
+			},
+		},
+		"should omit the client IP when it cannot be determined": {
+			setupRequest: func(r *http.Request) {
+				r.RemoteAddr = "pipe"
+			},
+			expectedResult: map[string]any{
+				AttributeClientIP:     nil,
+				AttributeNetworkZones: []string{},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "https://api.example.com/orders/1", http.NoBody)
+			r.TLS = nil
+			if tc.setupRequest != nil {
+				tc.setupRequest(r)
+			}
+
+			provider := New(append([]Option{WithClock(func() time.Time { return now })}, tc.options...)...)
+			result, err := provider.GetInfo(context.Background(), &infoprovider.GetInfoRequest{InfoType: InfoType, Params: r})
+
+			require.NoError(t, err)
+			for key, expected := range tc.expectedResult {
+				assert.Equal(t, expected, result.Info[key], key)
+			}
+		})
+	}
+}
+
+func TestInfoProvider_GetInfoErrors(t *testing.T) {
+	testCases := map[string]struct {
+		req           *infoprovider.GetInfoRequest
+		expectedError string
+	}{
+		"should return error when request is nil": {
+			expectedError: "request cannot be nil",
+		},
+		"should return error when params are not an HTTP request": {
+			req:           &infoprovider.GetInfoRequest{InfoType: InfoType, Params: "request"},
+			expectedError: "environment params must be an *http.Request, got string",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := New().GetInfo(context.Background(), tc.req)
+
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
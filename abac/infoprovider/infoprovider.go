@@ -1,6 +1,12 @@
 package infoprovider
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned, possibly wrapped, by InfoProviders when no info exists for a request
+var ErrNotFound = errors.New("info not found")
 
 type GetInfoRequest struct {
 	InfoType string
@@ -0,0 +1,91 @@
+package remote
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped, when calls to an endpoint are suspended after repeated failures
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // Calls are made
+	breakerOpen                         // Calls are rejected until the open duration elapses
+	breakerHalfOpen                     // A single trial call is in flight
+)
+
+// outcome classifies a call for the circuit breaker
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota // The endpoint responded, whatever the response
+	outcomeFailure                // The endpoint could not be reached or failed, after retries
+	outcomeIgnored                // The caller gave up or the request could not be built, saying nothing about the endpoint
+)
+
+// circuitBreaker suspends calls to an endpoint after consecutive failures, then lets a single trial call through once
+// the open duration has elapsed, closing again if it succeeds
+type circuitBreaker struct {
+	threshold    int
+	openDuration time.Duration
+	now          func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// allow reports whether a call may be made
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.openDuration {
+			return false
+		}
+
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of an allowed call
+func (b *circuitBreaker) record(result outcome) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch result {
+	case outcomeSuccess:
+		b.state = breakerClosed
+		b.failures = 0
+	case outcomeFailure:
+		b.failures++
+		if b.state == breakerHalfOpen || b.failures >= b.threshold {
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	case outcomeIgnored:
+		// Give the next call the trial, keeping the time the breaker opened
+		if b.state == breakerHalfOpen {
+			b.state = breakerOpen
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+)
+
+const (
+	defaultTimeout          = 5 * time.Second
+	defaultRetries          = 2
+	defaultBackoff          = 100 * time.Millisecond
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = 30 * time.Second
+
+	maxResponseBytes = 1 << 20
+)
+
+// AuthFunc returns the Authorization header value of a call, such as a bearer token, and is called for every attempt
+// so rotated credentials are picked up
+type AuthFunc func(ctx context.Context) (string, error)
+
+// BearerToken returns an AuthFunc authorising calls with a static bearer token
+func BearerToken(token string) AuthFunc {
+	return func(context.Context) (string, error) {
+		return "Bearer " + token, nil
+	}
+}
+
+// Endpoint describes the HTTP endpoint serving the info of an info type.
+// URL and Body are text/template templates rendered with the InfoType, Params and Context of the info request, with
+// the path, query and json functions escaping a value for a URL path segment, a URL query or a JSON document, such as
+// "https://crm.internal/customers/{{path .Params}}/standing".
+type Endpoint struct {
+	URL     string
+	Method  string // GET by default
+	Body    string // Sent as application/json when set
+	Headers map[string]string
+	Auth    AuthFunc
+
+	// Attributes maps info attribute names to dot-separated paths in the JSON response, such as "data.tier" or
+	// "accounts.0.standing"; paths missing from a response are left out. The whole response, which must then be a
+	// JSON object, is the info when no attributes are mapped.
+	Attributes map[string]string
+
+	// Timeout bounds each attempt, overriding the provider timeout
+	Timeout time.Duration
+}
+
+// endpoint is an Endpoint with its parsed templates and circuit breaker
+type endpoint struct {
+	Endpoint
+	url     *template.Template
+	body    *template.Template
+	breaker *circuitBreaker
+}
+
+// templateData is the data endpoint templates are rendered with
+type templateData struct {
+	InfoType string
+	Params   any
+	Context  map[string]string
+}
+
+// infoProvider implements the InfoProvider interface by calling HTTP endpoints returning JSON
+type infoProvider struct {
+	endpoints        map[string]*endpoint
+	client           *http.Client
+	timeout          time.Duration
+	retries          int
+	backoff          time.Duration
+	failureThreshold int
+	openDuration     time.Duration
+	now              func() time.Time
+}
+
+// Option defines configuration options for the remote InfoProvider
+type Option func(*infoProvider)
+
+// New creates an InfoProvider fetching the info of each info type from its endpoint.
+// Attempts failing to reach the endpoint, timing out or answered with status 429 or 5xx are retried with exponential
+// backoff, and an endpoint failing repeatedly has its calls rejected with ErrCircuitOpen for a while. A 404 Not Found
+// response is returned as infoprovider.ErrNotFound.
+func New(endpoints map[string]Endpoint, options ...Option) (infoprovider.InfoProvider, error) {
+	p := &infoProvider{
+		endpoints:        make(map[string]*endpoint, len(endpoints)),
+		client:           http.DefaultClient,
+		timeout:          defaultTimeout,
+		retries:          defaultRetries,
+		backoff:          defaultBackoff,
+		failureThreshold: defaultFailureThreshold,
+		openDuration:     defaultOpenDuration,
+		now:              time.Now,
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	for infoType, e := range endpoints {
+		parsed, err := p.newEndpoint(infoType, e)
+		if err != nil {
+			return nil, err
+		}
+
+		p.endpoints[infoType] = parsed
+	}
+
+	return p, nil
+}
+
+// WithHTTPClient sets the client used to call the endpoints, http.DefaultClient by default
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *infoProvider) {
+		p.client = client
+	}
+}
+
+// WithTimeout bounds each attempt to call an endpoint, five seconds by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *infoProvider) {
+		p.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times a failed call is retried, two by default, waiting backoff before the first retry
+// and doubling the wait before each next one, 100ms by default
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(p *infoProvider) {
+		p.retries = max(retries, 0)
+		p.backoff = backoff
+	}
+}
+
+// WithCircuitBreaker rejects calls to an endpoint for the open duration once that many consecutive calls failed, then
+// lets a trial call through. Five failures and 30 seconds by default; a threshold of zero or less disables it.
+func WithCircuitBreaker(failureThreshold int, openDuration time.Duration) Option {
+	return func(p *infoProvider) {
+		p.failureThreshold = failureThreshold
+		p.openDuration = openDuration
+	}
+}
+
+// newEndpoint parses the templates of the endpoint of the info type
+func (p *infoProvider) newEndpoint(infoType string, e Endpoint) (*endpoint, error) {
+	if e.Method == "" {
+		e.Method = http.MethodGet
+	}
+
+	if e.Timeout <= 0 {
+		e.Timeout = p.timeout
+	}
+
+	urlTemplate, err := template.New("url").Funcs(templateFuncs).Parse(e.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url template for info type %s: %w", infoType, err)
+	}
+
+	var bodyTemplate *template.Template
+	if e.Body != "" {
+		bodyTemplate, err = template.New("body").Funcs(templateFuncs).Parse(e.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse body template for info type %s: %w", infoType, err)
+		}
+	}
+
+	return &endpoint{
+		Endpoint: e,
+		url:      urlTemplate,
+		body:     bodyTemplate,
+		breaker:  &circuitBreaker{threshold: p.failureThreshold, openDuration: p.openDuration, now: p.now},
+	}, nil
+}
+
+// templateFuncs escape values rendered in endpoint templates
+var templateFuncs = template.FuncMap{
+	"path": func(value any) string {
+		return url.PathEscape(fmt.Sprint(value))
+	},
+	"query": func(value any) string {
+		return url.QueryEscape(fmt.Sprint(value))
+	},
+	"json": func(value any) (string, error) {
+		content, err := json.Marshal(value)
+		return string(content), err
+	},
+}
+
+// GetInfo calls the endpoint of the requested info type and extracts the info from its JSON response
+func (p *infoProvider) GetInfo(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	e, ok := p.endpoints[req.InfoType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported info type %s", req.InfoType)
+	}
+
+	if !e.breaker.allow() {
+		return nil, fmt.Errorf("failed to get info for info type %s: %w", req.InfoType, ErrCircuitOpen)
+	}
+
+	doc, result, err := p.fetch(ctx, e, req)
+	if err != nil && ctx.Err() != nil {
+		result = outcomeIgnored
+	}
+
+	e.breaker.record(result)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for info type %s: %w", req.InfoType, err)
+	}
+
+	info, err := e.extract(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get info for info type %s: %w", req.InfoType, err)
+	}
+
+	return &infoprovider.GetInfoResponse{Info: info}, nil
+}
+
+// fetch calls the endpoint, retrying failures of the endpoint with exponential backoff. It reports the outcome of the
+// last attempt for the circuit breaker.
+func (p *infoProvider) fetch(ctx context.Context, e *endpoint, req *infoprovider.GetInfoRequest) (any, outcome, error) {
+	for attempt := 0; ; attempt++ {
+		doc, result, err := p.call(ctx, e, req)
+		if result != outcomeFailure || attempt >= p.retries {
+			return doc, result, err
+		}
+
+		timer := time.NewTimer(p.backoff << attempt)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, outcomeFailure, err
+		case <-timer.C:
+		}
+	}
+}
+
+// call makes a single attempt to call the endpoint, reporting whether the endpoint responded, failed, or was never
+// called because the request could not be built
+func (p *infoProvider) call(ctx context.Context, e *endpoint, req *infoprovider.GetInfoRequest) (any, outcome, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	httpReq, err := e.newRequest(ctx, req)
+	if err != nil {
+		return nil, outcomeIgnored, err
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, outcomeFailure, fmt.Errorf("failed to call %s: %w", httpReq.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, outcomeSuccess, fmt.Errorf("%s returned status %d: %w", httpReq.URL.Redacted(), resp.StatusCode, infoprovider.ErrNotFound)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return nil, outcomeFailure, fmt.Errorf("%s returned status %d", httpReq.URL.Redacted(), resp.StatusCode)
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return nil, outcomeSuccess, fmt.Errorf("%s returned status %d", httpReq.URL.Redacted(), resp.StatusCode)
+	}
+
+	var doc any
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&doc); err != nil {
+		// A response cut short by the attempt timeout is worth retrying
+		result := outcomeSuccess
+		if ctx.Err() != nil {
+			result = outcomeFailure
+		}
+
+		return nil, result, fmt.Errorf("failed to decode response from %s: %w", httpReq.URL.Redacted(), err)
+	}
+
+	return doc, outcomeSuccess, nil
+}
+
+// newRequest renders the templates of the endpoint into an HTTP request
+func (e *endpoint) newRequest(ctx context.Context, req *infoprovider.GetInfoRequest) (*http.Request, error) {
+	data := templateData{InfoType: req.InfoType, Params: req.Params, Context: req.Context}
+
+	var target strings.Builder
+	if err := e.url.Execute(&target, data); err != nil {
+		return nil, fmt.Errorf("failed to render url: %w", err)
+	}
+
+	var body io.Reader = http.NoBody
+	if e.body != nil {
+		var content bytes.Buffer
+		if err := e.body.Execute(&content, data); err != nil {
+			return nil, fmt.Errorf("failed to render body: %w", err)
+		}
+
+		body = &content
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, e.Method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+	if e.body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	for name, value := range e.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	if e.Auth != nil {
+		authorization, err := e.Auth(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to authorise request: %w", err)
+		}
+
+		httpReq.Header.Set("Authorization", authorization)
+	}
+
+	return httpReq, nil
+}
+
+// extract returns the info held by the JSON response
+func (e *endpoint) extract(doc any) (map[string]any, error) {
+	if len(e.Attributes) == 0 {
+		info, ok := doc.(map[string]any)
+		if !ok {
+			return nil, errors.New("response must be a JSON object when no attributes are mapped")
+		}
+
+		return maps.Clone(info), nil
+	}
+
+	info := make(map[string]any, len(e.Attributes))
+	for name, path := range e.Attributes {
+		if value, ok := lookup(doc, path); ok {
+			info[name] = value
+		}
+	}
+
+	return info, nil
+}
+
+// lookup returns the value at the dot-separated path in the JSON document, indexing arrays by number
+func lookup(doc any, path string) (any, bool) {
+	value := doc
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+
+			value = next
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+
+			value = v[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
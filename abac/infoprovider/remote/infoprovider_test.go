@@ -0,0 +1,431 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest is a request received by the test server
+type recordedRequest struct {
+	Method        string
+	Path          string
+	Query         string
+	Authorization string
+	Tenant        string
+	ContentType   string
+	Body          string
+}
+
+// testServer answers with the scripted responses in turn, repeating the last one, and records the requests it received
+type testServer struct {
+	mu        sync.Mutex
+	responses []testResponse
+	requests  []recordedRequest
+}
+
+type testResponse struct {
+	status int
+	body   string
+	delay  time.Duration
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, recordedRequest{
+		Method:        r.Method,
+		Path:          r.URL.EscapedPath(),
+		Query:         r.URL.RawQuery,
+		Authorization: r.Header.Get("Authorization"),
+		Tenant:        r.Header.Get("X-Tenant"),
+		ContentType:   r.Header.Get("Content-Type"),
+		Body:          string(body),
+	})
+	resp := s.responses[min(len(s.requests), len(s.responses))-1]
+	s.mu.Unlock()
+
+	if resp.delay > 0 {
+		select {
+		case <-time.After(resp.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.WriteHeader(resp.status)
+	_, _ = w.Write([]byte(resp.body))
+}
+
+func (s *testServer) received() []recordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]recordedRequest(nil), s.requests...)
+}
+
+func TestInfoProvider_GetInfo(t *testing.T) {
+	testCases := map[string]struct {
+		endpoint         Endpoint
+		responses        []testResponse
+		req              *infoprovider.GetInfoRequest
+		expectedResult   map[string]any
+		expectedRequests []recordedRequest
+		expectedError    string
+		expectedErrorIs  error
+	}{
+		"should return the response object as info": {
+			endpoint:       Endpoint{URL: "{{.Context.baseURL}}/customers/{{path .Params}}"},
+			responses:      []testResponse{{status: http.StatusOK, body: `{"tier":"gold","active":true}`}},
+			req:            &infoprovider.GetInfoRequest{InfoType: "customer", Params: "a/b c"},
+			expectedResult: map[string]any{"tier": "gold", "active": true},
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers/a%2Fb%20c"},
+			},
+		},
+		"should extract mapped attributes from the response": {
+			endpoint: Endpoint{
+				URL: "{{.Context.baseURL}}/accounts?owner={{query .Params}}",
+				Attributes: map[string]string{
+					"customer_tier":    "data.tier",
+					"account_standing": "data.accounts.1.standing",
+					"region":           "data.region",
+				},
+			},
+			responses: []testResponse{{
+				status: http.StatusOK,
+				body:   `{"data":{"tier":"gold","accounts":[{"standing":"closed"},{"standing":"good"}]}}`,
+			}},
+			req:            &infoprovider.GetInfoRequest{InfoType: "customer", Params: "a&b"},
+			expectedResult: map[string]any{"customer_tier": "gold", "account_standing": "good"},
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/accounts", Query: "owner=a%26b"},
+			},
+		},
+		"should send templated body and auth headers": {
+			endpoint: Endpoint{
+				URL:     "{{.Context.baseURL}}/lookup",
+				Method:  http.MethodPost,
+				Body:    `{"ids":{{json .Params}}}`,
+				Headers: map[string]string{"X-Tenant": "acme"},
+				Auth:    BearerToken("secret"),
+			},
+			responses:      []testResponse{{status: http.StatusOK, body: `{"standing":"good"}`}},
+			req:            &infoprovider.GetInfoRequest{InfoType: "customer", Params: []string{"c1", "c2"}},
+			expectedResult: map[string]any{"standing": "good"},
+			expectedRequests: []recordedRequest{{
+				Method:        http.MethodPost,
+				Path:          "/lookup",
+				Authorization: "Bearer secret",
+				Tenant:        "acme",
+				ContentType:   "application/json",
+				Body:          `{"ids":["c1","c2"]}`,
+			}},
+		},
+		"should retry server errors": {
+			endpoint: Endpoint{URL: "{{.Context.baseURL}}/customers/{{path .Params}}"},
+			responses: []testResponse{
+				{status: http.StatusServiceUnavailable},
+				{status: http.StatusTooManyRequests},
+				{status: http.StatusOK, body: `{"tier":"gold"}`},
+			},
+			req:            &infoprovider.GetInfoRequest{InfoType: "customer", Params: "c1"},
+			expectedResult: map[string]any{"tier": "gold"},
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers/c1"},
+				{Method: http.MethodGet, Path: "/customers/c1"},
+				{Method: http.MethodGet, Path: "/customers/c1"},
+			},
+		},
+		"should retry attempts timing out": {
+			endpoint: Endpoint{URL: "{{.Context.baseURL}}/customers/{{path .Params}}", Timeout: 20 * time.Millisecond},
+			responses: []testResponse{
+				{status: http.StatusOK, body: `{"tier":"silver"}`, delay: 200 * time.Millisecond},
+				{status: http.StatusOK, body: `{"tier":"gold"}`},
+			},
+			req:            &infoprovider.GetInfoRequest{InfoType: "customer", Params: "c1"},
+			expectedResult: map[string]any{"tier": "gold"},
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers/c1"},
+				{Method: http.MethodGet, Path: "/customers/c1"},
+			},
+		},
+		"should return error when retries are exhausted": {
+			endpoint:      Endpoint{URL: "{{.Context.baseURL}}/customers/{{path .Params}}"},
+			responses:     []testResponse{{status: http.StatusBadGateway}},
+			req:           &infoprovider.GetInfoRequest{InfoType: "customer", Params: "c1"},
+			expectedError: "returned status 502",
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers/c1"},
+				{Method: http.MethodGet, Path: "/customers/c1"},
+				{Method: http.MethodGet, Path: "/customers/c1"},
+			},
+		},
+		"should return not found without retrying": {
+			endpoint:        Endpoint{URL: "{{.Context.baseURL}}/customers/{{path .Params}}"},
+			responses:       []testResponse{{status: http.StatusNotFound}},
+			req:             &infoprovider.GetInfoRequest{InfoType: "customer", Params: "c1"},
+			expectedErrorIs: infoprovider.ErrNotFound,
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers/c1"},
+			},
+		},
+		"should not retry client errors": {
+			endpoint:      Endpoint{URL: "{{.Context.baseURL}}/customers/{{path .Params}}"},
+			responses:     []testResponse{{status: http.StatusForbidden}},
+			req:           &infoprovider.GetInfoRequest{InfoType: "customer", Params: "c1"},
+			expectedError: "returned status 403",
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers/c1"},
+			},
+		},
+		"should return error when response is not an object": {
+			endpoint:      Endpoint{URL: "{{.Context.baseURL}}/customers"},
+			responses:     []testResponse{{status: http.StatusOK, body: `["gold"]`}},
+			req:           &infoprovider.GetInfoRequest{InfoType: "customer"},
+			expectedError: "failed to get info for info type customer: response must be a JSON object when no attributes are mapped",
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers"},
+			},
+		},
+		"should return error when response is not JSON": {
+			endpoint:      Endpoint{URL: "{{.Context.baseURL}}/customers"},
+			responses:     []testResponse{{status: http.StatusOK, body: `gold`}},
+			req:           &infoprovider.GetInfoRequest{InfoType: "customer"},
+			expectedError: "failed to decode response from",
+			expectedRequests: []recordedRequest{
+				{Method: http.MethodGet, Path: "/customers"},
+			},
+		},
+		"should return error when info type is unsupported": {
+			endpoint:      Endpoint{URL: "{{.Context.baseURL}}/customers"},
+			req:           &infoprovider.GetInfoRequest{InfoType: "order"},
+			expectedError: "unsupported info type order",
+		},
+		"should return error when request is nil": {
+			endpoint:      Endpoint{URL: "{{.Context.baseURL}}/customers"},
+			expectedError: "request cannot be nil",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := &testServer{responses: tc.responses}
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			if tc.req != nil {
+				tc.req.Context = map[string]string{"baseURL": httpServer.URL}
+			}
+
+			provider, err := New(map[string]Endpoint{"customer": tc.endpoint}, WithRetries(2, time.Millisecond))
+			require.NoError(t, err)
+
+			result, err := provider.GetInfo(context.Background(), tc.req)
+
+			switch {
+			case tc.expectedErrorIs != nil:
+				assert.ErrorIs(t, err, tc.expectedErrorIs)
+				assert.Nil(t, result)
+			case tc.expectedError != "":
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, result.Info)
+			}
+
+			if tc.expectedRequests == nil {
+				assert.Empty(t, server.received())
+			} else {
+				assert.Equal(t, tc.expectedRequests, server.received())
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	testCases := map[string]struct {
+		endpoint      Endpoint
+		expectedError string
+	}{
+		"should create provider with valid templates": {
+			endpoint: Endpoint{URL: "https://crm.internal/customers/{{path .Params}}", Body: `{"id":{{json .Params}}}`},
+		},
+		"should return error when url template is invalid": {
+			endpoint:      Endpoint{URL: "https://crm.internal/customers/{{path .Params"},
+			expectedError: "failed to parse url template for info type customer",
+		},
+		"should return error when body template is invalid": {
+			endpoint:      Endpoint{URL: "https://crm.internal/customers", Body: `{"id":{{unknown .Params}}}`},
+			expectedError: "failed to parse body template for info type customer",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			provider, err := New(map[string]Endpoint{"customer": tc.endpoint})
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, provider)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, provider)
+		})
+	}
+}
+
+func TestInfoProvider_GetInfoWithCircuitBreaker(t *testing.T) {
+	server := &testServer{responses: []testResponse{
+		{status: http.StatusInternalServerError},
+		{status: http.StatusInternalServerError},
+		{status: http.StatusInternalServerError},
+		{status: http.StatusOK, body: `{"tier":"gold"}`},
+	}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	provider, err := New(
+		map[string]Endpoint{"customer": {URL: httpServer.URL + "/customers/{{path .Params}}"}},
+		WithRetries(0, 0),
+		WithCircuitBreaker(2, time.Minute),
+	)
+	require.NoError(t, err)
+
+	now := time.Now()
+	p := provider.(*infoProvider)
+	p.endpoints["customer"].breaker.now = func() time.Time { return now }
+
+	steps := []struct {
+		elapsed          time.Duration
+		expectedError    string
+		expectedRequests int
+	}{
+		{expectedError: "returned status 500", expectedRequests: 1},
+		{expectedError: "returned status 500", expectedRequests: 2},
+		{expectedError: ErrCircuitOpen.Error(), expectedRequests: 2},
+		{elapsed: time.Minute, expectedError: "returned status 500", expectedRequests: 3},
+		{expectedError: ErrCircuitOpen.Error(), expectedRequests: 3},
+		{elapsed: time.Minute, expectedRequests: 4},
+		{expectedRequests: 5},
+	}
+
+	for idx, step := range steps {
+		now = now.Add(step.elapsed)
+		result, err := provider.GetInfo(context.Background(), &infoprovider.GetInfoRequest{InfoType: "customer", Params: "c1"})
+
+		if step.expectedError != "" {
+			assert.ErrorContains(t, err, step.expectedError, "step %d", idx)
+			assert.Nil(t, result, "step %d", idx)
+		} else {
+			require.NoError(t, err, "step %d", idx)
+			assert.Equal(t, map[string]any{"tier": "gold"}, result.Info, "step %d", idx)
+		}
+
+		assert.Len(t, server.received(), step.expectedRequests, "step %d", idx)
+	}
+}
+
+func TestInfoProvider_GetInfoWithCircuitBreakerIgnoringLocalErrors(t *testing.T) {
+	server := &testServer{responses: []testResponse{
+		{status: http.StatusInternalServerError},
+		{status: http.StatusInternalServerError},
+	}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	authErr := errors.New("token expired")
+	var failAuth bool
+	provider, err := New(
+		map[string]Endpoint{"customer": {
+			URL: httpServer.URL + "/customers",
+			Auth: func(context.Context) (string, error) {
+				if failAuth {
+					return "", authErr
+				}
+
+				return "Bearer secret", nil
+			},
+		}},
+		WithRetries(0, 0),
+		WithCircuitBreaker(2, time.Minute),
+	)
+	require.NoError(t, err)
+
+	// A failure to authorise the request in between does not reset the consecutive endpoint failures
+	steps := []struct {
+		failAuth         bool
+		expectedError    string
+		expectedRequests int
+	}{
+		{expectedError: "returned status 500", expectedRequests: 1},
+		{failAuth: true, expectedError: authErr.Error(), expectedRequests: 1},
+		{expectedError: "returned status 500", expectedRequests: 2},
+		{expectedError: ErrCircuitOpen.Error(), expectedRequests: 2},
+	}
+
+	for idx, step := range steps {
+		failAuth = step.failAuth
+		result, err := provider.GetInfo(context.Background(), &infoprovider.GetInfoRequest{InfoType: "customer"})
+
+		assert.ErrorContains(t, err, step.expectedError, "step %d", idx)
+		assert.Nil(t, result, "step %d", idx)
+		assert.Len(t, server.received(), step.expectedRequests, "step %d", idx)
+	}
+}
+
+func TestInfoProvider_GetInfoWithAuthError(t *testing.T) {
+	provider, err := New(map[string]Endpoint{"customer": {
+		URL: "https://crm.internal/customers",
+		Auth: func(context.Context) (string, error) {
+			return "", errors.New("token expired")
+		},
+	}})
+	require.NoError(t, err)
+
+	result, err := provider.GetInfo(context.Background(), &infoprovider.GetInfoRequest{InfoType: "customer"})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "failed to get info for info type customer: failed to authorise request: token expired")
+}
+
+func TestLookup(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`{"a":{"b":[{"c":1},{"c":2}]},"d":null}`), &doc))
+
+	testCases := map[string]struct {
+		path          string
+		expectedValue any
+		expectedFound bool
+	}{
+		"should find nested value":                {path: "a.b.1.c", expectedValue: float64(2), expectedFound: true},
+		"should find null value":                  {path: "d", expectedFound: true},
+		"should not find missing key":             {path: "a.x", expectedFound: false},
+		"should not find out of range index":      {path: "a.b.2.c", expectedFound: false},
+		"should not find non numeric array index": {path: "a.b.first", expectedFound: false},
+		"should not find path through scalar":     {path: "a.b.0.c.d", expectedFound: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			value, found := lookup(doc, tc.path)
+
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
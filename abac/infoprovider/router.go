@@ -0,0 +1,32 @@
+package infoprovider
+
+import (
+	"context"
+	"fmt"
+)
+
+// router implements InfoProvider by routing requests to the InfoProvider registered for their info type
+type router struct {
+	providers map[string]InfoProvider
+}
+
+// NewRouter creates an InfoProvider directing each request to the provider registered under its InfoType
+func NewRouter(providers map[string]InfoProvider) InfoProvider {
+	return &router{
+		providers: providers,
+	}
+}
+
+// GetInfo routes the request to the provider registered for its info type
+func (r *router) GetInfo(ctx context.Context, req *GetInfoRequest) (*GetInfoResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	provider, ok := r.providers[req.InfoType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported info type %s", req.InfoType)
+	}
+
+	return provider.GetInfo(ctx, req)
+}
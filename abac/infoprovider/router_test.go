@@ -7,8 +7,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-
-	ip "github.com/CameronXie/access-control-explorer/abac/infoprovider"
 )
 
 type testContextKey string
@@ -18,13 +16,13 @@ type mockInfoProvider struct {
 	mock.Mock
 }
 
-func (m *mockInfoProvider) GetInfo(ctx context.Context, req *ip.GetInfoRequest) (*ip.GetInfoResponse, error) {
+func (m *mockInfoProvider) GetInfo(ctx context.Context, req *GetInfoRequest) (*GetInfoResponse, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 
-	res := args.Get(0).(*ip.GetInfoResponse)
+	res := args.Get(0).(*GetInfoResponse)
 
 	if ctx.Value(testContextKey("test")) != nil {
 		res.Info["test"] = ctx.Value(testContextKey("test"))
@@ -33,13 +31,13 @@ func (m *mockInfoProvider) GetInfo(ctx context.Context, req *ip.GetInfoRequest)
 	return res, args.Error(1)
 }
 
-func TestInfoProvider_GetInfo(t *testing.T) {
+func TestRouter_GetInfo(t *testing.T) {
 	testCases := map[string]struct {
-		req                  *ip.GetInfoRequest
-		mockInfoProviderResp *ip.GetInfoResponse
+		req                  *GetInfoRequest
+		mockInfoProviderResp *GetInfoResponse
 		mockInfoProviderErr  error
 		setupContext         func() context.Context
-		expectedResult       *ip.GetInfoResponse
+		expectedResult       *GetInfoResponse
 		expectedError        string
 	}{
 		"should return error when request is nil": {
@@ -50,11 +48,11 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 		},
 
 		"should return info when info provider exists": {
-			req: &ip.GetInfoRequest{
+			req: &GetInfoRequest{
 				InfoType: "user",
 				Params:   "user123",
 			},
-			mockInfoProviderResp: &ip.GetInfoResponse{
+			mockInfoProviderResp: &GetInfoResponse{
 				Info: map[string]any{
 					"id":         "user123",
 					"name":       "John Doe",
@@ -62,7 +60,7 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 				},
 			},
 			setupContext: func() context.Context { return context.Background() },
-			expectedResult: &ip.GetInfoResponse{
+			expectedResult: &GetInfoResponse{
 				Info: map[string]any{
 					"id":         "user123",
 					"name":       "John Doe",
@@ -72,7 +70,7 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 		},
 
 		"should return error when unsupported info type is requested": {
-			req: &ip.GetInfoRequest{
+			req: &GetInfoRequest{
 				InfoType: "unsupported",
 				Params:   "param123",
 			},
@@ -82,7 +80,7 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 		},
 
 		"should return error when provider returns error": {
-			req: &ip.GetInfoRequest{
+			req: &GetInfoRequest{
 				InfoType: "user",
 				Params:   "invalidUser",
 			},
@@ -93,11 +91,11 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 		},
 
 		"should pass context to underlying provider": {
-			req: &ip.GetInfoRequest{
+			req: &GetInfoRequest{
 				InfoType: "user",
 				Params:   "contextTest",
 			},
-			mockInfoProviderResp: &ip.GetInfoResponse{
+			mockInfoProviderResp: &GetInfoResponse{
 				Info: map[string]any{
 					"id":         "user123",
 					"name":       "John Doe",
@@ -107,7 +105,7 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 			setupContext: func() context.Context {
 				return context.WithValue(context.Background(), testContextKey("test"), "value")
 			},
-			expectedResult: &ip.GetInfoResponse{
+			expectedResult: &GetInfoResponse{
 				Info: map[string]any{
 					"id":         "user123",
 					"name":       "John Doe",
@@ -134,8 +132,8 @@ func TestInfoProvider_GetInfo(t *testing.T) {
 				)
 			}
 
-			// Create an info provider
-			p := NewInfoProvider(map[InfoType]ip.InfoProvider{
+			// Create a router
+			p := NewRouter(map[string]InfoProvider{
 				"user": userProvider,
 			})
 
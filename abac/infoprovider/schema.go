@@ -0,0 +1,224 @@
+package infoprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AttributeType identifies the type an attribute value is coerced to
+type AttributeType string
+
+const (
+	AttributeTypeString  AttributeType = "string"  // Coerced to string
+	AttributeTypeNumber  AttributeType = "number"  // Coerced to float64
+	AttributeTypeInteger AttributeType = "integer" // Coerced to int64, from numbers without a fractional part
+	AttributeTypeBoolean AttributeType = "boolean" // Coerced to bool
+	AttributeTypeTime    AttributeType = "time"    // Coerced to time.Time, from RFC 3339 strings
+	AttributeTypeObject  AttributeType = "object"  // Coerced to map[string]any, from any value encoding to a JSON object
+)
+
+// AttributeDefinition declares an attribute of an info response
+type AttributeDefinition struct {
+	Name        string        `json:"name"`
+	Type        AttributeType `json:"type"`
+	MultiValued bool          `json:"multiValued,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+}
+
+// Schema declares the attributes of the responses of an info type. Attributes it does not declare are left as is.
+type Schema struct {
+	Attributes []AttributeDefinition `json:"attributes"`
+}
+
+// SchemaError reports the attributes of an info response violating its schema
+type SchemaError struct {
+	Missing []string // Names of required attributes that are absent or null
+	Invalid []string // Descriptions of attributes whose value cannot be coerced to their type
+}
+
+// Error implements the error interface
+func (e *SchemaError) Error() string {
+	violations := make([]string, 0, 2)
+	if len(e.Missing) > 0 {
+		violations = append(violations, fmt.Sprintf("missing required attributes: %s", strings.Join(e.Missing, ", ")))
+	}
+
+	if len(e.Invalid) > 0 {
+		violations = append(violations, fmt.Sprintf("invalid attributes: %s", strings.Join(e.Invalid, "; ")))
+	}
+
+	return strings.Join(violations, "; ")
+}
+
+// Validate checks the info against the schema and returns a copy with declared attributes coerced to their type:
+// single values to the Go type of the attribute type, and multi-valued attributes to a slice of it, such as []string.
+// It returns a *SchemaError listing every violation.
+func (s Schema) Validate(info map[string]any) (map[string]any, error) {
+	coerced := make(map[string]any, len(info))
+	for name, value := range info {
+		coerced[name] = value
+	}
+
+	schemaErr := &SchemaError{}
+	for _, attribute := range s.Attributes {
+		value, ok := info[attribute.Name]
+		if !ok || value == nil {
+			if attribute.Required {
+				schemaErr.Missing = append(schemaErr.Missing, attribute.Name)
+			}
+
+			continue
+		}
+
+		coercedValue, err := attribute.coerce(value)
+		if err != nil {
+			schemaErr.Invalid = append(schemaErr.Invalid, fmt.Sprintf("%s %v", attribute.Name, err))
+			continue
+		}
+
+		coerced[attribute.Name] = coercedValue
+	}
+
+	if len(schemaErr.Missing) > 0 || len(schemaErr.Invalid) > 0 {
+		sort.Strings(schemaErr.Missing)
+		sort.Strings(schemaErr.Invalid)
+		return nil, schemaErr
+	}
+
+	return coerced, nil
+}
+
+// coerce converts the value to the attribute type, element by element when the attribute is multi-valued
+func (a AttributeDefinition) coerce(value any) (any, error) {
+	if !a.MultiValued {
+		return coerceValue(a.Type, value)
+	}
+
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return nil, fmt.Errorf("must be a list of %s, got %T", a.Type, value)
+	}
+
+	values := reflect.MakeSlice(reflect.SliceOf(goType(a.Type)), 0, list.Len())
+	for idx := range list.Len() {
+		element, err := coerceValue(a.Type, list.Index(idx).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("at index %d %w", idx, err)
+		}
+
+		values = reflect.Append(values, reflect.ValueOf(element))
+	}
+
+	return values.Interface(), nil
+}
+
+// goType returns the Go type values of the attribute type are coerced to
+func goType(attributeType AttributeType) reflect.Type {
+	switch attributeType {
+	case AttributeTypeString:
+		return reflect.TypeFor[string]()
+	case AttributeTypeNumber:
+		return reflect.TypeFor[float64]()
+	case AttributeTypeInteger:
+		return reflect.TypeFor[int64]()
+	case AttributeTypeBoolean:
+		return reflect.TypeFor[bool]()
+	case AttributeTypeTime:
+		return reflect.TypeFor[time.Time]()
+	case AttributeTypeObject:
+		return reflect.TypeFor[map[string]any]()
+	default:
+		return reflect.TypeFor[any]()
+	}
+}
+
+// coerceValue converts a single value to the attribute type
+func coerceValue(attributeType AttributeType, value any) (any, error) {
+	switch attributeType {
+	case AttributeTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case AttributeTypeNumber:
+		if number, ok := toFloat(value); ok {
+			return number, nil
+		}
+	case AttributeTypeInteger:
+		if number, ok := toFloat(value); ok && number == math.Trunc(number) {
+			return int64(number), nil
+		}
+	case AttributeTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case AttributeTypeTime:
+		return coerceTime(value)
+	case AttributeTypeObject:
+		return coerceObject(value)
+	default:
+		return nil, fmt.Errorf("has unknown type %q", attributeType)
+	}
+
+	return nil, fmt.Errorf("must be %s, got %T", attributeType, value)
+}
+
+// toFloat converts a numeric value to a float64
+func toFloat(value any) (float64, bool) {
+	if number, ok := value.(json.Number); ok {
+		f, err := number.Float64()
+		return f, err == nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// coerceTime converts a time or RFC 3339 string to a time.Time
+func coerceTime(value any) (any, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("must be an RFC 3339 time, got %q", v)
+		}
+
+		return t, nil
+	default:
+		return nil, fmt.Errorf("must be time, got %T", value)
+	}
+}
+
+// coerceObject converts a value encoding to a JSON object, such as a struct or typed map, to a map[string]any
+func coerceObject(value any) (any, error) {
+	if object, ok := value.(map[string]any); ok {
+		return object, nil
+	}
+
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("must be object, got %T", value)
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(content, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("must be object, got %T", value)
+	}
+
+	return object, nil
+}
@@ -0,0 +1,118 @@
+package infoprovider
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := map[string]struct {
+		schema         Schema
+		info           map[string]any
+		expectedResult map[string]any
+		expectedError  *SchemaError
+	}{
+		"should coerce declared attributes and keep undeclared ones": {
+			schema: Schema{Attributes: []AttributeDefinition{
+				{Name: "roles", Type: AttributeTypeString, MultiValued: true, Required: true},
+				{Name: "level", Type: AttributeTypeInteger},
+				{Name: "score", Type: AttributeTypeNumber},
+				{Name: "active", Type: AttributeTypeBoolean},
+				{Name: "createdAt", Type: AttributeTypeTime},
+				{Name: "profile", Type: AttributeTypeObject},
+			}},
+			info: map[string]any{
+				"roles":     []any{"admin", "viewer"},
+				"level":     json.Number("3"),
+				"score":     4,
+				"active":    true,
+				"createdAt": "2025-01-02T03:04:05Z",
+				"profile":   map[string]string{"team": "platform"},
+				"extra":     "kept",
+			},
+			expectedResult: map[string]any{
+				"roles":     []string{"admin", "viewer"},
+				"level":     int64(3),
+				"score":     float64(4),
+				"active":    true,
+				"createdAt": createdAt,
+				"profile":   map[string]any{"team": "platform"},
+				"extra":     "kept",
+			},
+		},
+		"should skip optional attributes that are absent or null": {
+			schema: Schema{Attributes: []AttributeDefinition{
+				{Name: "department", Type: AttributeTypeString},
+				{Name: "region", Type: AttributeTypeString},
+			}},
+			info:           map[string]any{"region": nil},
+			expectedResult: map[string]any{"region": nil},
+		},
+		"should report missing required and invalid attributes": {
+			schema: Schema{Attributes: []AttributeDefinition{
+				{Name: "roles", Type: AttributeTypeString, MultiValued: true, Required: true},
+				{Name: "owner", Type: AttributeTypeString, Required: true},
+				{Name: "level", Type: AttributeTypeInteger},
+				{Name: "tags", Type: AttributeTypeString, MultiValued: true},
+				{Name: "createdAt", Type: AttributeTypeTime},
+			}},
+			info: map[string]any{
+				"owner":     nil,
+				"level":     1.5,
+				"tags":      []any{"a", 1},
+				"createdAt": "yesterday",
+			},
+			expectedError: &SchemaError{
+				Missing: []string{"owner", "roles"},
+				Invalid: []string{
+					"createdAt must be an RFC 3339 time, got \"yesterday\"",
+					"level must be integer, got float64",
+					"tags at index 1 must be string, got int",
+				},
+			},
+		},
+		"should reject a single value for a multi-valued attribute": {
+			schema: Schema{Attributes: []AttributeDefinition{
+				{Name: "roles", Type: AttributeTypeString, MultiValued: true},
+			}},
+			info:          map[string]any{"roles": "admin"},
+			expectedError: &SchemaError{Invalid: []string{"roles must be a list of string, got string"}},
+		},
+		"should reject values not encoding to an object": {
+			schema: Schema{Attributes: []AttributeDefinition{
+				{Name: "profile", Type: AttributeTypeObject},
+			}},
+			info:          map[string]any{"profile": []string{"team"}},
+			expectedError: &SchemaError{Invalid: []string{"profile must be object, got []string"}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := tc.schema.Validate(tc.info)
+
+			if tc.expectedError != nil {
+				var schemaErr *SchemaError
+				require.ErrorAs(t, err, &schemaErr)
+				assert.Equal(t, tc.expectedError, schemaErr)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestSchemaError_Error(t *testing.T) {
+	err := &SchemaError{Missing: []string{"owner", "roles"}, Invalid: []string{"level must be integer, got float64"}}
+
+	assert.Equal(t, "missing required attributes: owner, roles; invalid attributes: level must be integer, got float64", err.Error())
+}
@@ -0,0 +1,126 @@
+// Package lru provides a size-bounded cache evicting its least recently used entries, with optional per-entry expiry.
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is a value stored in the cache under its key, expiring at expiresAt unless it is zero
+type entry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+}
+
+// Cache is a size-bounded, least recently used cache, safe for concurrent use. Expired values are removed when read,
+// or evicted with the other least recently used values once the cache is full.
+type Cache struct {
+	size    int
+	now     func() time.Time
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// Option defines configuration options for the Cache
+type Option func(*Cache)
+
+// New creates a cache holding at most size values, or none when size is zero or less
+func New(size int, options ...Option) *Cache {
+	c := &Cache{
+		size:    max(size, 0),
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// WithClock sets the function reporting the current time to expire values against, time.Now by default
+func WithClock(now func() time.Time) Option {
+	return func(c *Cache) {
+		c.now = now
+	}
+}
+
+// Get returns the value stored under the key, marking it as most recently used, unless it has expired
+func (c *Cache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := element.Value.(*entry)
+	if !e.expiresAt.IsZero() && !c.now().Before(e.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+// Add stores the value under the key without expiry, evicting the least recently used value when the cache is full
+func (c *Cache) Add(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(key, value, time.Time{})
+}
+
+// AddWithTTL stores the value under the key until the TTL elapses, evicting the least recently used value when the
+// cache is full. A TTL of zero or less removes the value stored under the key instead.
+func (c *Cache) AddWithTTL(key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ttl <= 0 {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+
+		return
+	}
+
+	c.add(key, value, c.now().Add(ttl))
+}
+
+// Len returns the number of cached values, including expired values not yet removed
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// add stores the value as the most recently used one. Callers must hold the lock.
+func (c *Cache) add(key string, value any, expiresAt time.Time) {
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove deletes the element from the cache. Callers must hold the lock.
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,132 @@
+package lru
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	tests := map[string]struct {
+		size           int
+		operations     func(c *Cache)
+		expectedValues map[string]any
+		missingKeys    []string
+	}{
+		"should return added values": {
+			size: 2,
+			operations: func(c *Cache) {
+				c.Add("a", 1)
+				c.Add("b", 2)
+			},
+			expectedValues: map[string]any{"a": 1, "b": 2},
+		},
+
+		"should evict least recently added value when full": {
+			size: 2,
+			operations: func(c *Cache) {
+				c.Add("a", 1)
+				c.Add("b", 2)
+				c.Add("c", 3)
+			},
+			expectedValues: map[string]any{"b": 2, "c": 3},
+			missingKeys:    []string{"a"},
+		},
+
+		"should keep recently read value when full": {
+			size: 2,
+			operations: func(c *Cache) {
+				c.Add("a", 1)
+				c.Add("b", 2)
+				c.Get("a")
+				c.Add("c", 3)
+			},
+			expectedValues: map[string]any{"a": 1, "c": 3},
+			missingKeys:    []string{"b"},
+		},
+
+		"should replace value added under existing key": {
+			size: 2,
+			operations: func(c *Cache) {
+				c.Add("a", 1)
+				c.Add("a", 2)
+			},
+			expectedValues: map[string]any{"a": 2},
+		},
+
+		"should remove value when added with non-positive ttl": {
+			size: 2,
+			operations: func(c *Cache) {
+				c.Add("a", 1)
+				c.AddWithTTL("a", 2, 0)
+			},
+			expectedValues: map[string]any{},
+			missingKeys:    []string{"a"},
+		},
+
+		"should not store values when size is negative": {
+			size: -1,
+			operations: func(c *Cache) {
+				c.Add("a", 1)
+				c.AddWithTTL("b", 2, time.Minute)
+			},
+			expectedValues: map[string]any{},
+			missingKeys:    []string{"a", "b"},
+		},
+
+		"should not store values when size is zero": {
+			size: 0,
+			operations: func(c *Cache) {
+				c.Add("a", 1)
+			},
+			expectedValues: map[string]any{},
+			missingKeys:    []string{"a"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache := New(tc.size)
+			tc.operations(cache)
+
+			assert.Equal(t, len(tc.expectedValues), cache.Len())
+			for key, expected := range tc.expectedValues {
+				value, ok := cache.Get(key)
+				assert.True(t, ok, "expected key %s to be cached", key)
+				assert.Equal(t, expected, value)
+			}
+			for _, key := range tc.missingKeys {
+				_, ok := cache.Get(key)
+				assert.False(t, ok, "expected key %s to be evicted", key)
+			}
+		})
+	}
+}
+
+func TestCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := New(3, WithClock(func() time.Time { return now }))
+
+	cache.AddWithTTL("short", 1, time.Minute)
+	cache.AddWithTTL("long", 2, time.Hour)
+	cache.Add("forever", 3)
+
+	for _, step := range []struct {
+		elapsed      time.Duration
+		expectedKeys map[string]bool
+	}{
+		{elapsed: 0, expectedKeys: map[string]bool{"short": true, "long": true, "forever": true}},
+		{elapsed: time.Minute, expectedKeys: map[string]bool{"short": false, "long": true, "forever": true}},
+		{elapsed: time.Hour, expectedKeys: map[string]bool{"short": false, "long": false, "forever": true}},
+	} {
+		now = now.Add(step.elapsed)
+
+		for key, expected := range step.expectedKeys {
+			_, ok := cache.Get(key)
+			assert.Equal(t, expected, ok, "key %s after %s", key, step.elapsed)
+		}
+	}
+
+	assert.Equal(t, 1, cache.Len(), "expired values should be removed when read")
+}
@@ -0,0 +1,45 @@
+// Package sharedcall collapses concurrent calls for the same key into a single call that outlives its callers.
+package sharedcall
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Group runs at most one call per key at a time, sharing its result with every caller waiting on the key.
+// The shared call runs detached from the cancellation of the caller that started it, bounded by the group timeout
+// instead, so a caller giving up does not fail the callers still waiting; each caller stops waiting once its own
+// context is done.
+type Group struct {
+	group   singleflight.Group
+	timeout time.Duration
+}
+
+// New creates a Group bounding each shared call by the timeout, or leaving it unbounded when the timeout is zero or less
+func New(timeout time.Duration) *Group {
+	return &Group{timeout: timeout}
+}
+
+// Do calls fn for the key unless a call for it is in flight, and waits for the result of the call or for ctx to be done.
+// The context passed to fn carries the values of ctx, but not its cancellation or deadline.
+func (g *Group) Do(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (any, error) {
+	results := g.group.DoChan(key, func() (any, error) {
+		callCtx := context.WithoutCancel(ctx)
+		if g.timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(callCtx, g.timeout)
+			defer cancel()
+		}
+
+		return fn(callCtx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		return result.Val, result.Err
+	}
+}
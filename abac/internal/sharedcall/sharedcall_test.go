@@ -0,0 +1,89 @@
+package sharedcall
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Do(t *testing.T) {
+	t.Run("should share the call with a caller still waiting after the first one cancels", func(t *testing.T) {
+		group := New(time.Second)
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		var calls atomic.Int32
+		fn := func(ctx context.Context) (any, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+				return "value", nil
+			}
+		}
+
+		firstCtx, cancelFirst := context.WithCancel(context.Background())
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := group.Do(firstCtx, "key", fn)
+			firstErr <- err
+		}()
+		<-started
+
+		secondResult := make(chan any, 1)
+		secondErr := make(chan error, 1)
+		go func() {
+			value, err := group.Do(context.Background(), "key", fn)
+			secondResult <- value
+			secondErr <- err
+		}()
+
+		// Give the second caller time to join the call in flight
+		time.Sleep(20 * time.Millisecond)
+
+		cancelFirst()
+		assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+		close(release)
+		require.NoError(t, <-secondErr)
+		assert.Equal(t, "value", <-secondResult)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("should bound the call by the group timeout", func(t *testing.T) {
+		group := New(10 * time.Millisecond)
+
+		_, err := group.Do(context.Background(), "key", func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should pass context values to the call", func(t *testing.T) {
+		type contextKey struct{}
+		group := New(0)
+		ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+		value, err := group.Do(ctx, "key", func(ctx context.Context) (any, error) {
+			if ctx.Value(contextKey{}) != "value" {
+				return nil, errors.New("context value missing")
+			}
+
+			return ctx.Value(contextKey{}), nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+}
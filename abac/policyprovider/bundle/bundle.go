@@ -0,0 +1,187 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	opabundle "github.com/open-policy-agent/opa/v1/bundle"
+)
+
+// DataPolicyID is the ID of the policy holding the data documents of a bundle, merged into a single JSON document
+const DataPolicyID = "data.json"
+
+// snapshot holds the policies of a loaded bundle, keyed by policy ID
+type snapshot struct {
+	revision string
+	policies map[string][]byte
+	hashes   map[string]string // Content hashes of the policies, keyed by policy ID
+}
+
+// policyProvider implements the PolicyProvider interface by serving the policies of the current bundle snapshot
+type policyProvider struct {
+	mu      sync.RWMutex
+	current *snapshot
+}
+
+// loader reads bundle archives, verifying their signatures when verification keys are configured
+type loader struct {
+	verification *opabundle.VerificationConfig
+}
+
+// NewFromFile creates a PolicyProvider serving the policies of the OPA bundle archive (.tar.gz) at path.
+// Each Rego module is served under its path in the bundle, such as "rbac/policy.rego", and the data documents under
+// DataPolicyID. Policies are versioned by the manifest revision; see GetPolicies for how versions are matched.
+func NewFromFile(path string, options ...Option) (policyprovider.PolicyProvider, error) {
+	config := newConfig(options)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+
+	loaded, err := config.loader.load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policyProvider{current: loaded}, nil
+}
+
+// GetPolicies retrieves multiple policies from the current bundle.
+// A request matches a policy when its version is empty or equal to the bundle revision.
+func (p *policyProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	p.mu.RLock()
+	current := p.current
+	p.mu.RUnlock()
+
+	policies := make([]policyprovider.PolicyResponse, 0, len(reqs))
+
+	for _, req := range reqs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		content, ok := current.policies[req.ID]
+		if !ok {
+			return nil, fmt.Errorf("failed to get policy %s@%s: %w", req.ID, req.Version, policyprovider.ErrPolicyNotFound)
+		}
+
+		if req.Version != "" && req.Version != current.revision {
+			return nil, fmt.Errorf(
+				"failed to get policy %s@%s: %w, bundle revision is %q",
+				req.ID,
+				req.Version,
+				policyprovider.ErrPolicyNotFound,
+				current.revision,
+			)
+		}
+
+		policies = append(policies, policyprovider.PolicyResponse{
+			ID:          req.ID,
+			Version:     current.revision,
+			Content:     content,
+			ContentHash: current.hashes[req.ID],
+		})
+	}
+
+	return policies, nil
+}
+
+// revision returns the revision of the current bundle
+func (p *policyProvider) revision() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.current.revision
+}
+
+// swap replaces the current bundle snapshot
+func (p *policyProvider) swap(next *snapshot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current = next
+}
+
+// load reads a bundle archive, validating its manifest roots and signatures, and extracts its Rego modules and data
+// documents
+func (l loader) load(r io.Reader) (*snapshot, error) {
+	b, err := l.read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string][]byte, len(b.Modules)+1)
+	for _, module := range b.Modules {
+		policies[strings.TrimPrefix(module.Path, "/")] = module.Raw
+	}
+
+	if len(b.Data) > 0 {
+		data, err := json.Marshal(b.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal bundle data: %w", err)
+		}
+
+		policies[DataPolicyID] = data
+	}
+
+	hashes := make(map[string]string, len(policies))
+	for id, content := range policies {
+		hashes[id] = policyprovider.HashContent(content)
+	}
+
+	return &snapshot{
+		revision: b.Manifest.Revision,
+		policies: policies,
+		hashes:   hashes,
+	}, nil
+}
+
+// read parses a bundle archive. When verification keys are configured, the bundle must carry a .signatures.json
+// JWS manifest signed by one of them, and an archive failing verification but otherwise readable is reported as an
+// invalid signature rather than a malformed bundle.
+func (l loader) read(r io.Reader) (opabundle.Bundle, error) {
+	if l.verification == nil {
+		b, err := opabundle.NewCustomReader(opabundle.NewTarballLoader(r)).Read()
+		if err != nil {
+			return opabundle.Bundle{}, fmt.Errorf("failed to read bundle: %w", err)
+		}
+
+		return b, nil
+	}
+
+	archive, err := io.ReadAll(r)
+	if err != nil {
+		return opabundle.Bundle{}, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	b, verifyErr := opabundle.NewCustomReader(opabundle.NewTarballLoader(bytes.NewReader(archive))).
+		WithBundleVerificationConfig(l.verification).
+		Read()
+	if verifyErr == nil {
+		if len(b.Signatures.Signatures) == 0 {
+			return opabundle.Bundle{}, fmt.Errorf("failed to read bundle: %w", policyprovider.ErrSignatureMissing)
+		}
+
+		return b, nil
+	}
+
+	if _, err := opabundle.NewCustomReader(opabundle.NewTarballLoader(bytes.NewReader(archive))).
+		WithSkipBundleVerification(true).
+		Read(); err != nil {
+		return opabundle.Bundle{}, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	return opabundle.Bundle{}, fmt.Errorf("failed to read bundle: %w: %w", policyprovider.ErrSignatureInvalid, verifyErr)
+}
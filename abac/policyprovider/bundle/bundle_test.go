@@ -0,0 +1,302 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	opabundle "github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testManifest = `{"revision": "rev-1", "roots": ["rbac"]}`
+	testPolicy   = "package rbac\n\nallow if input.subject.id == \"alice\"\n"
+	testData     = `{"roles": {"admin": ["read"]}}`
+)
+
+func TestNewFromFile(t *testing.T) {
+	testCases := map[string]struct {
+		files         map[string]string
+		path          string
+		expectedError string
+	}{
+		"should load bundle successfully": {
+			files: testBundleFiles(),
+		},
+
+		"should return error when bundle does not exist": {
+			path:          "/nonexistent/bundle.tar.gz",
+			expectedError: "failed to open bundle",
+		},
+
+		"should return error when module is outside manifest roots": {
+			files: map[string]string{
+				"/.manifest":       testManifest,
+				"/other/rbac.rego": "package other\n",
+			},
+			expectedError: "failed to read bundle: manifest roots [rbac] do not permit 'package other'",
+		},
+
+		"should return error when module is invalid": {
+			files: map[string]string{
+				"/rbac/rbac.rego": "package",
+			},
+			expectedError: "failed to read bundle: 1 error occurred: /rbac/rbac.rego:1: rego_parse_error",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path := tc.path
+			if path == "" {
+				path = filepath.Join(t.TempDir(), "bundle.tar.gz")
+				require.NoError(t, os.WriteFile(path, newTestBundle(t, tc.files), 0600))
+			}
+
+			provider, err := NewFromFile(path)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, provider)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, provider)
+		})
+	}
+}
+
+func TestPolicyProvider_GetPolicies(t *testing.T) {
+	loaded, err := loader{}.load(bytes.NewReader(newTestBundle(t, testBundleFiles())))
+	require.NoError(t, err)
+
+	provider := &policyProvider{current: loaded}
+
+	testCases := map[string]struct {
+		requests        []policyprovider.GetPolicyRequest
+		setupContext    func() context.Context
+		expectedResult  []policyprovider.PolicyResponse
+		expectedError   string
+		expectedErrorIs error
+	}{
+		"should retrieve module and data policies successfully": {
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "rbac/rbac.rego", Version: "rev-1"},
+				{ID: DataPolicyID, Version: "rev-1"},
+			},
+			setupContext: context.Background,
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "rbac/rbac.rego",
+					Version:     "rev-1",
+					Content:     []byte(testPolicy),
+					ContentHash: policyprovider.HashContent([]byte(testPolicy)),
+				},
+				{
+					ID:          DataPolicyID,
+					Version:     "rev-1",
+					Content:     []byte(`{"rbac":{"roles":{"admin":["read"]}}}`),
+					ContentHash: policyprovider.HashContent([]byte(`{"rbac":{"roles":{"admin":["read"]}}}`)),
+				},
+			},
+		},
+
+		"should match any revision when version is empty": {
+			requests:     []policyprovider.GetPolicyRequest{{ID: "rbac/rbac.rego"}},
+			setupContext: context.Background,
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "rbac/rbac.rego",
+					Version:     "rev-1",
+					Content:     []byte(testPolicy),
+					ContentHash: policyprovider.HashContent([]byte(testPolicy)),
+				},
+			},
+		},
+
+		"should return error when policy does not exist": {
+			requests:        []policyprovider.GetPolicyRequest{{ID: "nonexistent.rego", Version: "rev-1"}},
+			setupContext:    context.Background,
+			expectedError:   "failed to get policy nonexistent.rego@rev-1: policy not found",
+			expectedErrorIs: policyprovider.ErrPolicyNotFound,
+		},
+
+		"should return error when version does not match revision": {
+			requests:        []policyprovider.GetPolicyRequest{{ID: "rbac/rbac.rego", Version: "rev-0"}},
+			setupContext:    context.Background,
+			expectedError:   `failed to get policy rbac/rbac.rego@rev-0: policy not found, bundle revision is "rev-1"`,
+			expectedErrorIs: policyprovider.ErrPolicyNotFound,
+		},
+
+		"should return error when context is cancelled": {
+			requests: []policyprovider.GetPolicyRequest{{ID: "rbac/rbac.rego", Version: "rev-1"}},
+			setupContext: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			expectedError: "context canceled",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := provider.GetPolicies(tc.setupContext(), tc.requests)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				if tc.expectedErrorIs != nil {
+					assert.ErrorIs(t, err, tc.expectedErrorIs)
+				}
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestNewFromFile_Verification(t *testing.T) {
+	signingKey, publicKey := newTestKeyPair(t)
+	_, otherPublicKey := newTestKeyPair(t)
+
+	testCases := map[string]struct {
+		bundle        func(t *testing.T) []byte
+		keys          map[string]policyprovider.VerificationKey
+		expectedError error
+		errorMessage  string
+	}{
+		"should load bundle signed by a configured key": {
+			bundle: func(t *testing.T) []byte { return newSignedTestBundle(t, signingKey, "platform") },
+			keys:   map[string]policyprovider.VerificationKey{"platform": {Algorithm: "ES256", PublicKey: publicKey}},
+		},
+
+		"should return missing signature error when bundle is unsigned": {
+			bundle:        func(t *testing.T) []byte { return newTestBundle(t, testBundleFiles()) },
+			keys:          map[string]policyprovider.VerificationKey{"platform": {Algorithm: "ES256", PublicKey: publicKey}},
+			expectedError: policyprovider.ErrSignatureMissing,
+		},
+
+		"should return invalid signature error when signed by another key": {
+			bundle:        func(t *testing.T) []byte { return newSignedTestBundle(t, signingKey, "platform") },
+			keys:          map[string]policyprovider.VerificationKey{"platform": {Algorithm: "ES256", PublicKey: otherPublicKey}},
+			expectedError: policyprovider.ErrSignatureInvalid,
+		},
+
+		"should return invalid signature error when key ID is unknown": {
+			bundle:        func(t *testing.T) []byte { return newSignedTestBundle(t, signingKey, "unknown") },
+			keys:          map[string]policyprovider.VerificationKey{"platform": {Algorithm: "ES256", PublicKey: publicKey}},
+			expectedError: policyprovider.ErrSignatureInvalid,
+		},
+
+		"should return read error when bundle is malformed": {
+			bundle:       func(t *testing.T) []byte { return newTestBundle(t, map[string]string{"/rbac/rbac.rego": "package"}) },
+			keys:         map[string]policyprovider.VerificationKey{"platform": {Algorithm: "ES256", PublicKey: publicKey}},
+			errorMessage: "failed to read bundle: 1 error occurred: /rbac/rbac.rego:1: rego_parse_error",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+			require.NoError(t, os.WriteFile(path, tc.bundle(t), 0600))
+
+			provider, err := NewFromFile(path, WithVerificationKeys(tc.keys))
+
+			switch {
+			case tc.expectedError != nil:
+				require.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, provider)
+			case tc.errorMessage != "":
+				require.ErrorContains(t, err, tc.errorMessage)
+				assert.NotErrorIs(t, err, policyprovider.ErrSignatureInvalid)
+				assert.Nil(t, provider)
+			default:
+				require.NoError(t, err)
+
+				result, err := provider.GetPolicies(context.Background(), []policyprovider.GetPolicyRequest{{ID: "rbac/rbac.rego"}})
+				require.NoError(t, err)
+				assert.Equal(t, []byte(testPolicy), result[0].Content)
+			}
+		})
+	}
+}
+
+func testBundleFiles() map[string]string {
+	return map[string]string{
+		"/.manifest":           testManifest,
+		"/rbac/rbac.rego":      testPolicy,
+		"/rbac/data.json":      testData,
+		"/rbac/README.md":      "ignored",
+		"/rbac/nested/.hidden": "ignored",
+	}
+}
+
+// newSignedTestBundle builds the test bundle with a .signatures.json signed by the PEM encoded ES256 key
+func newSignedTestBundle(t *testing.T, signingKey, keyID string) []byte {
+	b, err := opabundle.NewCustomReader(opabundle.NewTarballLoader(bytes.NewReader(newTestBundle(t, testBundleFiles())))).Read()
+	require.NoError(t, err)
+
+	require.NoError(t, b.GenerateSignature(opabundle.NewSigningConfig(signingKey, "ES256", ""), keyID, false))
+
+	var buf bytes.Buffer
+	require.NoError(t, opabundle.NewWriter(&buf).Write(b))
+
+	return buf.Bytes()
+}
+
+// newTestKeyPair generates an ECDSA P-256 key pair, returning the PEM encoded private and public keys
+func newTestKeyPair(t *testing.T) (privateKeyPEM, publicKeyPEM string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+}
+
+// newTestBundle builds a gzipped tarball containing the files, keyed by path
+func newTestBundle(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for path, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     path,
+			Mode:     0600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,141 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+)
+
+// PollingPolicyProvider is a PolicyProvider serving the policies of a bundle polled from an HTTP endpoint
+type PollingPolicyProvider interface {
+	policyprovider.PolicyProvider
+
+	// Revision returns the manifest revision of the bundle currently served
+	Revision() string
+
+	// Close stops polling the bundle endpoint
+	Close() error
+}
+
+// pollingPolicyProvider implements PollingPolicyProvider, downloading the bundle again only when its ETag changes
+type pollingPolicyProvider struct {
+	*policyProvider
+
+	url    string
+	config config
+	etag   string
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewFromURL creates a PolicyProvider serving the policies of the OPA bundle archive downloaded from url.
+// The bundle is downloaded before returning, then polled in the background using If-None-Match with the ETag of the
+// last download. A bundle failing to download or load is reported to the error handler and the previous one kept.
+func NewFromURL(url string, options ...Option) (PollingPolicyProvider, error) {
+	p := &pollingPolicyProvider{
+		url:    url,
+		config: newConfig(options),
+		done:   make(chan struct{}),
+	}
+
+	if p.config.pollInterval <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive, got %s", p.config.pollInterval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	loaded, err := p.fetch(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	p.policyProvider = &policyProvider{current: loaded}
+
+	go p.poll(ctx)
+
+	return p, nil
+}
+
+// Revision returns the manifest revision of the bundle currently served
+func (p *pollingPolicyProvider) Revision() string {
+	return p.revision()
+}
+
+// Close stops polling and waits for the poll loop to exit
+func (p *pollingPolicyProvider) Close() error {
+	p.closeOnce.Do(func() {
+		p.cancel()
+		<-p.done
+	})
+
+	return nil
+}
+
+// poll downloads the bundle every poll interval until the context is cancelled
+func (p *pollingPolicyProvider) poll(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			loaded, err := p.fetch(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					p.config.onError(err)
+				}
+				continue
+			}
+
+			if loaded != nil {
+				p.swap(loaded)
+			}
+		}
+	}
+}
+
+// fetch downloads and loads the bundle, returning nil when it is unchanged since the last download
+func (p *pollingPolicyProvider) fetch(ctx context.Context) (*snapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle request: %w", err)
+	}
+
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+
+	resp, err := p.config.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download bundle: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("failed to download bundle: unexpected status %d", resp.StatusCode)
+	}
+
+	loaded, err := p.config.loader.load(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	p.etag = resp.Header.Get("ETag")
+
+	return loaded, nil
+}
@@ -0,0 +1,189 @@
+package bundle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPollInterval = 10 * time.Millisecond
+
+// bundleServer serves a bundle with an ETag, answering 304 Not Modified when the client already has it
+type bundleServer struct {
+	mu       sync.Mutex
+	etag     string
+	bundle   []byte
+	status   int
+	requests []string
+}
+
+func (s *bundleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Header.Get("If-None-Match"))
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", s.etag)
+	_, _ = w.Write(s.bundle)
+}
+
+func (s *bundleServer) publish(etag string, bundle []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.etag = etag
+	s.bundle = bundle
+}
+
+func (s *bundleServer) fail(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = status
+}
+
+func (s *bundleServer) conditionalRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, etag := range s.requests {
+		if etag != "" {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestNewFromURL(t *testing.T) {
+	testCases := map[string]struct {
+		status        int
+		bundle        map[string]string
+		options       []Option
+		expectedError string
+	}{
+		"should download bundle successfully": {
+			bundle: testBundleFiles(),
+		},
+
+		"should return error when poll interval is not positive": {
+			bundle:        testBundleFiles(),
+			options:       []Option{WithPollInterval(0)},
+			expectedError: "poll interval must be positive, got 0s",
+		},
+
+		"should return error when endpoint fails": {
+			status:        http.StatusInternalServerError,
+			expectedError: "failed to download bundle: unexpected status 500",
+		},
+
+		"should return error when bundle is invalid": {
+			bundle:        map[string]string{"/rbac/rbac.rego": "package"},
+			expectedError: "failed to read bundle",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := &bundleServer{status: tc.status}
+			server.publish(`"etag-1"`, newTestBundle(t, tc.bundle))
+
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			provider, err := NewFromURL(httpServer.URL, append([]Option{WithHTTPClient(httpServer.Client())}, tc.options...)...)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, provider)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "rev-1", provider.Revision())
+			require.NoError(t, provider.Close())
+		})
+	}
+}
+
+func TestPollingPolicyProvider_Poll(t *testing.T) {
+	server := &bundleServer{}
+	server.publish(`"etag-1"`, newTestBundle(t, testBundleFiles()))
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	var mu sync.Mutex
+	var pollErrs []error
+
+	provider, err := NewFromURL(
+		httpServer.URL,
+		WithHTTPClient(httpServer.Client()),
+		WithPollInterval(testPollInterval),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			pollErrs = append(pollErrs, err)
+		}),
+	)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, provider.Close())
+	}()
+
+	// Unchanged bundles are not downloaded again
+	require.Eventually(t, func() bool { return server.conditionalRequests() > 0 }, time.Second, testPollInterval)
+	assert.Equal(t, "rev-1", provider.Revision())
+
+	updated := testBundleFiles()
+	updated["/.manifest"] = strings.Replace(testManifest, "rev-1", "rev-2", 1)
+	updated["/rbac/rbac.rego"] = "package rbac\n\nallow if input.subject.id == \"bob\"\n"
+	server.publish(`"etag-2"`, newTestBundle(t, updated))
+
+	require.Eventually(t, func() bool { return provider.Revision() == "rev-2" }, time.Second, testPollInterval)
+
+	result, err := provider.GetPolicies(context.Background(), []policyprovider.GetPolicyRequest{{ID: "rbac/rbac.rego"}})
+	require.NoError(t, err)
+	assert.Equal(t, []policyprovider.PolicyResponse{
+		{
+			ID:          "rbac/rbac.rego",
+			Version:     "rev-2",
+			Content:     []byte(updated["/rbac/rbac.rego"]),
+			ContentHash: policyprovider.HashContent([]byte(updated["/rbac/rbac.rego"])),
+		},
+	}, result)
+
+	// Failed polls keep the previous bundle
+	server.fail(http.StatusServiceUnavailable)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(pollErrs) > 0
+	}, time.Second, testPollInterval)
+
+	mu.Lock()
+	assert.ErrorContains(t, pollErrs[0], "failed to download bundle: unexpected status 503")
+	mu.Unlock()
+	assert.Equal(t, "rev-2", provider.Revision())
+}
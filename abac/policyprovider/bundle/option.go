@@ -0,0 +1,73 @@
+package bundle
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	opabundle "github.com/open-policy-agent/opa/v1/bundle"
+)
+
+const defaultPollInterval = time.Minute
+
+// config holds the configuration shared by the bundle providers
+type config struct {
+	loader       loader
+	client       *http.Client
+	pollInterval time.Duration
+	onError      func(error)
+}
+
+// Option defines configuration options for the bundle providers
+type Option func(*config)
+
+// newConfig applies the options over the default configuration
+func newConfig(options []Option) config {
+	c := config{
+		client:       http.DefaultClient,
+		pollInterval: defaultPollInterval,
+		onError:      func(error) {},
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return c
+}
+
+// WithVerificationKeys requires bundles to be signed by one of the keys, identified by the "kid" header of the
+// signature in the bundle's .signatures.json. Unsigned bundles fail to load with ErrSignatureMissing and bundles
+// failing verification with ErrSignatureInvalid.
+func WithVerificationKeys(keys map[string]policyprovider.VerificationKey) Option {
+	return func(c *config) {
+		keyConfigs := make(map[string]*opabundle.KeyConfig, len(keys))
+		for id, key := range keys {
+			keyConfigs[id] = &opabundle.KeyConfig{Key: key.PublicKey, Algorithm: key.Algorithm}
+		}
+
+		c.loader.verification = opabundle.NewVerificationConfig(keyConfigs, "", "", nil)
+	}
+}
+
+// WithHTTPClient sets the client used to download the bundle, http.DefaultClient by default
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+// WithPollInterval sets how often the bundle endpoint is polled, one minute by default. NewFromURL rejects an interval
+// of zero or less.
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.pollInterval = interval
+	}
+}
+
+// WithErrorHandler sets the function called when polling the bundle fails
+func WithErrorHandler(handler func(error)) Option {
+	return func(c *config) {
+		c.onError = handler
+	}
+}
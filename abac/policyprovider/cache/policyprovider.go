@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/internal/lru"
+	"github.com/CameronXie/access-control-explorer/abac/internal/sharedcall"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultTTL          = time.Minute
+	defaultMaxEntries   = 1000
+	defaultFetchTimeout = 30 * time.Second
+)
+
+// policyProvider decorates a PolicyProvider with a policy cache
+type policyProvider struct {
+	next       policyprovider.PolicyProvider
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	fetch      *sharedcall.Group
+	cache      *lru.Cache
+}
+
+// Option defines configuration options for the caching PolicyProvider
+type Option func(*policyProvider)
+
+// New creates a PolicyProvider that caches the policies retrieved from next, keyed on the requested ID and version.
+// Policies missing from the cache are fetched concurrently, one request each, and simultaneous fetches of the same
+// policy share a single call to next, which keeps running for up to 30 seconds when the caller that started it gives up.
+// Responses carry a ContentHash, computed when next does not report one.
+// Versions resolved by next, such as "latest", are served from the cache until their entry expires.
+func New(next policyprovider.PolicyProvider, options ...Option) policyprovider.PolicyProvider {
+	p := &policyProvider{
+		next:       next,
+		ttl:        defaultTTL,
+		maxEntries: defaultMaxEntries,
+		now:        time.Now,
+		fetch:      sharedcall.New(defaultFetchTimeout),
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	p.cache = lru.New(p.maxEntries, lru.WithClock(func() time.Time { return p.now() }))
+	return p
+}
+
+// WithTTL sets how long a policy is cached, one minute by default
+func WithTTL(ttl time.Duration) Option {
+	return func(p *policyProvider) {
+		p.ttl = ttl
+	}
+}
+
+// WithMaxEntries bounds the number of cached policies, evicting the least recently used one when full, 1000 by default.
+// A bound of zero or less disables caching.
+func WithMaxEntries(maxEntries int) Option {
+	return func(p *policyProvider) {
+		p.maxEntries = maxEntries
+	}
+}
+
+// GetPolicies returns cached policies where available and fetches the remaining policies from the wrapped provider
+func (p *policyProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	policies := make([]policyprovider.PolicyResponse, len(reqs))
+
+	g, ctx := errgroup.WithContext(ctx)
+	for idx, req := range reqs {
+		if policy, ok := p.cache.Get(cacheKey(req)); ok {
+			policies[idx] = policy.(policyprovider.PolicyResponse)
+			continue
+		}
+
+		g.Go(func() error {
+			policy, err := p.load(ctx, req)
+			policies[idx] = policy
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// load fetches a single policy from the wrapped provider and caches it, sharing the fetch with concurrent callers
+func (p *policyProvider) load(ctx context.Context, req policyprovider.GetPolicyRequest) (policyprovider.PolicyResponse, error) {
+	key := cacheKey(req)
+	value, err := p.fetch.Do(ctx, key, func(ctx context.Context) (any, error) {
+		responses, err := p.next.GetPolicies(ctx, []policyprovider.GetPolicyRequest{req})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(responses) != 1 {
+			return nil, fmt.Errorf("provider returned %d policies for policy %s@%s", len(responses), req.ID, req.Version)
+		}
+
+		policy := responses[0]
+		if policy.ContentHash == "" {
+			policy.ContentHash = policyprovider.HashContent(policy.Content)
+		}
+
+		p.cache.AddWithTTL(key, policy, p.ttl)
+		return policy, nil
+	})
+	if err != nil {
+		return policyprovider.PolicyResponse{}, err
+	}
+
+	return value.(policyprovider.PolicyResponse), nil
+}
+
+// cacheKey identifies the policy by its requested ID and version
+func cacheKey(req policyprovider.GetPolicyRequest) string {
+	return req.ID + "@" + req.Version
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPolicyProvider is a mock implementation of PolicyProvider
+type mockPolicyProvider struct {
+	mock.Mock
+}
+
+func (m *mockPolicyProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	args := m.Called(ctx, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]policyprovider.PolicyResponse), args.Error(1)
+}
+
+func TestPolicyProvider_GetPolicies(t *testing.T) {
+	rbac := policyprovider.GetPolicyRequest{ID: "rbac.rego", Version: "v1"}
+	orders := policyprovider.GetPolicyRequest{ID: "orders.rego", Version: "latest"}
+
+	testCases := map[string]struct {
+		setupMock      func(*mockPolicyProvider)
+		requests       []policyprovider.GetPolicyRequest
+		expectedResult []policyprovider.PolicyResponse
+		expectedError  string
+	}{
+		"should fetch each policy separately and compute missing content hashes": {
+			setupMock: func(m *mockPolicyProvider) {
+				m.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{rbac}).Return(
+					[]policyprovider.PolicyResponse{{ID: "rbac.rego", Version: "v1", Content: []byte("rbac")}}, nil,
+				).Once()
+				m.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{orders}).Return(
+					[]policyprovider.PolicyResponse{{ID: "orders.rego", Version: "v3", Content: []byte("orders"), ContentHash: "etag"}}, nil,
+				).Once()
+			},
+			requests: []policyprovider.GetPolicyRequest{rbac, orders},
+			expectedResult: []policyprovider.PolicyResponse{
+				{ID: "rbac.rego", Version: "v1", Content: []byte("rbac"), ContentHash: policyprovider.HashContent([]byte("rbac"))},
+				{ID: "orders.rego", Version: "v3", Content: []byte("orders"), ContentHash: "etag"},
+			},
+		},
+
+		"should return empty result for no requests": {
+			setupMock:      func(*mockPolicyProvider) {},
+			requests:       []policyprovider.GetPolicyRequest{},
+			expectedResult: []policyprovider.PolicyResponse{},
+		},
+
+		"should propagate provider error": {
+			setupMock: func(m *mockPolicyProvider) {
+				m.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{rbac}).
+					Return(nil, errors.New("failed to get policy rbac.rego@v1: policy not found"))
+			},
+			requests:      []policyprovider.GetPolicyRequest{rbac},
+			expectedError: "failed to get policy rbac.rego@v1: policy not found",
+		},
+
+		"should return error when provider returns unexpected number of policies": {
+			setupMock: func(m *mockPolicyProvider) {
+				m.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{rbac}).
+					Return([]policyprovider.PolicyResponse{}, nil)
+			},
+			requests:      []policyprovider.GetPolicyRequest{rbac},
+			expectedError: "provider returned 0 policies for policy rbac.rego@v1",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			next := new(mockPolicyProvider)
+			tc.setupMock(next)
+
+			result, err := New(next).GetPolicies(context.Background(), tc.requests)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+			next.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyProvider_ServesCachedPolicies(t *testing.T) {
+	latest := policyprovider.GetPolicyRequest{ID: "rbac.rego", Version: "latest"}
+
+	next := new(mockPolicyProvider)
+	next.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{latest}).
+		Return([]policyprovider.PolicyResponse{{ID: "rbac.rego", Version: "v2", Content: []byte("rbac")}}, nil).Once()
+
+	provider := New(next)
+
+	// The version resolved for "latest" is served from the cache rather than resolved again
+	for range 2 {
+		result, err := provider.GetPolicies(context.Background(), []policyprovider.GetPolicyRequest{latest})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "v2", result[0].Version)
+	}
+
+	next.AssertExpectations(t)
+}
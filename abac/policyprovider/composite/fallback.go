@@ -0,0 +1,83 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+)
+
+// FallbackOption configures the fallback PolicyProvider
+type FallbackOption func(*fallbackProvider)
+
+// fallbackProvider implements the PolicyProvider interface by retrying failed retrievals with a secondary provider
+type fallbackProvider struct {
+	primary   policyprovider.PolicyProvider
+	secondary policyprovider.PolicyProvider
+	condition func(error) bool
+	onError   func(error)
+}
+
+// WithFallbackCondition sets which errors of the primary provider are retried with the secondary provider.
+// By default, every error other than the cancellation or expiry of the request context is retried.
+func WithFallbackCondition(condition func(error) bool) FallbackOption {
+	return func(p *fallbackProvider) {
+		p.condition = condition
+	}
+}
+
+// WithFallbackErrorHandler sets the function called with the primary provider errors retried with the secondary
+// provider, such as to log that policies are served from a snapshot
+func WithFallbackErrorHandler(handler func(error)) FallbackOption {
+	return func(p *fallbackProvider) {
+		p.onError = handler
+	}
+}
+
+// NewFallback creates a PolicyProvider retrieving policies from primary, and from secondary when primary fails, such
+// as a database first and then an embed.FS snapshot of baseline policies.
+func NewFallback(
+	primary, secondary policyprovider.PolicyProvider,
+	options ...FallbackOption,
+) policyprovider.PolicyProvider {
+	p := &fallbackProvider{
+		primary:   primary,
+		secondary: secondary,
+		condition: isNotContextError,
+		onError:   func(error) {},
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// GetPolicies retrieves multiple policies from the primary provider, retrying all of them with the secondary provider
+// when the primary provider fails with an error matching the fallback condition
+func (p *fallbackProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	policies, err := p.primary.GetPolicies(ctx, reqs)
+	if err == nil || !p.condition(err) {
+		return policies, err
+	}
+
+	p.onError(err)
+
+	policies, fallbackErr := p.secondary.GetPolicies(ctx, reqs)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("failed to get policies from fallback provider: %w, after primary provider failed: %w",
+			fallbackErr, err)
+	}
+
+	return policies, nil
+}
+
+// isNotContextError reports whether the error is not caused by the cancellation or expiry of a context
+func isNotContextError(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
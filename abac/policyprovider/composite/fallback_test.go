@@ -0,0 +1,108 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackProvider_GetPolicies(t *testing.T) {
+	requests := []policyprovider.GetPolicyRequest{{ID: "rbac.rego", Version: "v1"}}
+	primaryPolicies := []policyprovider.PolicyResponse{{ID: "rbac.rego", Version: "v1", Content: []byte("primary")}}
+	snapshotPolicies := []policyprovider.PolicyResponse{{ID: "rbac.rego", Version: "v1", Content: []byte("snapshot")}}
+	primaryErr := errors.New("database connection failed")
+
+	testCases := map[string]struct {
+		options          []FallbackOption
+		primaryResult    []policyprovider.PolicyResponse
+		primaryError     error
+		secondaryResult  []policyprovider.PolicyResponse
+		secondaryError   error
+		expectFallback   bool
+		expectedResult   []policyprovider.PolicyResponse
+		expectedError    string
+		expectedReported []error
+	}{
+		"should return policies from primary provider": {
+			primaryResult:  primaryPolicies,
+			expectedResult: primaryPolicies,
+		},
+
+		"should fall back to secondary provider when primary fails": {
+			primaryError:     primaryErr,
+			secondaryResult:  snapshotPolicies,
+			expectFallback:   true,
+			expectedResult:   snapshotPolicies,
+			expectedReported: []error{primaryErr},
+		},
+
+		"should return both errors when secondary provider also fails": {
+			primaryError:   primaryErr,
+			secondaryError: errors.New("failed to get policy rbac.rego@v1: policy not found"),
+			expectFallback: true,
+			expectedError: "failed to get policies from fallback provider: failed to get policy rbac.rego@v1: policy not found, " +
+				"after primary provider failed: database connection failed",
+			expectedReported: []error{primaryErr},
+		},
+
+		"should not fall back when context is cancelled": {
+			primaryError:  context.Canceled,
+			expectedError: "context canceled",
+		},
+
+		"should not fall back when error does not match condition": {
+			options: []FallbackOption{
+				WithFallbackCondition(func(err error) bool { return errors.Is(err, policyprovider.ErrPolicyNotFound) }),
+			},
+			primaryError:  primaryErr,
+			expectedError: "database connection failed",
+		},
+
+		"should fall back when error matches condition": {
+			options: []FallbackOption{
+				WithFallbackCondition(func(err error) bool { return errors.Is(err, policyprovider.ErrPolicyNotFound) }),
+			},
+			primaryError:     policyprovider.ErrPolicyNotFound,
+			secondaryResult:  snapshotPolicies,
+			expectFallback:   true,
+			expectedResult:   snapshotPolicies,
+			expectedReported: []error{policyprovider.ErrPolicyNotFound},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			primary := new(mockPolicyProvider)
+			primary.On("GetPolicies", mock.Anything, requests).Return(tc.primaryResult, tc.primaryError)
+
+			secondary := new(mockPolicyProvider)
+			if tc.expectFallback {
+				secondary.On("GetPolicies", mock.Anything, requests).Return(tc.secondaryResult, tc.secondaryError)
+			}
+
+			var reported []error
+			options := append([]FallbackOption{WithFallbackErrorHandler(func(err error) {
+				reported = append(reported, err)
+			})}, tc.options...)
+
+			result, err := NewFallback(primary, secondary, options...).GetPolicies(context.Background(), requests)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, result)
+			}
+
+			assert.Equal(t, tc.expectedReported, reported)
+			primary.AssertExpectations(t)
+			secondary.AssertExpectations(t)
+		})
+	}
+}
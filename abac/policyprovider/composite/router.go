@@ -0,0 +1,145 @@
+package composite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"golang.org/x/sync/errgroup"
+)
+
+// Matcher reports whether a policy request is served by the provider of a route
+type Matcher func(req policyprovider.GetPolicyRequest) bool
+
+// IDPrefix matches requests for policies whose ID starts with prefix, such as "tenant-a/"
+func IDPrefix(prefix string) Matcher {
+	return func(req policyprovider.GetPolicyRequest) bool {
+		return strings.HasPrefix(req.ID, prefix)
+	}
+}
+
+// Version matches requests for the given policy version
+func Version(version string) Matcher {
+	return func(req policyprovider.GetPolicyRequest) bool {
+		return req.Version == version
+	}
+}
+
+// route pairs a matcher with the provider serving the requests it matches
+type route struct {
+	matcher  Matcher
+	provider policyprovider.PolicyProvider
+}
+
+// Option configures the routing PolicyProvider
+type Option func(*router)
+
+// router implements the PolicyProvider interface by dispatching each request to the provider of its route
+type router struct {
+	routes          []route
+	defaultProvider policyprovider.PolicyProvider
+}
+
+// WithRoute routes the requests matched by matcher to provider. Routes are tried in the order they are added.
+func WithRoute(matcher Matcher, provider policyprovider.PolicyProvider) Option {
+	return func(r *router) {
+		r.routes = append(r.routes, route{matcher: matcher, provider: provider})
+	}
+}
+
+// New creates a PolicyProvider serving each request from the provider of the first matching route, or from
+// defaultProvider when no route matches. A nil defaultProvider fails unmatched requests with ErrPolicyNotFound.
+// Each provider receives its requests in a single batch, and the batches are retrieved concurrently.
+func New(defaultProvider policyprovider.PolicyProvider, options ...Option) policyprovider.PolicyProvider {
+	r := &router{
+		routes:          make([]route, 0),
+		defaultProvider: defaultProvider,
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// GetPolicies retrieves multiple policies from their providers, returning them in request order
+func (r *router) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	// Request indexes grouped by provider, in order of first use
+	providers := make([]policyprovider.PolicyProvider, 0)
+	batches := make([][]int, 0)
+	batchOf := make(map[int]int)
+
+	for i, req := range reqs {
+		routeIndex, provider := r.route(req)
+		if provider == nil {
+			return nil, fmt.Errorf("failed to get policy %s@%s: %w, no provider route matches", req.ID, req.Version,
+				policyprovider.ErrPolicyNotFound)
+		}
+
+		batch, ok := batchOf[routeIndex]
+		if !ok {
+			batch = len(providers)
+			batchOf[routeIndex] = batch
+			providers = append(providers, provider)
+			batches = append(batches, make([]int, 0))
+		}
+
+		batches[batch] = append(batches[batch], i)
+	}
+
+	// Avoid copying requests and responses when a single provider serves them all
+	if len(providers) == 1 {
+		return providers[0].GetPolicies(ctx, reqs)
+	}
+
+	policies := make([]policyprovider.PolicyResponse, len(reqs))
+	g, ctx := errgroup.WithContext(ctx)
+
+	for batch, provider := range providers {
+		indexes := batches[batch]
+		g.Go(func() error {
+			batchReqs := make([]policyprovider.GetPolicyRequest, 0, len(indexes))
+			for _, i := range indexes {
+				batchReqs = append(batchReqs, reqs[i])
+			}
+
+			responses, err := provider.GetPolicies(ctx, batchReqs)
+			if err != nil {
+				return err
+			}
+
+			if len(responses) != len(indexes) {
+				return fmt.Errorf("provider returned %d policies for %d requests", len(responses), len(indexes))
+			}
+
+			for j, i := range indexes {
+				policies[i] = responses[j]
+			}
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// route returns the index and provider of the first route matching the request, or the default provider with the
+// index following the last route
+func (r *router) route(req policyprovider.GetPolicyRequest) (int, policyprovider.PolicyProvider) {
+	for i, rt := range r.routes {
+		if rt.matcher(req) {
+			return i, rt.provider
+		}
+	}
+
+	return len(r.routes), r.defaultProvider
+}
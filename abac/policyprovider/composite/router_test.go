@@ -0,0 +1,182 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider/filestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPolicyProvider struct {
+	mock.Mock
+}
+
+func (m *mockPolicyProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	args := m.Called(ctx, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]policyprovider.PolicyResponse), args.Error(1)
+}
+
+func TestRouter_GetPolicies(t *testing.T) {
+	builtin := filestore.NewFromFS(fstest.MapFS{
+		"v1/default.rego": &fstest.MapFile{Data: []byte("builtin default")},
+	})
+	tenant := filestore.NewFromFS(fstest.MapFS{
+		"v1/tenant-a/rbac.rego": &fstest.MapFile{Data: []byte("tenant-a rbac")},
+	})
+	draft := filestore.NewFromFS(fstest.MapFS{
+		"draft/default.rego": &fstest.MapFile{Data: []byte("draft default")},
+	})
+
+	failing := new(mockPolicyProvider)
+	failing.On("GetPolicies", mock.Anything, mock.Anything).Return(nil, errors.New("database connection failed"))
+
+	invalid := new(mockPolicyProvider)
+	invalid.On("GetPolicies", mock.Anything, mock.Anything).Return([]policyprovider.PolicyResponse{}, nil)
+
+	testCases := map[string]struct {
+		defaultProvider policyprovider.PolicyProvider
+		options         []Option
+		requests        []policyprovider.GetPolicyRequest
+		expectedResult  []policyprovider.PolicyResponse
+		expectedError   string
+	}{
+		"should route requests by ID prefix and version in request order": {
+			defaultProvider: builtin,
+			options: []Option{
+				WithRoute(IDPrefix("tenant-a/"), tenant),
+				WithRoute(Version("draft"), draft),
+			},
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "tenant-a/rbac.rego", Version: "v1"},
+				{ID: "default.rego", Version: "v1"},
+				{ID: "default.rego", Version: "draft"},
+			},
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "tenant-a/rbac.rego",
+					Version:     "v1",
+					Content:     []byte("tenant-a rbac"),
+					ContentHash: policyprovider.HashContent([]byte("tenant-a rbac")),
+				},
+				{
+					ID:          "default.rego",
+					Version:     "v1",
+					Content:     []byte("builtin default"),
+					ContentHash: policyprovider.HashContent([]byte("builtin default")),
+				},
+				{
+					ID:          "default.rego",
+					Version:     "draft",
+					Content:     []byte("draft default"),
+					ContentHash: policyprovider.HashContent([]byte("draft default")),
+				},
+			},
+		},
+
+		"should use first matching route": {
+			defaultProvider: builtin,
+			options: []Option{
+				WithRoute(Version("draft"), draft),
+				WithRoute(IDPrefix("default"), builtin),
+			},
+			requests: []policyprovider.GetPolicyRequest{{ID: "default.rego", Version: "draft"}},
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "default.rego",
+					Version:     "draft",
+					Content:     []byte("draft default"),
+					ContentHash: policyprovider.HashContent([]byte("draft default")),
+				},
+			},
+		},
+
+		"should return empty result for empty requests": {
+			defaultProvider: builtin,
+			requests:        []policyprovider.GetPolicyRequest{},
+			expectedResult:  []policyprovider.PolicyResponse{},
+		},
+
+		"should return policy not found error when no route matches without default provider": {
+			options:       []Option{WithRoute(IDPrefix("tenant-a/"), tenant)},
+			requests:      []policyprovider.GetPolicyRequest{{ID: "default.rego", Version: "v1"}},
+			expectedError: "failed to get policy default.rego@v1: policy not found, no provider route matches",
+		},
+
+		"should return error when a provider fails": {
+			defaultProvider: builtin,
+			options:         []Option{WithRoute(IDPrefix("tenant-a/"), failing)},
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "default.rego", Version: "v1"},
+				{ID: "tenant-a/rbac.rego", Version: "v1"},
+			},
+			expectedError: "database connection failed",
+		},
+
+		"should return error when a provider returns fewer policies than requested": {
+			defaultProvider: builtin,
+			options:         []Option{WithRoute(IDPrefix("tenant-a/"), invalid)},
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "default.rego", Version: "v1"},
+				{ID: "tenant-a/rbac.rego", Version: "v1"},
+			},
+			expectedError: "provider returned 0 policies for 1 requests",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := New(tc.defaultProvider, tc.options...).GetPolicies(context.Background(), tc.requests)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestRouter_GetPoliciesBatchesRequestsPerProvider(t *testing.T) {
+	tenant := new(mockPolicyProvider)
+	tenant.On("GetPolicies", mock.Anything, []policyprovider.GetPolicyRequest{
+		{ID: "tenant-a/rbac.rego", Version: "v1"},
+		{ID: "tenant-a/orders.rego", Version: "v1"},
+	}).Return([]policyprovider.PolicyResponse{
+		{ID: "tenant-a/rbac.rego", Version: "v1", Content: []byte("rbac")},
+		{ID: "tenant-a/orders.rego", Version: "v1", Content: []byte("orders")},
+	}, nil).Once()
+
+	builtin := filestore.NewFromFS(fstest.MapFS{"v1/default.rego": &fstest.MapFile{Data: []byte("default")}})
+
+	result, err := New(builtin, WithRoute(IDPrefix("tenant-a/"), tenant)).GetPolicies(
+		context.Background(),
+		[]policyprovider.GetPolicyRequest{
+			{ID: "tenant-a/rbac.rego", Version: "v1"},
+			{ID: "default.rego", Version: "v1"},
+			{ID: "tenant-a/orders.rego", Version: "v1"},
+		},
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, []policyprovider.PolicyResponse{
+		{ID: "tenant-a/rbac.rego", Version: "v1", Content: []byte("rbac")},
+		{ID: "default.rego", Version: "v1", Content: []byte("default"), ContentHash: policyprovider.HashContent([]byte("default"))},
+		{ID: "tenant-a/orders.rego", Version: "v1", Content: []byte("orders")},
+	}, result)
+	tenant.AssertExpectations(t)
+}
@@ -3,22 +3,29 @@ package filestore
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
 
 	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
 )
 
-// policyProvider implements the PolicyProvider interface using the local filesystem
+// policyProvider implements the PolicyProvider interface using a filesystem
 type policyProvider struct {
-	basePath string // Base directory where policies are stored
+	fsys fs.FS // Filesystem rooted at the directory where policies are stored
 }
 
 // New creates a new filesystem-based PolicyProvider
 // The basePath parameter specifies the root directory for policy files
 func New(basePath string) policyprovider.PolicyProvider {
+	return NewFromFS(os.DirFS(basePath))
+}
+
+// NewFromFS creates a PolicyProvider serving the policies stored as <version>/<id> in fsys, such as an embed.FS
+// snapshot of the policy directory built into the binary
+func NewFromFS(fsys fs.FS) policyprovider.PolicyProvider {
 	return &policyProvider{
-		basePath: basePath,
+		fsys: fsys,
 	}
 }
 
@@ -45,25 +52,26 @@ func (p *policyProvider) GetPolicies(ctx context.Context, reqs []policyprovider.
 
 // getPolicy retrieves a single policy from the filesystem
 func (p *policyProvider) getPolicy(req policyprovider.GetPolicyRequest) (*policyprovider.PolicyResponse, error) {
-	policyPath := filepath.Join(p.basePath, req.Version, req.ID)
+	policyPath := path.Join(req.Version, req.ID)
 
-	fileInfo, err := os.Stat(policyPath)
+	fileInfo, err := fs.Stat(p.fsys, policyPath)
 	if err != nil {
-		return nil, fmt.Errorf("policy not found: %w", err)
+		return nil, fmt.Errorf("%w: %w", policyprovider.ErrPolicyNotFound, err)
 	}
 
 	if fileInfo.IsDir() {
 		return nil, fmt.Errorf("policy path is a directory, not a file")
 	}
 
-	content, err := os.ReadFile(policyPath)
+	content, err := fs.ReadFile(p.fsys, policyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read policy: %w", err)
 	}
 
 	return &policyprovider.PolicyResponse{
-		ID:      req.ID,
-		Version: req.Version,
-		Content: content,
+		ID:          req.ID,
+		Version:     req.Version,
+		Content:     content,
+		ContentHash: policyprovider.HashContent(content),
 	}, nil
 }
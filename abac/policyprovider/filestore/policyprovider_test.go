@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
 	"github.com/stretchr/testify/assert"
@@ -32,8 +33,18 @@ func TestPolicyProvider_GetPolicies(t *testing.T) {
 			},
 			setupContext: func() context.Context { return context.Background() },
 			expectedResult: []policyprovider.PolicyResponse{
-				{ID: "policy1", Version: "v1", Content: []byte("policy1 content")},
-				{ID: "policy2", Version: "v1", Content: []byte("policy2 content")},
+				{
+					ID:          "policy1",
+					Version:     "v1",
+					Content:     []byte("policy1 content"),
+					ContentHash: policyprovider.HashContent([]byte("policy1 content")),
+				},
+				{
+					ID:          "policy2",
+					Version:     "v1",
+					Content:     []byte("policy2 content"),
+					ContentHash: policyprovider.HashContent([]byte("policy2 content")),
+				},
 			},
 		},
 
@@ -44,7 +55,12 @@ func TestPolicyProvider_GetPolicies(t *testing.T) {
 			},
 			setupContext: func() context.Context { return context.Background() },
 			expectedResult: []policyprovider.PolicyResponse{
-				{ID: "policy1", Version: "v2", Content: []byte("policy1 v2 content")},
+				{
+					ID:          "policy1",
+					Version:     "v2",
+					Content:     []byte("policy1 v2 content"),
+					ContentHash: policyprovider.HashContent([]byte("policy1 v2 content")),
+				},
 			},
 		},
 
@@ -111,6 +127,51 @@ func TestPolicyProvider_GetPolicies(t *testing.T) {
 	}
 }
 
+func TestNewFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"v1/rbac.rego": &fstest.MapFile{Data: []byte("rbac v1")},
+	}
+
+	testCases := map[string]struct {
+		requests       []policyprovider.GetPolicyRequest
+		expectedResult []policyprovider.PolicyResponse
+		expectedError  string
+	}{
+		"should retrieve policy from filesystem": {
+			requests: []policyprovider.GetPolicyRequest{{ID: "rbac.rego", Version: "v1"}},
+			expectedResult: []policyprovider.PolicyResponse{
+				{ID: "rbac.rego", Version: "v1", Content: []byte("rbac v1"), ContentHash: policyprovider.HashContent([]byte("rbac v1"))},
+			},
+		},
+
+		"should return policy not found error when policy does not exist": {
+			requests:      []policyprovider.GetPolicyRequest{{ID: "rbac.rego", Version: "v2"}},
+			expectedError: "failed to get policy rbac.rego@v2: policy not found",
+		},
+
+		"should return policy not found error when path is outside version directory": {
+			requests:      []policyprovider.GetPolicyRequest{{ID: "../rbac.rego", Version: "v1"}},
+			expectedError: "failed to get policy ../rbac.rego@v1: policy not found",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := NewFromFS(fsys).GetPolicies(context.Background(), tc.requests)
+
+			if tc.expectedError != "" {
+				require.ErrorIs(t, err, policyprovider.ErrPolicyNotFound)
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
 func setupTestDir(t *testing.T) string {
 	// Create a temporary test directory
 	tempDir, err := os.MkdirTemp("", "policy-test")
@@ -0,0 +1,341 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	defaultReloadDelay = 100 * time.Millisecond
+	policyKeySeparator = "/"
+)
+
+// Validator checks the content of a policy before it is served, for example by parsing it with the policy evaluator
+type Validator func(policy policyprovider.PolicyResponse) error
+
+// Change lists the policies added, updated or removed by a reload
+type Change struct {
+	Policies []policyprovider.GetPolicyRequest
+}
+
+// WatchingPolicyProvider is a PolicyProvider serving policies from memory, reloaded when the policy files change
+type WatchingPolicyProvider interface {
+	policyprovider.PolicyProvider
+
+	// Changes returns a channel receiving the policies changed by each reload, for invalidating evaluator caches.
+	// Changes not yet received are merged into the next one, so none are lost to a slow receiver.
+	Changes() <-chan Change
+
+	// Close stops watching the policy files and closes the Changes channel
+	Close() error
+}
+
+// watchingPolicyProvider implements WatchingPolicyProvider using an in-memory snapshot of the policy files
+type watchingPolicyProvider struct {
+	basePath    string
+	validator   Validator
+	onError     func(error)
+	reloadDelay time.Duration
+
+	mu       sync.RWMutex
+	policies map[string]policyprovider.PolicyResponse
+
+	watcher   *fsnotify.Watcher
+	changes   chan Change
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// WatchOption defines configuration options for the watching PolicyProvider
+type WatchOption func(*watchingPolicyProvider)
+
+// NewWatching creates a PolicyProvider loading the policies stored as basePath/<version>/<id> into memory.
+// The directory tree is watched for changes; on change, all policies are reloaded and the new or updated ones are
+// validated before the snapshot is swapped in. When validation fails the previous snapshot keeps being served.
+func NewWatching(basePath string, options ...WatchOption) (WatchingPolicyProvider, error) {
+	p := &watchingPolicyProvider{
+		basePath:    basePath,
+		validator:   func(policyprovider.PolicyResponse) error { return nil },
+		onError:     func(error) {},
+		reloadDelay: defaultReloadDelay,
+		policies:    make(map[string]policyprovider.PolicyResponse),
+		changes:     make(chan Change, 1),
+		done:        make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy watcher: %w", err)
+	}
+	p.watcher = watcher
+
+	if _, err := p.reload(); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	go p.watch()
+
+	return p, nil
+}
+
+// WithValidator sets the validator applied to new and updated policies before they are served
+func WithValidator(validator Validator) WatchOption {
+	return func(p *watchingPolicyProvider) {
+		p.validator = validator
+	}
+}
+
+// WithErrorHandler sets the function called when watching or reloading the policies fails
+func WithErrorHandler(handler func(error)) WatchOption {
+	return func(p *watchingPolicyProvider) {
+		p.onError = handler
+	}
+}
+
+// WithReloadDelay sets how long to wait after the last file event before reloading, 100ms by default
+func WithReloadDelay(delay time.Duration) WatchOption {
+	return func(p *watchingPolicyProvider) {
+		p.reloadDelay = delay
+	}
+}
+
+// GetPolicies retrieves multiple policies from the in-memory snapshot
+func (p *watchingPolicyProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	p.mu.RLock()
+	snapshot := p.policies
+	p.mu.RUnlock()
+
+	policies := make([]policyprovider.PolicyResponse, 0, len(reqs))
+
+	for _, req := range reqs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		policy, ok := snapshot[policyKey(req.Version, req.ID)]
+		if !ok {
+			return nil, fmt.Errorf("failed to get policy %s@%s: %w", req.ID, req.Version, policyprovider.ErrPolicyNotFound)
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// Changes returns the channel receiving the policies changed by each reload
+func (p *watchingPolicyProvider) Changes() <-chan Change {
+	return p.changes
+}
+
+// Close stops watching the policy files and waits for the watch loop to exit
+func (p *watchingPolicyProvider) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		err = p.watcher.Close()
+		<-p.done
+	})
+
+	return err
+}
+
+// watch reloads the policies once file events settle for the reload delay, until the watcher is closed
+func (p *watchingPolicyProvider) watch() {
+	defer close(p.done)
+	defer close(p.changes)
+
+	var reload <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-p.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			reload = time.After(p.reloadDelay)
+
+		case err, ok := <-p.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			p.onError(fmt.Errorf("failed to watch policies: %w", err))
+
+		case <-reload:
+			reload = nil
+
+			change, err := p.reload()
+			if err != nil {
+				p.onError(err)
+				continue
+			}
+
+			if len(change.Policies) > 0 {
+				p.notify(change)
+			}
+		}
+	}
+}
+
+// reload reads and validates the policy files, swapping them in and returning the policies changed
+func (p *watchingPolicyProvider) reload() (Change, error) {
+	policies, err := p.load()
+	if err != nil {
+		return Change{}, fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	p.mu.RLock()
+	current := p.policies
+	p.mu.RUnlock()
+
+	var changed []policyprovider.GetPolicyRequest
+	var errs []error
+
+	for key, policy := range policies {
+		if existing, ok := current[key]; ok && slices.Equal(existing.Content, policy.Content) {
+			continue
+		}
+
+		if err := p.validator(policy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid policy %s@%s: %w", policy.ID, policy.Version, err))
+			continue
+		}
+
+		changed = append(changed, policyprovider.GetPolicyRequest{ID: policy.ID, Version: policy.Version})
+	}
+
+	if len(errs) > 0 {
+		return Change{}, fmt.Errorf("failed to validate policies: %w", errors.Join(errs...))
+	}
+
+	for key, policy := range current {
+		if _, ok := policies[key]; !ok {
+			changed = append(changed, policyprovider.GetPolicyRequest{ID: policy.ID, Version: policy.Version})
+		}
+	}
+
+	p.mu.Lock()
+	p.policies = policies
+	p.mu.Unlock()
+
+	return Change{Policies: sortPolicyRequests(changed)}, nil
+}
+
+// load reads every policy file under the base path and watches the directories holding them
+func (p *watchingPolicyProvider) load() (map[string]policyprovider.PolicyResponse, error) {
+	if err := p.watcher.Add(p.basePath); err != nil {
+		return nil, fmt.Errorf("failed to watch %s: %w", p.basePath, err)
+	}
+
+	versions, err := os.ReadDir(p.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p.basePath, err)
+	}
+
+	policies := make(map[string]policyprovider.PolicyResponse)
+
+	for _, version := range versions {
+		if !version.IsDir() || isHidden(version.Name()) {
+			continue
+		}
+
+		versionPath := filepath.Join(p.basePath, version.Name())
+		if err := p.watcher.Add(versionPath); err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", versionPath, err)
+		}
+
+		files, err := os.ReadDir(versionPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", versionPath, err)
+		}
+
+		for _, file := range files {
+			// Skip directories and hidden files, such as editor swap files
+			if file.IsDir() || isHidden(file.Name()) {
+				continue
+			}
+
+			content, err := os.ReadFile(filepath.Join(versionPath, file.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read policy %s@%s: %w", file.Name(), version.Name(), err)
+			}
+
+			policies[policyKey(version.Name(), file.Name())] = policyprovider.PolicyResponse{
+				ID:          file.Name(),
+				Version:     version.Name(),
+				Content:     content,
+				ContentHash: policyprovider.HashContent(content),
+			}
+		}
+	}
+
+	return policies, nil
+}
+
+// notify sends the change, merging it with any change not yet received
+func (p *watchingPolicyProvider) notify(change Change) {
+	for {
+		select {
+		case p.changes <- change:
+			return
+		default:
+		}
+
+		select {
+		case pending := <-p.changes:
+			change = Change{Policies: mergePolicyRequests(pending.Policies, change.Policies)}
+		default:
+		}
+	}
+}
+
+// policyKey returns the snapshot key of a policy
+func policyKey(version, id string) string {
+	return version + policyKeySeparator + id
+}
+
+// isHidden reports whether a file or directory name is hidden
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// mergePolicyRequests returns the union of two lists of policy requests, sorted
+func mergePolicyRequests(a, b []policyprovider.GetPolicyRequest) []policyprovider.GetPolicyRequest {
+	merged := make([]policyprovider.GetPolicyRequest, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	merged = append(merged, b...)
+
+	return slices.Compact(sortPolicyRequests(merged))
+}
+
+// sortPolicyRequests sorts policy requests by version, then ID
+func sortPolicyRequests(reqs []policyprovider.GetPolicyRequest) []policyprovider.GetPolicyRequest {
+	slices.SortFunc(reqs, func(a, b policyprovider.GetPolicyRequest) int {
+		return strings.Compare(policyKey(a.Version, a.ID), policyKey(b.Version, b.ID))
+	})
+
+	return reqs
+}
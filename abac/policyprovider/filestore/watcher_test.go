@@ -0,0 +1,341 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testReloadDelay  = 10 * time.Millisecond
+	testChangeWait   = 2 * time.Second
+	invalidContent   = "invalid"
+	invalidPolicyErr = "policy content is invalid"
+)
+
+func TestNewWatching(t *testing.T) {
+	testCases := map[string]struct {
+		setup         func(t *testing.T) string
+		options       []WatchOption
+		expectedError string
+	}{
+		"should load policies successfully": {
+			setup: setupWatchDir,
+		},
+
+		"should return error when base path does not exist": {
+			setup:         func(*testing.T) string { return "/nonexistent/path" },
+			expectedError: "failed to load policies: failed to watch /nonexistent/path",
+		},
+
+		"should return error when policy is invalid": {
+			setup: func(t *testing.T) string {
+				dir := setupWatchDir(t)
+				writePolicy(t, dir, "v1", "policy3", invalidContent)
+				return dir
+			},
+			options:       []WatchOption{WithValidator(rejectInvalid)},
+			expectedError: "failed to validate policies: invalid policy policy3@v1: policy content is invalid",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			provider, err := NewWatching(tc.setup(t), tc.options...)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, provider)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, provider.Close())
+		})
+	}
+}
+
+func TestWatchingPolicyProvider_GetPolicies(t *testing.T) {
+	provider, err := NewWatching(setupWatchDir(t))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, provider.Close())
+	}()
+
+	testCases := map[string]struct {
+		requests        []policyprovider.GetPolicyRequest
+		setupContext    func() context.Context
+		expectedResult  []policyprovider.PolicyResponse
+		expectedError   string
+		expectedErrorIs error
+	}{
+		"should retrieve multiple policies successfully": {
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "policy1", Version: "v1"},
+				{ID: "policy1", Version: "v2"},
+			},
+			setupContext: context.Background,
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "policy1",
+					Version:     "v1",
+					Content:     []byte("policy1 content"),
+					ContentHash: policyprovider.HashContent([]byte("policy1 content")),
+				},
+				{
+					ID:          "policy1",
+					Version:     "v2",
+					Content:     []byte("policy1 v2 content"),
+					ContentHash: policyprovider.HashContent([]byte("policy1 v2 content")),
+				},
+			},
+		},
+
+		"should return error when policy does not exist": {
+			requests:        []policyprovider.GetPolicyRequest{{ID: "nonexistent", Version: "v1"}},
+			setupContext:    context.Background,
+			expectedError:   "failed to get policy nonexistent@v1: policy not found",
+			expectedErrorIs: policyprovider.ErrPolicyNotFound,
+		},
+
+		"should not serve hidden files": {
+			requests:        []policyprovider.GetPolicyRequest{{ID: ".policy1.swp", Version: "v1"}},
+			setupContext:    context.Background,
+			expectedError:   "failed to get policy .policy1.swp@v1: policy not found",
+			expectedErrorIs: policyprovider.ErrPolicyNotFound,
+		},
+
+		"should return error when context is cancelled": {
+			requests: []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v1"}},
+			setupContext: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			expectedError: "context canceled",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := provider.GetPolicies(tc.setupContext(), tc.requests)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				if tc.expectedErrorIs != nil {
+					assert.ErrorIs(t, err, tc.expectedErrorIs)
+				}
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestWatchingPolicyProvider_Reload(t *testing.T) {
+	testCases := map[string]struct {
+		update         func(t *testing.T, dir string)
+		requests       []policyprovider.GetPolicyRequest
+		expectedChange Change
+		expectedResult []policyprovider.PolicyResponse
+	}{
+		"should reload updated policy": {
+			update: func(t *testing.T, dir string) {
+				writePolicy(t, dir, "v1", "policy1", "policy1 updated content")
+			},
+			requests:       []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v1"}},
+			expectedChange: Change{Policies: []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v1"}}},
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "policy1",
+					Version:     "v1",
+					Content:     []byte("policy1 updated content"),
+					ContentHash: policyprovider.HashContent([]byte("policy1 updated content")),
+				},
+			},
+		},
+
+		"should load policy in new version directory": {
+			update: func(t *testing.T, dir string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "v3"), 0755))
+				writePolicy(t, dir, "v3", "policy1", "policy1 v3 content")
+			},
+			requests:       []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v3"}},
+			expectedChange: Change{Policies: []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v3"}}},
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "policy1",
+					Version:     "v3",
+					Content:     []byte("policy1 v3 content"),
+					ContentHash: policyprovider.HashContent([]byte("policy1 v3 content")),
+				},
+			},
+		},
+
+		"should report removed policy": {
+			update: func(t *testing.T, dir string) {
+				require.NoError(t, os.Remove(filepath.Join(dir, "v1", "policy2")))
+			},
+			requests:       []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v1"}},
+			expectedChange: Change{Policies: []policyprovider.GetPolicyRequest{{ID: "policy2", Version: "v1"}}},
+			expectedResult: []policyprovider.PolicyResponse{
+				{
+					ID:          "policy1",
+					Version:     "v1",
+					Content:     []byte("policy1 content"),
+					ContentHash: policyprovider.HashContent([]byte("policy1 content")),
+				},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := setupWatchDir(t)
+			provider, err := NewWatching(dir, WithReloadDelay(testReloadDelay))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, provider.Close())
+			}()
+
+			tc.update(t, dir)
+
+			assert.Equal(t, tc.expectedChange, waitForChange(t, provider))
+
+			result, err := provider.GetPolicies(context.Background(), tc.requests)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestWatchingPolicyProvider_ReloadInvalidPolicy(t *testing.T) {
+	dir := setupWatchDir(t)
+
+	var mu sync.Mutex
+	var reloadErrs []error
+
+	provider, err := NewWatching(
+		dir,
+		WithReloadDelay(testReloadDelay),
+		WithValidator(rejectInvalid),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reloadErrs = append(reloadErrs, err)
+		}),
+	)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, provider.Close())
+	}()
+
+	writePolicy(t, dir, "v1", "policy1", invalidContent)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reloadErrs) > 0
+	}, testChangeWait, testReloadDelay)
+
+	mu.Lock()
+	assert.ErrorContains(t, reloadErrs[0], "invalid policy policy1@v1: policy content is invalid")
+	mu.Unlock()
+
+	// The previous content keeps being served until a valid policy is written
+	result, err := provider.GetPolicies(context.Background(), []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []policyprovider.PolicyResponse{
+		{
+			ID:          "policy1",
+			Version:     "v1",
+			Content:     []byte("policy1 content"),
+			ContentHash: policyprovider.HashContent([]byte("policy1 content")),
+		},
+	}, result)
+
+	writePolicy(t, dir, "v1", "policy1", "policy1 fixed content")
+
+	assert.Equal(
+		t,
+		Change{Policies: []policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v1"}}},
+		waitForChange(t, provider),
+	)
+}
+
+func TestWatchingPolicyProvider_Close(t *testing.T) {
+	provider, err := NewWatching(setupWatchDir(t))
+	require.NoError(t, err)
+
+	require.NoError(t, provider.Close())
+	require.NoError(t, provider.Close())
+
+	_, ok := <-provider.Changes()
+	assert.False(t, ok)
+}
+
+func TestMergePolicyRequests(t *testing.T) {
+	merged := mergePolicyRequests(
+		[]policyprovider.GetPolicyRequest{{ID: "policy2", Version: "v1"}, {ID: "policy1", Version: "v2"}},
+		[]policyprovider.GetPolicyRequest{{ID: "policy1", Version: "v2"}, {ID: "policy1", Version: "v1"}},
+	)
+
+	assert.Equal(t, []policyprovider.GetPolicyRequest{
+		{ID: "policy1", Version: "v1"},
+		{ID: "policy2", Version: "v1"},
+		{ID: "policy1", Version: "v2"},
+	}, merged)
+}
+
+func setupWatchDir(t *testing.T) string {
+	dir := t.TempDir()
+
+	for _, version := range []string{"v1", "v2"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, version), 0755))
+	}
+
+	writePolicy(t, dir, "v1", "policy1", "policy1 content")
+	writePolicy(t, dir, "v1", "policy2", "policy2 content")
+	writePolicy(t, dir, "v1", ".policy1.swp", invalidContent)
+	writePolicy(t, dir, "v2", "policy1", "policy1 v2 content")
+
+	return dir
+}
+
+// writePolicy writes the policy through a hidden temporary file renamed into place, so no partial write is observed
+func writePolicy(t *testing.T, dir, version, id, content string) {
+	tempPath := filepath.Join(dir, version, "."+id+".tmp")
+	require.NoError(t, os.WriteFile(tempPath, []byte(content), 0644)) //nolint:gosec // unit test
+	require.NoError(t, os.Rename(tempPath, filepath.Join(dir, version, id)))
+}
+
+func rejectInvalid(policy policyprovider.PolicyResponse) error {
+	if string(policy.Content) == invalidContent {
+		return errors.New(invalidPolicyErr)
+	}
+
+	return nil
+}
+
+func waitForChange(t *testing.T, provider WatchingPolicyProvider) Change {
+	select {
+	case change := <-provider.Changes():
+		return change
+	case <-time.After(testChangeWait):
+		t.Fatal("timed out waiting for policy change")
+		return Change{}
+	}
+}
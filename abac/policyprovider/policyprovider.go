@@ -1,6 +1,39 @@
 package policyprovider
 
-import "context"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrSignatureMissing is returned when a policy required to be signed has no signature
+	ErrSignatureMissing = errors.New("policy signature is missing")
+
+	// ErrSignatureInvalid is returned when a policy signature fails verification
+	ErrSignatureInvalid = errors.New("policy signature is invalid")
+
+	// ErrPolicyNotFound is returned when a requested policy version is not stored
+	ErrPolicyNotFound = errors.New("policy not found")
+
+	// ErrPolicyVersionExists is returned when creating a policy version that is already stored
+	ErrPolicyVersionExists = errors.New("policy version already exists")
+
+	// ErrNoPreviousVersion is returned when rolling back a policy that has no previously active version
+	ErrNoPreviousVersion = errors.New("policy has no previously active version")
+)
+
+// PolicyStatus represents the lifecycle status of a stored policy version
+type PolicyStatus string
+
+const (
+	PolicyStatusDraft    PolicyStatus = "draft"    // Stored, but not served as the active version
+	PolicyStatusActive   PolicyStatus = "active"   // Served as the active version of the policy
+	PolicyStatusArchived PolicyStatus = "archived" // Superseded or retired
+)
 
 // GetPolicyRequest represents a request to retrieve a specific policy
 type GetPolicyRequest struct {
@@ -10,9 +43,85 @@ type GetPolicyRequest struct {
 
 // PolicyResponse contains a policy's metadata and content
 type PolicyResponse struct {
+	ID          string
+	Version     string
+	Content     []byte
+	ContentHash string // Hex encoded SHA-256 hash of the content, empty if the provider does not compute it
+}
+
+// PolicyVersion describes a stored version of a policy, without its content
+type PolicyVersion struct {
+	ID          string
+	Version     string
+	Status      PolicyStatus
+	ContentHash string // Hex encoded SHA-256 hash of the content
+	CreatedAt   time.Time
+	ActivatedAt *time.Time // When the version was last made active, nil if it never was
+	Target      PolicyTarget
+}
+
+// AttributeCategory identifies the part of a decision request holding an attribute
+type AttributeCategory string
+
+const (
+	AttributeCategorySubject     AttributeCategory = "subject"
+	AttributeCategoryResource    AttributeCategory = "resource"
+	AttributeCategoryAction      AttributeCategory = "action"
+	AttributeCategoryEnvironment AttributeCategory = "environment"
+)
+
+// AttributeMatch is a predicate on a request attribute, satisfied when the attribute is present and, if values are
+// given, equal to one of them. A list attribute is satisfied when any of its elements is.
+type AttributeMatch struct {
+	Category  AttributeCategory `json:"category"`
+	Attribute string            `json:"attribute"`
+	Values    []any             `json:"values,omitempty"`
+}
+
+// PolicyTarget declares the decision requests a policy applies to. Every non-empty field must match the request, so
+// an empty target applies to every request.
+type PolicyTarget struct {
+	SubjectTypes  []string         `json:"subjectTypes,omitempty"`
+	ResourceTypes []string         `json:"resourceTypes,omitempty"`
+	ActionIDs     []string         `json:"actionIds,omitempty"`
+	Attributes    []AttributeMatch `json:"attributes,omitempty"`
+}
+
+// Validate checks that every attribute predicate names a known category and an attribute
+func (t PolicyTarget) Validate() error {
+	for idx, match := range t.Attributes {
+		switch match.Category {
+		case AttributeCategorySubject, AttributeCategoryResource, AttributeCategoryAction, AttributeCategoryEnvironment:
+		default:
+			return fmt.Errorf("attribute match at index %d has unknown category %q", idx, match.Category)
+		}
+
+		if match.Attribute == "" {
+			return fmt.Errorf("attribute match at index %d has no attribute", idx)
+		}
+	}
+
+	return nil
+}
+
+// PolicyMetadata describes a policy served by a provider and the decision requests it applies to
+type PolicyMetadata struct {
 	ID      string
 	Version string
-	Content []byte
+	Target  PolicyTarget
+}
+
+// PolicyLister is implemented by PolicyProviders able to enumerate the policies they serve, so that the policies
+// applicable to a request can be resolved from their targets
+type PolicyLister interface {
+	// ListPolicies returns the metadata of the policies to consider for decisions, such as the active version of each
+	ListPolicies(ctx context.Context) ([]PolicyMetadata, error)
+}
+
+// VerificationKey is a public key used to verify policy signatures
+type VerificationKey struct {
+	Algorithm string // JWS algorithm of signatures made with the key, such as RS256 or ES256
+	PublicKey string // PEM encoded public key
 }
 
 // PolicyProvider defines the interface for retrieving policies
@@ -21,3 +130,34 @@ type PolicyProvider interface {
 	// Returns policy responses for each request or an error if retrieval fails
 	GetPolicies(ctx context.Context, reqs []GetPolicyRequest) ([]PolicyResponse, error)
 }
+
+// WritablePolicyProvider extends PolicyProvider with the operations of a Policy Administration Point, managing the
+// versions of each policy and which one of them is active
+type WritablePolicyProvider interface {
+	PolicyProvider
+
+	// CreatePolicy stores a new version of a policy with the given target and status, atomically archiving the
+	// previously active version when the status is active
+	// Returns ErrPolicyVersionExists if the version is already stored
+	CreatePolicy(ctx context.Context, id, version string, content []byte, target PolicyTarget, status PolicyStatus) error
+
+	// ActivatePolicy makes a version the active version of a policy, archiving the previously active version
+	ActivatePolicy(ctx context.Context, id, version string) error
+
+	// RollbackPolicy reactivates the version that was active before the current active version and returns it
+	// Returns ErrNoPreviousVersion if there is no such version
+	RollbackPolicy(ctx context.Context, id string) (*PolicyVersion, error)
+
+	// ArchivePolicy retires a version, so it is neither served as active nor a rollback target
+	ArchivePolicy(ctx context.Context, id, version string) error
+
+	// ListPolicyVersions returns the versions of a policy, most recently created first
+	// Returns an empty list for an unknown policy
+	ListPolicyVersions(ctx context.Context, id string) ([]PolicyVersion, error)
+}
+
+// HashContent returns the hex encoded SHA-256 hash of policy content, as reported in PolicyResponse.ContentHash
+func HashContent(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,166 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Suffix is appended to a policy ID to get the ID of its detached signature, such as "rbac.rego.sig"
+const Suffix = ".sig"
+
+// jwsParts is the number of dot separated parts of a compact JWS
+const jwsParts = 3
+
+// verificationKey is a parsed public key and the signing method of signatures made with it
+type verificationKey struct {
+	method    jwt.SigningMethod
+	publicKey any
+}
+
+// jwsHeader is the protected header of a detached signature
+type jwsHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// policyProvider implements the PolicyProvider interface by verifying the policies retrieved from another provider
+type policyProvider struct {
+	next policyprovider.PolicyProvider
+	keys map[string]verificationKey
+}
+
+// New creates a PolicyProvider requiring every policy retrieved from next to be signed by one of the keys.
+// Signatures are compact JWS with a detached payload (RFC 7515, Appendix F) over the policy content, retrieved from
+// next under the policy ID with Suffix appended, such as the file "v1/rbac.rego.sig" of a filestore provider. The "kid"
+// header selects the verification key. GetPolicies fails with ErrSignatureMissing when next reports a signature with
+// ErrPolicyNotFound and with ErrSignatureInvalid when verification fails; other errors from next are returned as is.
+func New(next policyprovider.PolicyProvider, keys map[string]policyprovider.VerificationKey) (policyprovider.PolicyProvider, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one verification key is required")
+	}
+
+	parsed := make(map[string]verificationKey, len(keys))
+	for id, key := range keys {
+		parsedKey, err := parseKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification key %q: %w", id, err)
+		}
+
+		parsed[id] = parsedKey
+	}
+
+	return &policyProvider{
+		next: next,
+		keys: parsed,
+	}, nil
+}
+
+// GetPolicies retrieves multiple policies and their signatures, failing unless every signature is valid
+func (p *policyProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	policies, err := p.next.GetPolicies(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureReqs := make([]policyprovider.GetPolicyRequest, 0, len(policies))
+	for _, policy := range policies {
+		signatureReqs = append(signatureReqs, policyprovider.GetPolicyRequest{ID: policy.ID + Suffix, Version: policy.Version})
+	}
+
+	signatures, err := p.next.GetPolicies(ctx, signatureReqs)
+	if errors.Is(err, policyprovider.ErrPolicyNotFound) {
+		return nil, fmt.Errorf("%w: %w", policyprovider.ErrSignatureMissing, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signatures) != len(policies) {
+		return nil, fmt.Errorf("%w: expected %d signatures, got %d", policyprovider.ErrSignatureMissing, len(policies), len(signatures))
+	}
+
+	for i, policy := range policies {
+		if err := p.verify(policy.Content, signatures[i].Content); err != nil {
+			return nil, fmt.Errorf("failed to verify policy %s@%s: %w", policy.ID, policy.Version, err)
+		}
+	}
+
+	return policies, nil
+}
+
+// verify checks the detached JWS signature over the policy content
+func (p *policyProvider) verify(content, signature []byte) error {
+	parts := strings.Split(string(bytes.TrimSpace(signature)), ".")
+	if len(parts) != jwsParts || parts[1] != "" {
+		return fmt.Errorf("%w: not a compact JWS with a detached payload", policyprovider.ErrSignatureInvalid)
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("%w: failed to decode header: %w", policyprovider.ErrSignatureInvalid, err)
+	}
+
+	var header jwsHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("%w: failed to parse header: %w", policyprovider.ErrSignatureInvalid, err)
+	}
+
+	key, ok := p.keys[header.KeyID]
+	if !ok {
+		return fmt.Errorf("%w: unknown key ID %q", policyprovider.ErrSignatureInvalid, header.KeyID)
+	}
+
+	// The algorithm is fixed by the key, so a signature cannot downgrade it
+	if header.Algorithm != key.method.Alg() {
+		return fmt.Errorf("%w: algorithm %q does not match key %q", policyprovider.ErrSignatureInvalid, header.Algorithm, header.KeyID)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: failed to decode signature: %w", policyprovider.ErrSignatureInvalid, err)
+	}
+
+	signingString := parts[0] + "." + base64.RawURLEncoding.EncodeToString(content)
+	if err := key.method.Verify(signingString, sig, key.publicKey); err != nil {
+		return fmt.Errorf("%w: %w", policyprovider.ErrSignatureInvalid, err)
+	}
+
+	return nil
+}
+
+// parseKey parses the PEM encoded public key for the signing method of its algorithm
+func parseKey(key policyprovider.VerificationKey) (verificationKey, error) {
+	method := jwt.GetSigningMethod(key.Algorithm)
+
+	var publicKey any
+	var err error
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		publicKey, err = jwt.ParseRSAPublicKeyFromPEM([]byte(key.PublicKey))
+	case *jwt.SigningMethodECDSA:
+		publicKey, err = jwt.ParseECPublicKeyFromPEM([]byte(key.PublicKey))
+	case *jwt.SigningMethodEd25519:
+		publicKey, err = jwt.ParseEdPublicKeyFromPEM([]byte(key.PublicKey))
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported algorithm %q", key.Algorithm)
+	}
+
+	if err != nil {
+		return verificationKey{}, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return verificationKey{method: method, publicKey: publicKey}, nil
+}
@@ -0,0 +1,225 @@
+package signature
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider/filestore"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = "package rbac\n\ndefault allow := false\n"
+
+func TestNew(t *testing.T) {
+	_, publicKey := newTestKeyPair(t)
+
+	testCases := map[string]struct {
+		keys          map[string]policyprovider.VerificationKey
+		expectedError string
+	}{
+		"should create provider successfully": {
+			keys: map[string]policyprovider.VerificationKey{"platform": {Algorithm: "EdDSA", PublicKey: publicKey}},
+		},
+
+		"should return error when no keys are given": {
+			keys:          map[string]policyprovider.VerificationKey{},
+			expectedError: "at least one verification key is required",
+		},
+
+		"should return error when algorithm is not asymmetric": {
+			keys:          map[string]policyprovider.VerificationKey{"platform": {Algorithm: "HS256", PublicKey: publicKey}},
+			expectedError: `invalid verification key "platform": unsupported algorithm "HS256"`,
+		},
+
+		"should return error when public key does not match algorithm": {
+			keys:          map[string]policyprovider.VerificationKey{"platform": {Algorithm: "RS256", PublicKey: publicKey}},
+			expectedError: `invalid verification key "platform": failed to parse public key`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			provider, err := New(filestore.New(t.TempDir()), tc.keys)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, provider)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, provider)
+		})
+	}
+}
+
+func TestPolicyProvider_GetPolicies(t *testing.T) {
+	privateKey, publicKey := newTestKeyPair(t)
+	otherPrivateKey, _ := newTestKeyPair(t)
+
+	keys := map[string]policyprovider.VerificationKey{"platform": {Algorithm: "EdDSA", PublicKey: publicKey}}
+	request := []policyprovider.GetPolicyRequest{{ID: "rbac.rego", Version: "v1"}}
+
+	testCases := map[string]struct {
+		files          map[string]string
+		signatureErr   error
+		expectedResult []policyprovider.PolicyResponse
+		expectedError  error
+		errorMessage   string
+	}{
+		"should return policy with valid signature": {
+			files: map[string]string{
+				"rbac.rego":     testPolicy,
+				"rbac.rego.sig": sign(t, testPolicy, "EdDSA", "platform", privateKey) + "\n",
+			},
+			expectedResult: []policyprovider.PolicyResponse{
+				{ID: "rbac.rego", Version: "v1", Content: []byte(testPolicy), ContentHash: policyprovider.HashContent([]byte(testPolicy))},
+			},
+		},
+
+		"should return provider error when policy does not exist": {
+			files:        map[string]string{},
+			errorMessage: "failed to get policy rbac.rego@v1: policy not found",
+		},
+
+		"should return missing signature error when signature does not exist": {
+			files:         map[string]string{"rbac.rego": testPolicy},
+			expectedError: policyprovider.ErrSignatureMissing,
+		},
+
+		"should return signature retrieval error when it is not a missing signature": {
+			files:        map[string]string{"rbac.rego": testPolicy},
+			signatureErr: errors.New("connection refused"),
+			errorMessage: "connection refused",
+		},
+
+		"should return invalid signature error when content is tampered": {
+			files: map[string]string{
+				"rbac.rego":     testPolicy + "allow := true\n",
+				"rbac.rego.sig": sign(t, testPolicy, "EdDSA", "platform", privateKey),
+			},
+			expectedError: policyprovider.ErrSignatureInvalid,
+		},
+
+		"should return invalid signature error when signed by another key": {
+			files: map[string]string{
+				"rbac.rego":     testPolicy,
+				"rbac.rego.sig": sign(t, testPolicy, "EdDSA", "platform", otherPrivateKey),
+			},
+			expectedError: policyprovider.ErrSignatureInvalid,
+		},
+
+		"should return invalid signature error when key ID is unknown": {
+			files: map[string]string{
+				"rbac.rego":     testPolicy,
+				"rbac.rego.sig": sign(t, testPolicy, "EdDSA", "unknown", privateKey),
+			},
+			expectedError: policyprovider.ErrSignatureInvalid,
+		},
+
+		"should return invalid signature error when algorithm does not match key": {
+			files: map[string]string{
+				"rbac.rego":     testPolicy,
+				"rbac.rego.sig": sign(t, testPolicy, "ES256", "platform", privateKey),
+			},
+			expectedError: policyprovider.ErrSignatureInvalid,
+		},
+
+		"should return invalid signature error when payload is attached": {
+			files: map[string]string{
+				"rbac.rego":     testPolicy,
+				"rbac.rego.sig": "e30.e30.e30",
+			},
+			expectedError: policyprovider.ErrSignatureInvalid,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "v1"), 0755))
+			for id, content := range tc.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "v1", id), []byte(content), 0600))
+			}
+
+			var next policyprovider.PolicyProvider = filestore.New(dir)
+			if tc.signatureErr != nil {
+				next = &failingSignatureProvider{PolicyProvider: next, err: tc.signatureErr}
+			}
+
+			provider, err := New(next, keys)
+			require.NoError(t, err)
+
+			result, err := provider.GetPolicies(context.Background(), request)
+
+			switch {
+			case tc.expectedError != nil:
+				require.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			case tc.errorMessage != "":
+				require.ErrorContains(t, err, tc.errorMessage)
+				assert.NotErrorIs(t, err, policyprovider.ErrSignatureMissing)
+				assert.Nil(t, result)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+// failingSignatureProvider fails signature requests with err, serving other requests from the wrapped provider
+type failingSignatureProvider struct {
+	policyprovider.PolicyProvider
+	err error
+}
+
+func (p *failingSignatureProvider) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	if len(reqs) > 0 && strings.HasSuffix(reqs[0].ID, Suffix) {
+		return nil, p.err
+	}
+
+	return p.PolicyProvider.GetPolicies(ctx, reqs)
+}
+
+// sign creates a compact JWS with a detached payload over the content, declaring the algorithm and key ID in its header
+func sign(t *testing.T, content, algorithm, keyID string, privateKey ed25519.PrivateKey) string {
+	header, err := json.Marshal(map[string]string{"alg": algorithm, "kid": keyID})
+	require.NoError(t, err)
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+	signingString := encodedHeader + "." + base64.RawURLEncoding.EncodeToString([]byte(content))
+
+	sig, err := jwt.SigningMethodEdDSA.Sign(signingString, privateKey)
+	require.NoError(t, err)
+
+	return encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// newTestKeyPair generates an Ed25519 key pair, returning the private key and the PEM encoded public key
+func newTestKeyPair(t *testing.T) (ed25519.PrivateKey, string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	return privateKey, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
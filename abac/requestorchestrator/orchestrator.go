@@ -0,0 +1,524 @@
+package requestorchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// EnrichedSubject is the subject of an access request with the attributes fetched for it
+type EnrichedSubject struct {
+	Subject
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// EnrichedResource is the resource of an access request with the attributes fetched for it
+type EnrichedResource struct {
+	Resource
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// EnrichedAccessRequest is an access request with the info fetched for it so far
+type EnrichedAccessRequest struct {
+	Subject  EnrichedSubject
+	Action   Action
+	Resource EnrichedResource
+
+	// Environment holds the environment attributes of the access request and the info fetched for the analysers'
+	// requests in previous rounds
+	Environment map[string]any
+
+	// MissingAttributes lists the attributes the last decision reported missing, for analysers to request
+	MissingAttributes []decisionmaker.AttributeDesignator
+}
+
+// InfoAnalyser defines the interface for components that determine the additional info needed to decide a request
+type InfoAnalyser interface {
+	// AnalyseInfoRequirements returns the info requests to fetch for the enriched access request, if any.
+	AnalyseInfoRequirements(ctx context.Context, req *EnrichedAccessRequest) ([]infoprovider.GetInfoRequest, error)
+}
+
+// InfoTypeFunc returns the info type under which the attributes of a subject or resource of the given type are
+// fetched, or an empty string to not fetch them
+type InfoTypeFunc func(entityType string) string
+
+// InfoTypes returns an InfoTypeFunc mapping subject or resource types to info types, not fetching the attributes of
+// unmapped types
+func InfoTypes(infoTypes map[string]string) InfoTypeFunc {
+	return func(entityType string) string {
+		return infoTypes[entityType]
+	}
+}
+
+// sameInfoType is the default InfoTypeFunc, fetching attributes under the subject or resource type
+func sameInfoType(entityType string) string {
+	return entityType
+}
+
+const defaultMaxRounds = 1
+
+// requestOrchestrator implements the RequestOrchestrator interface, acting as the context handler between the policy
+// enforcement point, the info providers and the decision maker
+type requestOrchestrator struct {
+	infoAnalysers    []InfoAnalyser
+	infoProvider     infoprovider.InfoProvider
+	decisionMaker    decisionmaker.DecisionMaker
+	subjectInfoType  InfoTypeFunc
+	resourceInfoType InfoTypeFunc
+	maxRounds        int
+	timeout          time.Duration
+	schemas          map[string]infoprovider.Schema
+}
+
+// Option defines configuration options for the request orchestrator
+type Option func(*requestOrchestrator)
+
+// NewRequestOrchestrator creates a RequestOrchestrator enriching access requests with subject and resource attributes
+// and the additional info requested by its analysers, all fetched from the info provider, before asking the decision
+// maker for a decision. Subject and resource attributes are fetched with their ID as params, under their type as info
+// type unless configured otherwise; NewRouter in the infoprovider package routes each info type to its own provider.
+func NewRequestOrchestrator(
+	infoProvider infoprovider.InfoProvider,
+	decisionMaker decisionmaker.DecisionMaker,
+	options ...Option,
+) RequestOrchestrator {
+	o := &requestOrchestrator{
+		infoAnalysers:    make([]InfoAnalyser, 0),
+		infoProvider:     infoProvider,
+		decisionMaker:    decisionMaker,
+		subjectInfoType:  sameInfoType,
+		resourceInfoType: sameInfoType,
+		maxRounds:        defaultMaxRounds,
+		schemas:          make(map[string]infoprovider.Schema),
+	}
+
+	for _, option := range options {
+		option(o)
+	}
+
+	return o
+}
+
+// WithInfoAnalysers registers analysers determining the additional info to fetch for a request
+func WithInfoAnalysers(analysers ...InfoAnalyser) Option {
+	return func(o *requestOrchestrator) {
+		o.infoAnalysers = append(o.infoAnalysers, analysers...)
+	}
+}
+
+// WithSubjectInfoType sets the info type under which subject attributes are fetched
+func WithSubjectInfoType(infoType InfoTypeFunc) Option {
+	return func(o *requestOrchestrator) {
+		o.subjectInfoType = infoType
+	}
+}
+
+// WithResourceInfoType sets the info type under which resource attributes are fetched
+func WithResourceInfoType(infoType InfoTypeFunc) Option {
+	return func(o *requestOrchestrator) {
+		o.resourceInfoType = infoType
+	}
+}
+
+// WithMaxRounds bounds how many rounds of additional info are fetched for a request, one by default. Each round runs
+// the analysers on the info fetched so far and fetches what they newly request, so chains such as role to permission
+// to delegation can be followed one hop per round.
+func WithMaxRounds(rounds int) Option {
+	return func(o *requestOrchestrator) {
+		o.maxRounds = max(rounds, 1)
+	}
+}
+
+// WithTimeout bounds the total time spent evaluating a request, across all rounds
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *requestOrchestrator) {
+		o.timeout = timeout
+	}
+}
+
+// WithSchemas sets the attribute schemas of info types. The info fetched under an info type with a schema is validated
+// and coerced to it, and a violation makes the decision Indeterminate without asking the decision maker.
+func WithSchemas(schemas map[string]infoprovider.Schema) Option {
+	return func(o *requestOrchestrator) {
+		maps.Copy(o.schemas, schemas)
+	}
+}
+
+// EvaluateAccess processes an access request through enrichment, analysis, and decision-making.
+// Analysers are run again on the fetched info until they request nothing new or the round limit is reached, and a
+// decision reporting missing attributes is made again once the analysers have fetched info for them.
+func (o *requestOrchestrator) EvaluateAccess(ctx context.Context, req *AccessRequest) (*AccessResponse, error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	var schemaErr *infoSchemaError
+
+	enrichedReq, err := o.enrichAccessRequest(ctx, req)
+	if errors.As(err, &schemaErr) {
+		return schemaErr.toAccessResponse(), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to enrich request: %w", err)
+	}
+
+	state := &roundState{fetched: make(map[string]bool)}
+
+	var resp *decisionmaker.DecisionResponse
+	for {
+		fetched, err := o.gatherInfo(ctx, enrichedReq, state)
+		if errors.As(err, &schemaErr) {
+			return schemaErr.toAccessResponse(), nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Nothing new was learned about the attributes the last decision reported missing
+		if resp != nil && !fetched {
+			break
+		}
+
+		resp, err = o.decisionMaker.MakeDecision(ctx, createDecisionRequest(enrichedReq))
+		if err != nil {
+			return nil, fmt.Errorf("failed to make decision: %w", err)
+		}
+
+		if resp.Status == nil || resp.Status.Code != decisionmaker.StatusMissingAttribute || state.rounds >= o.maxRounds {
+			break
+		}
+
+		enrichedReq.MissingAttributes = resp.Status.MissingAttributes
+	}
+
+	return toAccessResponse(resp), nil
+}
+
+// roundState tracks the rounds of additional info fetched for a request
+type roundState struct {
+	rounds  int
+	fetched map[string]bool
+}
+
+// pending returns the info requests not fetched in previous rounds, marking them as fetched
+func (s *roundState) pending(infoReqs []infoprovider.GetInfoRequest) []infoprovider.GetInfoRequest {
+	pending := make([]infoprovider.GetInfoRequest, 0, len(infoReqs))
+	for _, infoReq := range infoReqs {
+		key := fmt.Sprintf("%s|%v|%v", infoReq.InfoType, infoReq.Params, infoReq.Context)
+		if s.fetched[key] {
+			continue
+		}
+
+		s.fetched[key] = true
+		pending = append(pending, infoReq)
+	}
+
+	return pending
+}
+
+// gatherInfo runs the analysers and fetches the info they newly request, round after round, until they request
+// nothing new or the round limit is reached. It reports whether any info was fetched.
+func (o *requestOrchestrator) gatherInfo(ctx context.Context, req *EnrichedAccessRequest, state *roundState) (bool, error) {
+	fetched := false
+
+	for state.rounds < o.maxRounds {
+		infoReqs, err := o.analyseInfoRequirements(ctx, req)
+		if err != nil {
+			return false, fmt.Errorf("failed to analyze requirements: %w", err)
+		}
+
+		pending := state.pending(infoReqs)
+		if len(pending) == 0 {
+			break
+		}
+
+		additionalInfo, err := o.getAdditionalInfo(ctx, pending)
+		if err != nil {
+			return false, fmt.Errorf("failed to get additional info: %w", err)
+		}
+
+		for key := range additionalInfo {
+			if _, ok := req.Environment[key]; ok {
+				return false, fmt.Errorf("failed to get additional info: duplicate info for %s", key)
+			}
+		}
+
+		maps.Copy(req.Environment, additionalInfo)
+		state.rounds++
+		fetched = true
+	}
+
+	return fetched, nil
+}
+
+// enrichAccessRequest fetches basic subject and resource attributes in parallel
+func (o *requestOrchestrator) enrichAccessRequest(ctx context.Context, req *AccessRequest) (*EnrichedAccessRequest, error) {
+	environment := maps.Clone(req.Environment)
+	if environment == nil {
+		environment = make(map[string]any)
+	}
+
+	enrichedReq := &EnrichedAccessRequest{
+		Subject: EnrichedSubject{
+			Subject:    req.Subject,
+			Attributes: make(map[string]any),
+		},
+		Action: req.Action,
+		Resource: EnrichedResource{
+			Resource:   req.Resource,
+			Attributes: make(map[string]any),
+		},
+		Environment: environment,
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	// Fetch subject attributes
+	g.Go(func() error {
+		attributes, err := o.getEntityInfo(ctx, policyprovider.AttributeCategorySubject, o.subjectInfoType(req.Subject.Type), req.Subject.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get subject info: %w", err)
+		}
+
+		enrichedReq.Subject.Attributes = attributes
+		return nil
+	})
+
+	// Fetch resource attributes
+	g.Go(func() error {
+		attributes, err := o.getEntityInfo(ctx, policyprovider.AttributeCategoryResource, o.resourceInfoType(req.Resource.Type), req.Resource.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get resource info: %w", err)
+		}
+
+		enrichedReq.Resource.Attributes = attributes
+		return nil
+	})
+
+	return enrichedReq, g.Wait()
+}
+
+// getEntityInfo fetches the attributes of the entity with the given ID, or none when it has no info type
+func (o *requestOrchestrator) getEntityInfo(
+	ctx context.Context,
+	category policyprovider.AttributeCategory,
+	infoType, id string,
+) (map[string]any, error) {
+	if infoType == "" {
+		return make(map[string]any), nil
+	}
+
+	resp, err := o.infoProvider.GetInfo(ctx, &infoprovider.GetInfoRequest{
+		InfoType: infoType,
+		Params:   id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return o.validateInfo(category, infoType, resp.Info)
+}
+
+// validateInfo validates and coerces the info fetched under the info type to its schema, if it has one
+func (o *requestOrchestrator) validateInfo(
+	category policyprovider.AttributeCategory,
+	infoType string,
+	info map[string]any,
+) (map[string]any, error) {
+	schema, ok := o.schemas[infoType]
+	if !ok {
+		return info, nil
+	}
+
+	coerced, err := schema.Validate(info)
+	if err != nil {
+		var schemaErr *infoprovider.SchemaError
+		if errors.As(err, &schemaErr) {
+			return nil, &infoSchemaError{category: category, infoType: infoType, err: schemaErr}
+		}
+
+		return nil, err
+	}
+
+	return coerced, nil
+}
+
+// analyseInfoRequirements collects additional info requirements from all analyzers
+func (o *requestOrchestrator) analyseInfoRequirements(
+	ctx context.Context,
+	req *EnrichedAccessRequest,
+) ([]infoprovider.GetInfoRequest, error) {
+	var results []infoprovider.GetInfoRequest
+
+	for _, analyser := range o.infoAnalysers {
+		reqs, err := analyser.AnalyseInfoRequirements(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("analyser failed: %w", err)
+		}
+		results = append(results, reqs...)
+	}
+
+	return results, nil
+}
+
+// getAdditionalInfo fetches additional info in parallel and returns a consolidated result
+func (o *requestOrchestrator) getAdditionalInfo(ctx context.Context, infoReqs []infoprovider.GetInfoRequest) (map[string]any, error) {
+	if len(infoReqs) == 0 {
+		return make(map[string]any), nil
+	}
+
+	result := make(map[string]any)
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+
+	for idx := range infoReqs {
+		req := infoReqs[idx]
+		g.Go(func() error {
+			resp, err := o.infoProvider.GetInfo(ctx, &req)
+			if err != nil {
+				return fmt.Errorf("failed to get info for %s: %w", req.Params, err)
+			}
+
+			info, err := o.validateInfo(policyprovider.AttributeCategoryEnvironment, req.InfoType, resp.Info)
+			if err != nil {
+				return fmt.Errorf("failed to get info for %s: %w", req.Params, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for k, v := range info {
+				if _, ok := result[k]; ok {
+					return fmt.Errorf("duplicate info for %s", k)
+				}
+
+				result[k] = v
+			}
+
+			return nil
+		})
+	}
+
+	return result, g.Wait()
+}
+
+// infoSchemaError reports fetched info violating the schema of its info type, identifying the request attribute
+// category it was fetched for
+type infoSchemaError struct {
+	category policyprovider.AttributeCategory
+	infoType string
+	err      *infoprovider.SchemaError
+}
+
+// Error implements the error interface
+func (e *infoSchemaError) Error() string {
+	return fmt.Sprintf("%s info of type %s violates its schema: %v", e.category, e.infoType, e.err)
+}
+
+// Unwrap returns the underlying schema error
+func (e *infoSchemaError) Unwrap() error {
+	return e.err
+}
+
+// toAccessResponse converts the violation to an Indeterminate access response, with an AttributeMissing status listing
+// the missing required attributes, or an InvalidRequest status when attributes are only invalid
+func (e *infoSchemaError) toAccessResponse() *AccessResponse {
+	status := Status{Code: StatusInvalidRequest, Message: e.Error()}
+	if len(e.err.Missing) > 0 {
+		status.Code = StatusMissingAttribute
+		status.MissingAttributes = make([]decisionmaker.AttributeDesignator, 0, len(e.err.Missing))
+		for _, name := range e.err.Missing {
+			status.MissingAttributes = append(status.MissingAttributes, decisionmaker.AttributeDesignator{
+				Category: e.category,
+				Path:     name,
+			})
+		}
+	}
+
+	return &AccessResponse{
+		RequestID:          uuid.New(),
+		Decision:           Indeterminate,
+		Status:             status,
+		EvaluatedAt:        time.Now(),
+		PolicyIdReferences: make([]PolicyIdReference, 0),
+	}
+}
+
+// createDecisionRequest converts enriched request to decision request format, with the fetched info as environment
+func createDecisionRequest(req *EnrichedAccessRequest) *decisionmaker.DecisionRequest {
+	return &decisionmaker.DecisionRequest{
+		RequestID: uuid.New(),
+		Subject: decisionmaker.Subject{
+			ID:         req.Subject.ID,
+			Type:       req.Subject.Type,
+			Attributes: req.Subject.Attributes,
+		},
+		Action: decisionmaker.Action{
+			ID: req.Action.ID,
+		},
+		Resource: decisionmaker.Resource{
+			ID:         req.Resource.ID,
+			Type:       req.Resource.Type,
+			Attributes: req.Resource.Attributes,
+		},
+		Environment: maps.Clone(req.Environment),
+	}
+}
+
+// toAccessResponse converts decision response to access response format
+func toAccessResponse(resp *decisionmaker.DecisionResponse) *AccessResponse {
+	result := &AccessResponse{
+		RequestID: resp.RequestID,
+		Decision:  Decision(resp.Decision),
+		Status: Status{
+			Code:              StatusCode(resp.Status.Code),
+			Message:           resp.Status.Message,
+			MissingAttributes: resp.Status.MissingAttributes,
+		},
+		EvaluatedAt:        resp.EvaluatedAt,
+		PolicyIdReferences: make([]PolicyIdReference, 0, len(resp.PolicyIdReferences)),
+	}
+
+	// Convert obligations if present
+	if len(resp.Obligations) > 0 {
+		result.Obligations = make([]Obligation, len(resp.Obligations))
+		for i, obligation := range resp.Obligations {
+			result.Obligations[i] = Obligation{
+				ID:         obligation.ID,
+				Attributes: obligation.Attributes,
+			}
+		}
+	}
+
+	// Convert advice if present
+	if len(resp.Advice) > 0 {
+		result.Advices = make([]Advice, len(resp.Advice))
+		for i, advice := range resp.Advice {
+			result.Advices[i] = Advice{
+				ID:         advice.ID,
+				Attributes: advice.Attributes,
+			}
+		}
+	}
+
+	for _, policyIdReference := range resp.PolicyIdReferences {
+		result.PolicyIdReferences = append(result.PolicyIdReferences, PolicyIdReference{
+			ID:      policyIdReference.ID,
+			Version: policyIdReference.Version,
+		})
+	}
+
+	return result
+}
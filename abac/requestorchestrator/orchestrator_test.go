@@ -0,0 +1,679 @@
+package requestorchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInfoProvider struct {
+	mock.Mock
+}
+
+func (m *mockInfoProvider) GetInfo(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*infoprovider.GetInfoResponse), args.Error(1)
+}
+
+type mockDecisionMaker struct {
+	mock.Mock
+}
+
+func (m *mockDecisionMaker) MakeDecision(ctx context.Context, req *decisionmaker.DecisionRequest) (*decisionmaker.DecisionResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*decisionmaker.DecisionResponse), args.Error(1)
+}
+
+func (m *mockDecisionMaker) MakeDecisions(
+	ctx context.Context,
+	reqs []*decisionmaker.DecisionRequest,
+) ([]*decisionmaker.DecisionResponse, error) {
+	args := m.Called(ctx, reqs)
+	return args.Get(0).([]*decisionmaker.DecisionResponse), args.Error(1)
+}
+
+type mockInfoAnalyser struct {
+	mock.Mock
+}
+
+func (m *mockInfoAnalyser) AnalyseInfoRequirements(ctx context.Context, req *EnrichedAccessRequest) ([]infoprovider.GetInfoRequest, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]infoprovider.GetInfoRequest), args.Error(1)
+}
+
+func TestRequestOrchestrator_EvaluateAccess(t *testing.T) { //nolint:gocyclo // unit test
+	testCases := map[string]struct {
+		subjectInfoResp    *infoprovider.GetInfoResponse
+		subjectInfoErr     error
+		resourceInfoResp   *infoprovider.GetInfoResponse
+		resourceInfoErr    error
+		analyserReqs       []infoprovider.GetInfoRequest
+		analyserErr        error
+		additionalInfoResp map[string]*infoprovider.GetInfoResponse
+		additionalInfoErr  map[string]error
+		decisionResp       *decisionmaker.DecisionResponse
+		decisionErr        error
+		expectedResult     *AccessResponse
+		expectedError      string
+	}{
+		"should permit access when all info available": {
+			subjectInfoResp: &infoprovider.GetInfoResponse{
+				Info: map[string]any{"role": "admin"},
+			},
+			resourceInfoResp: &infoprovider.GetInfoResponse{
+				Info: map[string]any{"owner": "user123"},
+			},
+			analyserReqs: []infoprovider.GetInfoRequest{},
+			decisionResp: &decisionmaker.DecisionResponse{
+				RequestID: uuid.New(),
+				Decision:  decisionmaker.Permit,
+				Status:    &decisionmaker.Status{Code: decisionmaker.StatusOK, Message: "OK"},
+				PolicyIdReferences: []decisionmaker.PolicyIdReference{
+					{ID: "policy1", Version: "v1"},
+					{ID: "policy2", Version: "v2"},
+				},
+				EvaluatedAt: time.Now(),
+			},
+			expectedResult: &AccessResponse{
+				Decision: Permit,
+				Status:   Status{Code: StatusOK, Message: "OK"},
+				PolicyIdReferences: []PolicyIdReference{
+					{ID: "policy1", Version: "v1"},
+					{ID: "policy2", Version: "v2"},
+				},
+			},
+		},
+
+		"should return error when subject info not found": {
+			subjectInfoErr: errors.New("user not found"),
+			resourceInfoResp: &infoprovider.GetInfoResponse{
+				Info: map[string]any{"owner": "user123"},
+			},
+			expectedError: "failed to enrich request: failed to get subject info: user not found",
+		},
+
+		"should return error when resource info not found": {
+			subjectInfoResp: &infoprovider.GetInfoResponse{
+				Info: map[string]any{"role": "admin"},
+			},
+			resourceInfoErr: errors.New("document not found"),
+			expectedError:   "failed to enrich request: failed to get resource info: document not found",
+		},
+
+		"should return error when analyser fails": {
+			subjectInfoResp:  &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			resourceInfoResp: &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			analyserErr:      errors.New("analysis failed"),
+			expectedError:    "failed to analyze requirements: analyser failed: analysis failed",
+		},
+
+		"should return error when additional info unavailable": {
+			subjectInfoResp:  &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			resourceInfoResp: &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			analyserReqs:     []infoprovider.GetInfoRequest{{InfoType: "metadata", Params: "extra"}},
+			additionalInfoErr: map[string]error{
+				"extra": errors.New("metadata unavailable"),
+			},
+			expectedError: "failed to get additional info: failed to get info for extra: metadata unavailable",
+		},
+
+		"should return error when decision maker fails": {
+			subjectInfoResp:  &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			resourceInfoResp: &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			analyserReqs:     []infoprovider.GetInfoRequest{},
+			decisionErr:      errors.New("decision failed"),
+			expectedError:    "failed to make decision: decision failed",
+		},
+
+		"should deny access with obligations when policy requires": {
+			subjectInfoResp:  &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			resourceInfoResp: &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			analyserReqs:     []infoprovider.GetInfoRequest{},
+			decisionResp: &decisionmaker.DecisionResponse{
+				RequestID: uuid.New(),
+				Decision:  decisionmaker.Deny,
+				Status:    &decisionmaker.Status{Code: decisionmaker.StatusOK, Message: "Access denied"},
+				Obligations: []decisionmaker.Obligation{
+					{ID: "log", Attributes: map[string]any{"action": "denied"}},
+				},
+				Advice: []decisionmaker.Advice{
+					{ID: "contact", Attributes: map[string]any{"admin": "true"}},
+				},
+				EvaluatedAt: time.Now(),
+				PolicyIdReferences: []decisionmaker.PolicyIdReference{
+					{ID: "policy1", Version: "v1"},
+				},
+			},
+			expectedResult: &AccessResponse{
+				Decision: Deny,
+				Status:   Status{Code: StatusOK, Message: "Access denied"},
+				Obligations: []Obligation{
+					{ID: "log", Attributes: map[string]any{"action": "denied"}},
+				},
+				Advices: []Advice{
+					{ID: "contact", Attributes: map[string]any{"admin": "true"}},
+				},
+				PolicyIdReferences: []PolicyIdReference{
+					{ID: "policy1", Version: "v1"},
+				},
+			},
+		},
+
+		"should return error when duplicate info keys detected": {
+			subjectInfoResp:  &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			resourceInfoResp: &infoprovider.GetInfoResponse{Info: map[string]any{}},
+			analyserReqs: []infoprovider.GetInfoRequest{
+				{InfoType: "metadata", Params: "extra1"},
+				{InfoType: "metadata", Params: "extra2"},
+			},
+			additionalInfoResp: map[string]*infoprovider.GetInfoResponse{
+				"extra1": {Info: map[string]any{"key": "value1"}},
+				"extra2": {Info: map[string]any{"key": "value2"}},
+			},
+			expectedError: "failed to get additional info: duplicate info for key",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// Setup fresh mocks for each test
+			mockInfoProvider := new(mockInfoProvider)
+			mockDecisionMaker := new(mockDecisionMaker)
+			mockAnalyser := new(mockInfoAnalyser)
+
+			testRequest := &AccessRequest{
+				Subject:  Subject{ID: "user123", Type: "user"},
+				Action:   Action{ID: "read"},
+				Resource: Resource{ID: "doc456", Type: "document"},
+			}
+
+			// Setup subject info mock
+			if tc.subjectInfoResp != nil || tc.subjectInfoErr != nil {
+				mockInfoProvider.On("GetInfo", mock.Anything, &infoprovider.GetInfoRequest{
+					InfoType: testRequest.Subject.Type,
+					Params:   testRequest.Subject.ID,
+				}).Return(tc.subjectInfoResp, tc.subjectInfoErr)
+			}
+
+			// Setup resource info mock
+			if tc.resourceInfoResp != nil || tc.resourceInfoErr != nil {
+				mockInfoProvider.On("GetInfo", mock.Anything, &infoprovider.GetInfoRequest{
+					InfoType: testRequest.Resource.Type,
+					Params:   testRequest.Resource.ID,
+				}).Return(tc.resourceInfoResp, tc.resourceInfoErr)
+			}
+
+			// Setup analyser mock
+			if tc.analyserReqs != nil || tc.analyserErr != nil {
+				mockAnalyser.On("AnalyseInfoRequirements", mock.Anything, mock.Anything).Return(
+					tc.analyserReqs, tc.analyserErr,
+				)
+			}
+
+			// Setup additional info mocks
+			if tc.additionalInfoResp != nil || tc.additionalInfoErr != nil {
+				for id, resp := range tc.additionalInfoResp {
+					err := tc.additionalInfoErr[id]
+					mockInfoProvider.On("GetInfo", mock.Anything, &infoprovider.GetInfoRequest{
+						InfoType: "metadata",
+						Params:   id,
+					}).Return(resp, err)
+				}
+				for id, err := range tc.additionalInfoErr {
+					if tc.additionalInfoResp[id] == nil {
+						mockInfoProvider.On("GetInfo", mock.Anything, &infoprovider.GetInfoRequest{
+							InfoType: "metadata",
+							Params:   id,
+						}).Return((*infoprovider.GetInfoResponse)(nil), err)
+					}
+				}
+			}
+
+			// Setup decision maker mock
+			if tc.decisionResp != nil || tc.decisionErr != nil {
+				mockDecisionMaker.On("MakeDecision", mock.Anything, mock.Anything).Return(
+					tc.decisionResp, tc.decisionErr,
+				)
+			}
+
+			orchestrator := NewRequestOrchestrator(mockInfoProvider, mockDecisionMaker, WithInfoAnalysers(mockAnalyser))
+
+			result, err := orchestrator.EvaluateAccess(context.Background(), testRequest)
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assertAccessResponse(t, tc.expectedResult, result)
+			}
+
+			mockInfoProvider.AssertExpectations(t)
+			mockDecisionMaker.AssertExpectations(t)
+			mockAnalyser.AssertExpectations(t)
+		})
+	}
+}
+
+func assertAccessResponse(t *testing.T, expected, actual *AccessResponse) {
+	assert.NotNil(t, actual)
+	assert.NotNil(t, actual.RequestID)
+	assert.False(t, actual.EvaluatedAt.IsZero())
+
+	expected.RequestID = actual.RequestID
+	expected.EvaluatedAt = actual.EvaluatedAt
+
+	assert.EqualValues(t, expected, actual)
+}
+
+type infoProviderFunc func(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error)
+
+func (f infoProviderFunc) GetInfo(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+	return f(ctx, req)
+}
+
+type infoAnalyserFunc func(req *EnrichedAccessRequest) []infoprovider.GetInfoRequest
+
+func (f infoAnalyserFunc) AnalyseInfoRequirements(_ context.Context, req *EnrichedAccessRequest) ([]infoprovider.GetInfoRequest, error) {
+	return f(req), nil
+}
+
+func TestRequestOrchestrator_EvaluateAccessWithRounds(t *testing.T) {
+	// Roles lead to permissions, which lead to delegations, one hop per round
+	chainAnalyser := infoAnalyserFunc(func(req *EnrichedAccessRequest) []infoprovider.GetInfoRequest {
+		reqs := []infoprovider.GetInfoRequest{{InfoType: "roles", Params: req.Subject.ID}}
+		if roles, ok := req.Environment["roles"]; ok {
+			reqs = append(reqs, infoprovider.GetInfoRequest{InfoType: "permissions", Params: roles})
+		}
+		if permissions, ok := req.Environment["permissions"]; ok {
+			reqs = append(reqs, infoprovider.GetInfoRequest{InfoType: "delegations", Params: permissions})
+		}
+		return reqs
+	})
+
+	// Fetches the region once a decision reports it missing
+	missingAnalyser := infoAnalyserFunc(func(req *EnrichedAccessRequest) []infoprovider.GetInfoRequest {
+		for _, attribute := range req.MissingAttributes {
+			if attribute.Category == "environment" && attribute.Path == "region" {
+				return []infoprovider.GetInfoRequest{{InfoType: "region", Params: req.Subject.ID}}
+			}
+		}
+		return nil
+	})
+
+	missingRegion := &decisionmaker.DecisionResponse{
+		Decision: decisionmaker.Indeterminate,
+		Status: &decisionmaker.Status{
+			Code:              decisionmaker.StatusMissingAttribute,
+			Message:           "Missing attributes: environment.region",
+			MissingAttributes: []decisionmaker.AttributeDesignator{{Category: "environment", Path: "region"}},
+		},
+	}
+
+	testCases := map[string]struct {
+		analyser             InfoAnalyser
+		options              []Option
+		requireRegion        bool
+		expectedEnvironments []map[string]any
+		expectedDecision     Decision
+		expectedError        string
+	}{
+		"should fetch one round by default": {
+			analyser:             chainAnalyser,
+			expectedEnvironments: []map[string]any{{"roles": "roles:user123"}},
+			expectedDecision:     Permit,
+		},
+		"should follow chains until analysers request nothing new": {
+			analyser: chainAnalyser,
+			options:  []Option{WithMaxRounds(5)},
+			expectedEnvironments: []map[string]any{{
+				"roles":       "roles:user123",
+				"permissions": "permissions:roles:user123",
+				"delegations": "delegations:permissions:roles:user123",
+			}},
+			expectedDecision: Permit,
+		},
+		"should stop following chains at max rounds": {
+			analyser: chainAnalyser,
+			options:  []Option{WithMaxRounds(2)},
+			expectedEnvironments: []map[string]any{{
+				"roles":       "roles:user123",
+				"permissions": "permissions:roles:user123",
+			}},
+			expectedDecision: Permit,
+		},
+		"should decide again after fetching missing attributes": {
+			analyser:             missingAnalyser,
+			options:              []Option{WithMaxRounds(2)},
+			requireRegion:        true,
+			expectedEnvironments: []map[string]any{{}, {"region": "region:user123"}},
+			expectedDecision:     Permit,
+		},
+		"should return missing attributes decision when rounds are exhausted": {
+			analyser: infoAnalyserFunc(func(req *EnrichedAccessRequest) []infoprovider.GetInfoRequest {
+				return append(chainAnalyser(req), missingAnalyser(req)...)
+			}),
+			requireRegion:        true,
+			expectedEnvironments: []map[string]any{{"roles": "roles:user123"}},
+			expectedDecision:     Indeterminate,
+		},
+		"should return error when timeout elapses": {
+			analyser: infoAnalyserFunc(func(*EnrichedAccessRequest) []infoprovider.GetInfoRequest {
+				return []infoprovider.GetInfoRequest{{InfoType: "slow", Params: "orders"}}
+			}),
+			options:       []Option{WithTimeout(10 * time.Millisecond)},
+			expectedError: "failed to get additional info: failed to get info for orders: context deadline exceeded",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			provider := infoProviderFunc(func(ctx context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+				switch req.InfoType {
+				case "user", "document":
+					return &infoprovider.GetInfoResponse{Info: map[string]any{}}, nil
+				case "slow":
+					<-ctx.Done()
+					return nil, ctx.Err()
+				default:
+					return &infoprovider.GetInfoResponse{
+						Info: map[string]any{req.InfoType: fmt.Sprintf("%s:%v", req.InfoType, req.Params)},
+					}, nil
+				}
+			})
+
+			// Decisions report the region missing until it is fetched, when required
+			dm := new(mockDecisionMaker)
+			if tc.requireRegion {
+				dm.On("MakeDecision", mock.Anything, mock.MatchedBy(func(req *decisionmaker.DecisionRequest) bool {
+					return req.Environment["region"] == nil
+				})).Return(missingRegion, nil)
+			}
+			dm.On("MakeDecision", mock.Anything, mock.Anything).Return(
+				&decisionmaker.DecisionResponse{Decision: decisionmaker.Permit, Status: &decisionmaker.Status{Code: decisionmaker.StatusOK}},
+				nil,
+			)
+
+			orchestrator := NewRequestOrchestrator(provider, dm, append(tc.options, WithInfoAnalysers(tc.analyser))...)
+			result, err := orchestrator.EvaluateAccess(context.Background(), &AccessRequest{
+				Subject:  Subject{ID: "user123", Type: "user"},
+				Action:   Action{ID: "read"},
+				Resource: Resource{ID: "doc456", Type: "document"},
+			})
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDecision, result.Decision)
+			if result.Status.Code == StatusMissingAttribute {
+				assert.Equal(t, missingRegion.Status.MissingAttributes, result.Status.MissingAttributes)
+			}
+
+			environments := make([]map[string]any, 0, len(dm.Calls))
+			for _, call := range dm.Calls {
+				environments = append(environments, call.Arguments.Get(1).(*decisionmaker.DecisionRequest).Environment)
+			}
+			assert.Equal(t, tc.expectedEnvironments, environments)
+		})
+	}
+}
+
+func TestRequestOrchestrator_EvaluateAccessWithInfoTypes(t *testing.T) {
+	testCases := map[string]struct {
+		options                    []Option
+		expectedInfoTypes          []string
+		expectedSubjectAttributes  map[string]any
+		expectedResourceAttributes map[string]any
+	}{
+		"should fetch attributes under subject and resource types by default": {
+			expectedInfoTypes:          []string{"document", "user"},
+			expectedSubjectAttributes:  map[string]any{"infoType": "user"},
+			expectedResourceAttributes: map[string]any{"infoType": "document"},
+		},
+		"should fetch attributes under mapped info types": {
+			options: []Option{
+				WithSubjectInfoType(InfoTypes(map[string]string{"user": "directory"})),
+				WithResourceInfoType(InfoTypes(map[string]string{"document": "documents"})),
+			},
+			expectedInfoTypes:          []string{"directory", "documents"},
+			expectedSubjectAttributes:  map[string]any{"infoType": "directory"},
+			expectedResourceAttributes: map[string]any{"infoType": "documents"},
+		},
+		"should not fetch attributes of unmapped types": {
+			options: []Option{
+				WithResourceInfoType(InfoTypes(map[string]string{"order": "orders"})),
+			},
+			expectedInfoTypes:          []string{"user"},
+			expectedSubjectAttributes:  map[string]any{"infoType": "user"},
+			expectedResourceAttributes: map[string]any{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			infoTypes := make([]string, 0)
+			provider := infoProviderFunc(func(_ context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				infoTypes = append(infoTypes, req.InfoType)
+				return &infoprovider.GetInfoResponse{Info: map[string]any{"infoType": req.InfoType}}, nil
+			})
+
+			dm := new(mockDecisionMaker)
+			dm.On("MakeDecision", mock.Anything, mock.Anything).Return(
+				&decisionmaker.DecisionResponse{Decision: decisionmaker.Permit, Status: &decisionmaker.Status{Code: decisionmaker.StatusOK}},
+				nil,
+			)
+
+			_, err := NewRequestOrchestrator(provider, dm, tc.options...).EvaluateAccess(context.Background(), &AccessRequest{
+				Subject:  Subject{ID: "user123", Type: "user"},
+				Action:   Action{ID: "read"},
+				Resource: Resource{ID: "doc456", Type: "document"},
+			})
+
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectedInfoTypes, infoTypes)
+
+			decisionReq := dm.Calls[0].Arguments.Get(1).(*decisionmaker.DecisionRequest)
+			assert.Equal(t, tc.expectedSubjectAttributes, decisionReq.Subject.Attributes)
+			assert.Equal(t, tc.expectedResourceAttributes, decisionReq.Resource.Attributes)
+		})
+	}
+}
+
+func TestRequestOrchestrator_EvaluateAccessWithSchemas(t *testing.T) {
+	schemas := map[string]infoprovider.Schema{
+		"user": {Attributes: []infoprovider.AttributeDefinition{
+			{Name: "roles", Type: infoprovider.AttributeTypeString, MultiValued: true, Required: true},
+			{Name: "level", Type: infoprovider.AttributeTypeInteger},
+		}},
+		"region": {Attributes: []infoprovider.AttributeDefinition{
+			{Name: "region", Type: infoprovider.AttributeTypeString, Required: true},
+		}},
+	}
+
+	testCases := map[string]struct {
+		info                      map[string]map[string]any
+		expectedSubjectAttributes map[string]any
+		expectedEnvironment       map[string]any
+		expectedResult            *AccessResponse
+	}{
+		"should decide on coerced info": {
+			info: map[string]map[string]any{
+				"user":   {"roles": []any{"admin"}, "level": float64(2)},
+				"region": {"region": "au"},
+			},
+			expectedSubjectAttributes: map[string]any{"roles": []string{"admin"}, "level": int64(2)},
+			expectedEnvironment:       map[string]any{"region": "au"},
+			expectedResult: &AccessResponse{
+				Decision:           Permit,
+				Status:             Status{Code: StatusOK},
+				PolicyIdReferences: []PolicyIdReference{},
+			},
+		},
+		"should be indeterminate when subject attributes are missing": {
+			info: map[string]map[string]any{
+				"user":   {"level": float64(2)},
+				"region": {"region": "au"},
+			},
+			expectedResult: &AccessResponse{
+				Decision: Indeterminate,
+				Status: Status{
+					Code:              StatusMissingAttribute,
+					Message:           "subject info of type user violates its schema: missing required attributes: roles",
+					MissingAttributes: []decisionmaker.AttributeDesignator{{Category: "subject", Path: "roles"}},
+				},
+				PolicyIdReferences: []PolicyIdReference{},
+			},
+		},
+		"should be indeterminate when additional info is missing": {
+			info: map[string]map[string]any{
+				"user":   {"roles": []any{"admin"}},
+				"region": {},
+			},
+			expectedResult: &AccessResponse{
+				Decision: Indeterminate,
+				Status: Status{
+					Code:              StatusMissingAttribute,
+					Message:           "environment info of type region violates its schema: missing required attributes: region",
+					MissingAttributes: []decisionmaker.AttributeDesignator{{Category: "environment", Path: "region"}},
+				},
+				PolicyIdReferences: []PolicyIdReference{},
+			},
+		},
+		"should be indeterminate when attributes are invalid": {
+			info: map[string]map[string]any{
+				"user":   {"roles": []any{"admin"}, "level": "high"},
+				"region": {"region": "au"},
+			},
+			expectedResult: &AccessResponse{
+				Decision: Indeterminate,
+				Status: Status{
+					Code:    StatusInvalidRequest,
+					Message: "subject info of type user violates its schema: invalid attributes: level must be integer, got string",
+				},
+				PolicyIdReferences: []PolicyIdReference{},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			provider := infoProviderFunc(func(_ context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+				return &infoprovider.GetInfoResponse{Info: tc.info[req.InfoType]}, nil
+			})
+			analyser := infoAnalyserFunc(func(_ *EnrichedAccessRequest) []infoprovider.GetInfoRequest {
+				return []infoprovider.GetInfoRequest{{InfoType: "region", Params: "ip"}}
+			})
+
+			dm := new(mockDecisionMaker)
+			dm.On("MakeDecision", mock.Anything, mock.Anything).Return(
+				&decisionmaker.DecisionResponse{
+					Decision:    decisionmaker.Permit,
+					Status:      &decisionmaker.Status{Code: decisionmaker.StatusOK},
+					EvaluatedAt: time.Now(),
+				},
+				nil,
+			).Maybe()
+
+			orchestrator := NewRequestOrchestrator(
+				provider,
+				dm,
+				WithResourceInfoType(InfoTypes(map[string]string{})),
+				WithInfoAnalysers(analyser),
+				WithSchemas(schemas),
+			)
+
+			result, err := orchestrator.EvaluateAccess(context.Background(), &AccessRequest{
+				Subject:  Subject{ID: "user123", Type: "user"},
+				Action:   Action{ID: "read"},
+				Resource: Resource{ID: "doc456", Type: "document"},
+			})
+
+			require.NoError(t, err)
+			if tc.expectedSubjectAttributes != nil {
+				require.Len(t, dm.Calls, 1)
+				decisionReq := dm.Calls[0].Arguments.Get(1).(*decisionmaker.DecisionRequest)
+				assert.Equal(t, tc.expectedSubjectAttributes, decisionReq.Subject.Attributes)
+				assert.Equal(t, tc.expectedEnvironment, decisionReq.Environment)
+			} else {
+				assert.Empty(t, dm.Calls)
+			}
+
+			assertAccessResponse(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestRequestOrchestrator_EvaluateAccessWithEnvironment(t *testing.T) {
+	testCases := map[string]struct {
+		environment         map[string]any
+		expectedEnvironment map[string]any
+		expectedError       string
+	}{
+		"should merge request environment with additional info": {
+			environment:         map[string]any{"client_ip": "10.1.2.3"},
+			expectedEnvironment: map[string]any{"client_ip": "10.1.2.3", "region": "au"},
+		},
+		"should return error when additional info overwrites request environment": {
+			environment:   map[string]any{"region": "us"},
+			expectedError: "failed to get additional info: duplicate info for region",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			provider := infoProviderFunc(func(_ context.Context, req *infoprovider.GetInfoRequest) (*infoprovider.GetInfoResponse, error) {
+				if req.InfoType == "region" {
+					return &infoprovider.GetInfoResponse{Info: map[string]any{"region": "au"}}, nil
+				}
+
+				return &infoprovider.GetInfoResponse{Info: map[string]any{}}, nil
+			})
+			analyser := infoAnalyserFunc(func(_ *EnrichedAccessRequest) []infoprovider.GetInfoRequest {
+				return []infoprovider.GetInfoRequest{{InfoType: "region", Params: "ip"}}
+			})
+
+			dm := new(mockDecisionMaker)
+			dm.On("MakeDecision", mock.Anything, mock.Anything).Return(
+				&decisionmaker.DecisionResponse{Decision: decisionmaker.Permit, Status: &decisionmaker.Status{Code: decisionmaker.StatusOK}},
+				nil,
+			).Maybe()
+
+			req := &AccessRequest{
+				Subject:     Subject{ID: "user123", Type: "user"},
+				Action:      Action{ID: "read"},
+				Resource:    Resource{ID: "doc456", Type: "document"},
+				Environment: tc.environment,
+			}
+			_, err := NewRequestOrchestrator(provider, dm, WithInfoAnalysers(analyser)).EvaluateAccess(context.Background(), req)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Len(t, req.Environment, 1)
+				return
+			}
+
+			require.NoError(t, err)
+			decisionReq := dm.Calls[0].Arguments.Get(1).(*decisionmaker.DecisionRequest)
+			assert.Equal(t, tc.expectedEnvironment, decisionReq.Environment)
+			assert.Equal(t, map[string]any{"client_ip": "10.1.2.3"}, req.Environment)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
 	"github.com/google/uuid"
 )
 
@@ -45,6 +46,9 @@ type AccessRequest struct {
 	Subject  Subject  `json:"subject"`
 	Action   Action   `json:"action"`
 	Resource Resource `json:"resource"`
+
+	// Environment holds attributes of the environment the request was made in, such as the current time or client IP
+	Environment map[string]any `json:"environment,omitempty"`
 }
 
 type Obligation struct {
@@ -60,6 +64,10 @@ type Advice struct {
 type Status struct {
 	Code    StatusCode `json:"code"`
 	Message string     `json:"message"`
+
+	// MissingAttributes lists the attributes the policies needed but could not find, reported with
+	// StatusMissingAttribute
+	MissingAttributes []decisionmaker.AttributeDesignator `json:"missingAttributes,omitempty"`
 }
 
 type PolicyIdReference struct {
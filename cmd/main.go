@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker/policyevaluator/casbin"
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker/policyevaluator/cedar"
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker/policyevaluator/cel"
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker/policyevaluator/opa"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider/filestore"
+)
+
+const (
+	EngineOPA    = "opa"
+	EngineCasbin = "casbin"
+	EngineCEL    = "cel"
+	EngineCedar  = "cedar"
+
+	DefaultPolicyDir = "cmd/policies"
+	PolicyVersion    = "v1"
+	RegoQuery        = "data.rbac.result"
+)
+
+// policyIDs maps each engine to the RBAC policy written in its language, stored under PolicyVersion
+var policyIDs = map[string]string{
+	EngineOPA:    "rbac.rego",
+	EngineCasbin: "rbac.conf",
+	EngineCEL:    "rbac.yaml",
+	EngineCedar:  "rbac.cedar",
+}
+
+// main evaluates a decision request against equivalent RBAC policies using the policy engine selected by flag,
+// so engines can be compared over the same DecisionMaker pipeline.
+func main() {
+	engine := flag.String("engine", EngineOPA, "policy engine to evaluate with: opa, casbin, cel or cedar")
+	policyDir := flag.String("policy-dir", DefaultPolicyDir, "directory containing policies, organised as <version>/<id>")
+	requestPath := flag.String("request", "-", "path to a JSON decision request, or - to read from stdin")
+	trace := flag.Bool("trace", false, "include a trace of policy resolution and evaluation in the response")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	req, err := readRequest(*requestPath)
+	if err != nil {
+		logger.Error("request_read_failed", "error", err)
+		os.Exit(1)
+	}
+	req.Trace = req.Trace || *trace
+
+	decisionMaker, err := newDecisionMaker(*engine, *policyDir)
+	if err != nil {
+		logger.Error("decision_maker_init_failed", "engine", *engine, "error", err)
+		os.Exit(1)
+	}
+
+	resp, err := decisionMaker.MakeDecision(context.Background(), req)
+	if err != nil {
+		logger.Error("decision_failed", "engine", *engine, "error", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(resp); err != nil {
+		logger.Error("response_write_failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// newDecisionMaker builds the DecisionMaker evaluating the engine's RBAC policy from the file-based policy provider
+func newDecisionMaker(engine, policyDir string) (decisionmaker.DecisionMaker, error) {
+	evaluator, err := newEvaluator(engine)
+	if err != nil {
+		return nil, err
+	}
+
+	return decisionmaker.NewDecisionMaker(
+		filestore.New(policyDir),
+		evaluator,
+		decisionmaker.WithPolicyResolver(&staticResolver{
+			policy: decisionmaker.PolicyIdReference{ID: policyIDs[engine], Version: PolicyVersion},
+		}),
+	), nil
+}
+
+// newEvaluator creates the PolicyEvaluator for the engine
+func newEvaluator(engine string) (decisionmaker.PolicyEvaluator, error) {
+	switch engine {
+	case EngineOPA:
+		return opa.NewEvaluator(RegoQuery), nil
+	case EngineCasbin:
+		return casbin.NewEvaluator(), nil
+	case EngineCEL:
+		return cel.NewEvaluator()
+	case EngineCedar:
+		return cedar.NewEvaluator(), nil
+	default:
+		return nil, fmt.Errorf("unsupported engine %q, must be one of: %s, %s, %s, %s", engine, EngineOPA, EngineCasbin, EngineCEL, EngineCedar)
+	}
+}
+
+// readRequest decodes a decision request from the file at path, or from stdin when path is "-"
+func readRequest(path string) (*decisionmaker.DecisionRequest, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open request: %w", err)
+		}
+		defer file.Close()
+
+		reader = file
+	}
+
+	var req decisionmaker.DecisionRequest
+	if err := json.NewDecoder(reader).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return &req, nil
+}
+
+// staticResolver resolves every decision request to the same policy
+type staticResolver struct {
+	policy decisionmaker.PolicyIdReference
+}
+
+// Resolve returns the configured policy reference
+func (r *staticResolver) Resolve(_ context.Context, req *decisionmaker.DecisionRequest) ([]decisionmaker.PolicyIdReference, error) {
+	if req == nil {
+		return nil, errors.New("decision request cannot be nil")
+	}
+
+	return []decisionmaker.PolicyIdReference{r.policy}, nil
+}
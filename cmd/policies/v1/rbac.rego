@@ -0,0 +1,17 @@
+package rbac
+
+# Permissions granted to each role.
+role_permissions := {"admin": [{"action": "get", "resource": "/api/resources"}]}
+
+# No role grants the requested permission.
+default result := {
+	"decision": "NotApplicable",
+	"status": {"code": "PolicyNotFound", "message": "No applicable policies found for the request"},
+}
+
+# A role of the subject grants the requested permission.
+result := {"decision": "Permit", "status": {"code": "OK"}} if {
+	some role in input.subject.attributes.roles
+	some permission in role_permissions[role]
+	permission == {"action": input.action.id, "resource": input.resource.id}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -12,19 +14,21 @@ import (
 	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
 	"github.com/CameronXie/access-control-explorer/abac/decisionmaker/policyevaluator/opa"
 	ip "github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	ipcache "github.com/CameronXie/access-control-explorer/abac/infoprovider/cache"
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider/environment"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
 	"github.com/CameronXie/access-control-explorer/abac/policyprovider/filestore"
+	"github.com/CameronXie/access-control-explorer/abac/requestorchestrator"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/advice"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/api/rest/handler"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/api/rest/middleware"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/enforcer"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/enforcer/jwt"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/enforcer/operations"
+	"github.com/CameronXie/access-control-explorer/examples/abac/internal/infoanalyser"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/infoprovider"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/obligation"
-	"github.com/CameronXie/access-control-explorer/examples/abac/internal/policyresolver"
 	repository "github.com/CameronXie/access-control-explorer/examples/abac/internal/repository/postgres"
-	"github.com/CameronXie/access-control-explorer/examples/abac/internal/requestorchestrator"
-	"github.com/CameronXie/access-control-explorer/examples/abac/internal/requestorchestrator/infoanalyser"
 	"github.com/CameronXie/access-control-explorer/examples/abac/internal/version"
 	"github.com/CameronXie/access-control-explorer/examples/abac/pkg/keyfetcher"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -39,10 +43,25 @@ const (
 	RBACPolicyKey    = "rbac.rego"
 	PolicyVersion    = "v1"
 
+	// PolicyTargetSuffix names the optional file declaring the target of the policy it suffixes
+	PolicyTargetSuffix = ".target.json"
+
 	TokenTTL                    = 1 * time.Hour
 	DecisionCacheHintHeaderName = "X-ABAC-Decision-TTL"
 
 	JWTClockSkewTolerance = 5 * time.Minute
+
+	// AttributeRounds bounds the rounds of attributes fetched for an access request, within AttributeTimeout
+	AttributeRounds  = 3
+	AttributeTimeout = 2 * time.Second
+
+	// InfoCacheTTL bounds how long fetched attributes are reused, except role data which changes rarely and is cached
+	// for RoleInfoCacheTTL, and policy versions which are always fetched so PAP changes apply immediately
+	InfoCacheTTL     = 30 * time.Second
+	RoleInfoCacheTTL = 5 * time.Minute
+
+	// EnvironmentTimePrecision truncates the current time seen by policies, so cached decisions are reused within it
+	EnvironmentTimePrecision = time.Minute
 )
 
 func main() {
@@ -75,6 +94,21 @@ func main() {
 	userRepo := repository.NewUserRepository(dbPool)
 	orderRepo := repository.NewOrderRepository(dbPool)
 	rbacRepo := repository.NewRBACRepository(dbPool)
+	policyRepo := repository.NewPolicyRepository(dbPool)
+
+	// PRP: policies are stored in the database, imported from the policy directory on first start
+	if err := importPolicies(context.Background(), policyRepo, filestore.New(policyPath)); err != nil {
+		logger.Error("policy_import_failed", "error", err)
+		os.Exit(1)
+	}
+
+	// PDP policy evaluator, also used by the PAP to validate uploaded policies
+	evaluator := opa.NewEvaluator(RegoQuery)
+	validator, ok := evaluator.(decisionmaker.PolicyValidator)
+	if !ok {
+		logger.Error("policy_validator_unsupported")
+		os.Exit(1)
+	}
 
 	// Auth config
 	issuer := os.Getenv("JWT_ISSUER")
@@ -89,7 +123,7 @@ func main() {
 	})
 
 	// Enforcer (PEP)
-	enforcerMiddleware, err := initEnforcer(policyPath, userRepo, orderRepo, rbacRepo, logger)
+	enforcerMiddleware, err := initEnforcer(policyRepo, evaluator, userRepo, orderRepo, rbacRepo, logger)
 	if err != nil {
 		logger.Error("enforcer_init_failed", "error", err)
 		os.Exit(1)
@@ -97,6 +131,7 @@ func main() {
 
 	// REST handlers
 	orderHandler := handler.NewOrderHandler(orderRepo, logger)
+	policyHandler := handler.NewPolicyHandler(policyRepo, validator, logger)
 	authHandler := handler.NewAuthHandler(
 		userRepo,
 		&handler.AuthConfig{
@@ -109,7 +144,7 @@ func main() {
 	)
 
 	// Routing
-	mux := buildServeMux(authHandler, orderHandler, jwtMiddleware, enforcerMiddleware)
+	mux := buildServeMux(authHandler, orderHandler, policyHandler, jwtMiddleware, enforcerMiddleware)
 
 	// HTTP server with sensible timeouts
 	port := os.Getenv("PORT")
@@ -148,36 +183,103 @@ func initializeDatabase(logger *slog.Logger, connectionString string) (*pgxpool.
 	return pool, nil
 }
 
+// importPolicies stores each policy of the policy directory as the active version of a policy without versions,
+// with the target declared in the policy's target file
+func importPolicies(ctx context.Context, policyRepo *repository.PolicyRepository, files policyprovider.PolicyProvider) error {
+	for _, id := range []string{DefaultPolicyKey, RBACPolicyKey} {
+		versions, err := policyRepo.ListPolicyVersions(ctx, id)
+		if err != nil {
+			return fmt.Errorf("list_policy_versions: %w", err)
+		}
+
+		if len(versions) > 0 {
+			continue
+		}
+
+		policies, err := files.GetPolicies(ctx, []policyprovider.GetPolicyRequest{{ID: id, Version: PolicyVersion}})
+		if err != nil {
+			return fmt.Errorf("read_policy_file: %w", err)
+		}
+
+		target, err := readPolicyTarget(ctx, files, id)
+		if err != nil {
+			return fmt.Errorf("read_policy_target: %w", err)
+		}
+
+		err = policyRepo.CreatePolicy(ctx, id, PolicyVersion, policies[0].Content, target, policyprovider.PolicyStatusActive)
+		if err != nil {
+			return fmt.Errorf("create_policy: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readPolicyTarget reads the target declared in the target file of a policy, an empty target if it has none
+func readPolicyTarget(ctx context.Context, files policyprovider.PolicyProvider, id string) (policyprovider.PolicyTarget, error) {
+	var target policyprovider.PolicyTarget
+
+	contents, err := files.GetPolicies(ctx, []policyprovider.GetPolicyRequest{{ID: id + PolicyTargetSuffix, Version: PolicyVersion}})
+	if errors.Is(err, policyprovider.ErrPolicyNotFound) {
+		return target, nil
+	}
+	if err != nil {
+		return target, err
+	}
+
+	if err := json.Unmarshal(contents[0].Content, &target); err != nil {
+		return target, fmt.Errorf("decode_target: %w", err)
+	}
+
+	return target, target.Validate()
+}
+
 // initEnforcer wires PRP, PDP, Context Handler, and PEP middleware.
 func initEnforcer(
-	policyPath string,
+	policyRepo *repository.PolicyRepository,
+	evaluator decisionmaker.PolicyEvaluator,
 	userRepo infoprovider.UserAttributesRepository,
 	orderRepo infoprovider.OrderAttributesRepository,
 	rbacRepo infoprovider.RBACRepository,
 	logger *slog.Logger,
 ) (*enforcer.Enforcer, error) {
-	// PRP: policy provider
-	policyProvider := filestore.New(policyPath)
-
-	// PDP: decision maker
-	decisionMaker := decisionmaker.NewDecisionMaker(
-		policyProvider,
-		opa.NewEvaluator(RegoQuery),
-		decisionmaker.WithPolicyResolver(policyresolver.NewDefaultResolver(DefaultPolicyKey, PolicyVersion)),
-		decisionmaker.WithPolicyResolver(policyresolver.NewRBACResolver(RBACPolicyKey, PolicyVersion)),
-	)
+	// PDP: decision maker evaluating the active policy versions whose target matches the request, cached for the TTL
+	// of the policies' cache_hint advice
+	decisionMaker, err := decisionmaker.NewCachingDecisionMaker(decisionmaker.NewDecisionMaker(
+		policyRepo,
+		evaluator,
+		decisionmaker.WithPolicyResolver(decisionmaker.NewTargetResolver(
+			policyRepo,
+			decisionmaker.WithTargetErrorHandler(func(err error) {
+				logger.Error("policy_list_failed", "error", err)
+			}),
+		)),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("new_decision_maker: %w", err)
+	}
 
 	// Context Handler: enrich request and call PDP
-	orchestrator := requestorchestrator.NewRequestOrchestrator(
-		[]requestorchestrator.InfoAnalyser{
-			infoanalyser.NewRBACAnalyser(infoprovider.InfoTypeRBAC),
-		},
-		infoprovider.NewInfoProvider(map[infoprovider.InfoType]ip.InfoProvider{
-			infoprovider.InfoTypeUser:  infoprovider.NewUserProvider(userRepo),
-			infoprovider.InfoTypeOrder: infoprovider.NewOrderProvider(orderRepo),
-			infoprovider.InfoTypeRBAC:  infoprovider.NewRoleBasedAccessProvider(rbacRepo),
+	// PIP: info providers by info type, behind a cache sharing concurrent lookups
+	infoProvider := ipcache.New(
+		ip.NewRouter(map[string]ip.InfoProvider{
+			string(infoprovider.InfoTypeUser):   infoprovider.NewUserProvider(userRepo),
+			string(infoprovider.InfoTypeOrder):  infoprovider.NewOrderProvider(orderRepo),
+			string(infoprovider.InfoTypeRBAC):   infoprovider.NewRoleBasedAccessProvider(rbacRepo),
+			string(infoprovider.InfoTypePolicy): infoprovider.NewPolicyProvider(policyRepo),
 		}),
+		ipcache.WithTTL(InfoCacheTTL),
+		ipcache.WithInfoTypeTTL(string(infoprovider.InfoTypeRBAC), RoleInfoCacheTTL),
+		ipcache.WithInfoTypeTTL(string(infoprovider.InfoTypePolicy), 0),
+	)
+
+	orchestrator := requestorchestrator.NewRequestOrchestrator(
+		infoProvider,
 		decisionMaker,
+		requestorchestrator.WithInfoAnalysers(infoanalyser.NewRBACAnalyser(infoprovider.InfoTypeRBAC)),
+		requestorchestrator.WithMaxRounds(AttributeRounds),
+		requestorchestrator.WithTimeout(AttributeTimeout),
+		requestorchestrator.WithSchemas(infoprovider.Schemas()),
 	)
 
 	// HTTP request extractors for operations
@@ -194,11 +296,25 @@ func initEnforcer(
 		return nil, fmt.Errorf("new_order_read_extractor: %w", err)
 	}
 
+	policyReadExtractor := operations.NewPolicyExtractor(operations.ActionRead)
+	policyCreateExtractor := operations.NewPolicyExtractor(operations.ActionCreate)
+	policyUpdateExtractor := operations.NewPolicyExtractor(operations.ActionUpdate)
+	policyDeleteExtractor := operations.NewPolicyExtractor(operations.ActionDelete)
+
 	// PEP request extractor
 	requestExtractor, err := enforcer.NewRequestExtractor(
 		enforcer.WithSubjectExtractor(jwt.NewSubjectExtractor()),
+		enforcer.WithEnvironmentProvider(environment.New(environment.WithTimePrecision(EnvironmentTimePrecision))),
 		enforcer.WithOperationExtractor("/orders", http.MethodPost, orderCreateExtractor),
 		enforcer.WithOperationExtractor("/orders/*", http.MethodGet, orderReadExtractor),
+		enforcer.WithOperationExtractor("/policies/*/versions", http.MethodGet, policyReadExtractor),
+		enforcer.WithOperationExtractor("/policies/*/versions", http.MethodPost, policyCreateExtractor),
+		enforcer.WithOperationExtractor("/policies/*/versions/*", http.MethodGet, policyReadExtractor),
+		enforcer.WithOperationExtractor("/policies/*/versions/*", http.MethodDelete, policyDeleteExtractor),
+		enforcer.WithOperationExtractor("/policies/*/versions/*/activate", http.MethodPost, policyUpdateExtractor),
+		enforcer.WithOperationExtractor("/policies/*/rollback", http.MethodPost, policyUpdateExtractor),
+		enforcer.WithOperationExtractor("/policies/*/diff", http.MethodGet, policyReadExtractor),
+		enforcer.WithOperationExtractor("/policies/*/validate", http.MethodPost, policyCreateExtractor),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("new_request_extractor: %w", err)
@@ -214,10 +330,11 @@ func initEnforcer(
 	), nil
 }
 
-// buildServeMux wires routes and applies the PEP to API endpoints.
+// buildServeMux wires routes and applies the PEP to API and admin endpoints.
 func buildServeMux(
 	authHandler *handler.AuthHandler,
 	orderHandler *handler.OrderHandler,
+	policyHandler *handler.PolicyHandler,
 	jwtMiddleware *middleware.JWTAuthMiddleware,
 	enforcer *enforcer.Enforcer,
 ) *http.ServeMux {
@@ -230,6 +347,19 @@ func buildServeMux(
 	root.Handle("POST /auth/signin", http.HandlerFunc(authHandler.SignIn))
 	api.Handle("POST /orders", http.HandlerFunc(orderHandler.CreateOrder))
 	api.Handle("GET /orders/{id}", http.HandlerFunc(orderHandler.GetOrderByID))
+
+	// PAP: policy administration
+	admin := http.NewServeMux()
+	root.Handle("/admin/v1/", http.StripPrefix("/admin/v1", jwtMiddleware.Handler(enforcer.Enforce(admin))))
+
+	admin.Handle("GET /policies/{id}/versions", http.HandlerFunc(policyHandler.ListPolicyVersions))
+	admin.Handle("POST /policies/{id}/versions", http.HandlerFunc(policyHandler.CreatePolicyVersion))
+	admin.Handle("GET /policies/{id}/versions/{version}", http.HandlerFunc(policyHandler.GetPolicyVersion))
+	admin.Handle("DELETE /policies/{id}/versions/{version}", http.HandlerFunc(policyHandler.ArchivePolicyVersion))
+	admin.Handle("POST /policies/{id}/versions/{version}/activate", http.HandlerFunc(policyHandler.ActivatePolicyVersion))
+	admin.Handle("POST /policies/{id}/rollback", http.HandlerFunc(policyHandler.RollbackPolicy))
+	admin.Handle("GET /policies/{id}/diff", http.HandlerFunc(policyHandler.DiffPolicyVersions))
+	admin.Handle("POST /policies/{id}/validate", http.HandlerFunc(policyHandler.ValidatePolicy))
 	return root
 }
 
@@ -10,7 +10,7 @@ result := r if {
 	r = {
 		"decision": "Permit",
 		"status": {"code": "OK"},
-		"advices": [{
+		"advice": [{
 			"id": "cache_hint",
 			"attributes": {"ttl_seconds": 30},
 		}],
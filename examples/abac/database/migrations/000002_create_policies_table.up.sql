@@ -0,0 +1,19 @@
+CREATE TABLE policies
+(
+    id           VARCHAR(255) NOT NULL,
+    version      VARCHAR(50)  NOT NULL,
+    content      TEXT         NOT NULL,
+    content_hash CHAR(64)     NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status       VARCHAR(20)  NOT NULL DEFAULT 'draft',
+
+    PRIMARY KEY (id, version),
+    CHECK (status IN ('draft', 'active', 'archived')),
+    -- Symbolic versions are resolved at read time and cannot be stored
+    CHECK (version NOT IN ('latest', 'active'))
+);
+
+-- At most one active version per policy
+CREATE UNIQUE INDEX idx_policies_active ON policies (id) WHERE status = 'active';
+
+CREATE INDEX idx_policies_latest ON policies (id, created_at DESC);
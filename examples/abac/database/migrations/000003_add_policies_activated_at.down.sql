@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_policies_activated;
+
+ALTER TABLE policies
+    DROP COLUMN IF EXISTS activated_at;
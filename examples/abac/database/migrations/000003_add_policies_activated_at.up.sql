@@ -0,0 +1,9 @@
+-- When a version was last made active, used to find the version to roll back to
+ALTER TABLE policies
+    ADD COLUMN activated_at TIMESTAMPTZ;
+
+UPDATE policies
+SET activated_at = created_at
+WHERE status = 'active';
+
+CREATE INDEX idx_policies_activated ON policies (id, activated_at DESC) WHERE activated_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Target declaring the decision requests a policy version applies to, an empty target applies to every request
+ALTER TABLE policies
+    ADD COLUMN target JSONB NOT NULL DEFAULT '{}'::jsonb;
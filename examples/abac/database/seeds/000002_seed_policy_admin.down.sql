@@ -0,0 +1,19 @@
+-- 000002_seed_policy_admin.down.sql
+
+-- Remove role_permissions inserted for policy administration
+DELETE
+FROM role_permissions rp
+    USING roles r, actions a, resources res
+WHERE rp.role_id = r.id
+  AND rp.action_id = a.id
+  AND rp.resource_id = res.id
+  AND res.name = 'policy'
+  AND r.name = 'admin'
+  AND a.name IN ('create', 'read', 'update', 'delete');
+
+DELETE
+FROM actions
+WHERE name IN ('update', 'delete');
+DELETE
+FROM resources
+WHERE name = 'policy';
@@ -0,0 +1,20 @@
+-- 000002_seed_policy_admin.up.sql
+
+-- Actions and resource of the policy administration API
+INSERT INTO actions (id, name, description)
+VALUES
+    (gen_random_uuid(), 'update', 'Activate or roll back policy versions'),
+    (gen_random_uuid(), 'delete', 'Archive policy versions')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO resources (id, name, description)
+VALUES (gen_random_uuid(), 'policy', 'Policy resource')
+ON CONFLICT (name) DO NOTHING;
+
+-- Role permissions
+-- admin: manage all policies
+INSERT INTO role_permissions (id, role_id, action_id, resource_id)
+SELECT gen_random_uuid(), r.id, a.id, res.id
+FROM roles r, actions a, resources res
+WHERE r.name = 'admin' AND res.name = 'policy' AND a.name IN ('create', 'read', 'update', 'delete')
+ON CONFLICT (role_id, action_id, resource_id) DO NOTHING;
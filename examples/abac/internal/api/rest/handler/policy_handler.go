@@ -0,0 +1,388 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/CameronXie/access-control-explorer/examples/abac/internal/repository"
+)
+
+const (
+	// maxPolicyRequestBytes bounds the size of request bodies carrying policy content
+	maxPolicyRequestBytes = 1 << 20
+
+	// maxDiffLines bounds the number of lines of each policy version compared by a diff, which takes time and memory
+	// proportional to the product of both line counts
+	maxDiffLines = 2000
+)
+
+// PolicyHandler handles HTTP requests of the Policy Administration Point
+type PolicyHandler struct {
+	repo      policyprovider.WritablePolicyProvider
+	validator decisionmaker.PolicyValidator
+	logger    *slog.Logger
+}
+
+// NewPolicyHandler creates a new PolicyHandler instance.
+// Uploaded policies are compiled with the validator, which is the PolicyEvaluator used by the PDP.
+func NewPolicyHandler(
+	repo policyprovider.WritablePolicyProvider,
+	validator decisionmaker.PolicyValidator,
+	logger *slog.Logger,
+) *PolicyHandler {
+	return &PolicyHandler{
+		repo:      repo,
+		validator: validator,
+		logger:    logger,
+	}
+}
+
+// CreatePolicyVersionRequest represents the request payload for uploading a policy version
+type CreatePolicyVersionRequest struct {
+	Version  string                      `json:"version"`
+	Content  string                      `json:"content"`
+	Target   policyprovider.PolicyTarget `json:"target"`
+	Activate bool                        `json:"activate,omitempty"`
+}
+
+// ValidatePolicyRequest represents the request payload for validating policy content without storing it
+type ValidatePolicyRequest struct {
+	Content string `json:"content"`
+}
+
+// PolicyVersionResponse represents a stored version of a policy
+type PolicyVersionResponse struct {
+	ID          string                      `json:"id"`
+	Version     string                      `json:"version"`
+	Status      string                      `json:"status"`
+	ContentHash string                      `json:"content_hash"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	ActivatedAt *time.Time                  `json:"activated_at,omitempty"`
+	Target      policyprovider.PolicyTarget `json:"target"`
+}
+
+// PolicyContentResponse represents the content of a policy version
+type PolicyContentResponse struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Content string `json:"content"`
+}
+
+// PolicyDiffResponse represents the line diff between two versions of a policy
+type PolicyDiffResponse struct {
+	ID    string   `json:"id"`
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Lines []string `json:"lines"`
+}
+
+// ListPolicyVersions handles GET /policies/{id}/versions - lists the versions of a policy
+func (h *PolicyHandler) ListPolicyVersions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	versions, err := h.repo.ListPolicyVersions(r.Context(), id)
+	if err != nil {
+		h.writeRepositoryError(w, err, "Failed to list policy versions", "policy_id", id)
+		return
+	}
+
+	if len(versions) == 0 {
+		WriteErrorResponse(w, http.StatusNotFound, "Policy not found", "The requested policy could not be found")
+		return
+	}
+
+	response := make([]PolicyVersionResponse, 0, len(versions))
+	for i := range versions {
+		response = append(response, newPolicyVersionResponse(&versions[i]))
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// CreatePolicyVersion handles POST /policies/{id}/versions - validates and stores a new version of a policy
+func (h *PolicyHandler) CreatePolicyVersion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req CreatePolicyVersionRequest
+	if !decodePolicyRequest(w, r, &req) {
+		return
+	}
+
+	if req.Version == "" || req.Content == "" {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request", "Version and content are required")
+		return
+	}
+
+	if err := req.Target.Validate(); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid target", err.Error())
+		return
+	}
+
+	if !h.validate(w, r, id, req.Version, req.Content) {
+		return
+	}
+
+	// Creating an active version archives the previously active version in the same transaction
+	status := policyprovider.PolicyStatusDraft
+	if req.Activate {
+		status = policyprovider.PolicyStatusActive
+	}
+
+	if err := h.repo.CreatePolicy(r.Context(), id, req.Version, []byte(req.Content), req.Target, status); err != nil {
+		h.writeRepositoryError(w, err, "Failed to create policy version", "policy_id", id, "version", req.Version)
+		return
+	}
+
+	h.logger.Info("Policy version created", "policy_id", id, "version", req.Version, "activated", req.Activate)
+	h.writePolicyVersion(w, r, http.StatusCreated, id, req.Version)
+}
+
+// GetPolicyVersion handles GET /policies/{id}/versions/{version} - retrieves the content of a policy version
+func (h *PolicyHandler) GetPolicyVersion(w http.ResponseWriter, r *http.Request) {
+	id, version := r.PathValue("id"), r.PathValue("version")
+
+	policies, err := h.repo.GetPolicies(r.Context(), []policyprovider.GetPolicyRequest{{ID: id, Version: version}})
+	if err != nil {
+		h.writeRepositoryError(w, err, "Failed to retrieve policy version", "policy_id", id, "version", version)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, PolicyContentResponse{
+		ID:      policies[0].ID,
+		Version: policies[0].Version,
+		Content: string(policies[0].Content),
+	})
+}
+
+// ActivatePolicyVersion handles POST /policies/{id}/versions/{version}/activate - promotes a version to active
+func (h *PolicyHandler) ActivatePolicyVersion(w http.ResponseWriter, r *http.Request) {
+	id, version := r.PathValue("id"), r.PathValue("version")
+
+	if err := h.repo.ActivatePolicy(r.Context(), id, version); err != nil {
+		h.writeRepositoryError(w, err, "Failed to activate policy version", "policy_id", id, "version", version)
+		return
+	}
+
+	h.logger.Info("Policy version activated", "policy_id", id, "version", version)
+	h.writePolicyVersion(w, r, http.StatusOK, id, version)
+}
+
+// ArchivePolicyVersion handles DELETE /policies/{id}/versions/{version} - retires a policy version
+func (h *PolicyHandler) ArchivePolicyVersion(w http.ResponseWriter, r *http.Request) {
+	id, version := r.PathValue("id"), r.PathValue("version")
+
+	if err := h.repo.ArchivePolicy(r.Context(), id, version); err != nil {
+		h.writeRepositoryError(w, err, "Failed to archive policy version", "policy_id", id, "version", version)
+		return
+	}
+
+	h.logger.Info("Policy version archived", "policy_id", id, "version", version)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RollbackPolicy handles POST /policies/{id}/rollback - reactivates the previously active version of a policy
+func (h *PolicyHandler) RollbackPolicy(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	version, err := h.repo.RollbackPolicy(r.Context(), id)
+	if err != nil {
+		h.writeRepositoryError(w, err, "Failed to roll back policy", "policy_id", id)
+		return
+	}
+
+	h.logger.Info("Policy rolled back", "policy_id", id, "version", version.Version)
+	WriteJSONResponse(w, http.StatusOK, newPolicyVersionResponse(version))
+}
+
+// DiffPolicyVersions handles GET /policies/{id}/diff?from={version}&to={version} - compares two versions of a policy
+func (h *PolicyHandler) DiffPolicyVersions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+
+	if from == "" || to == "" {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request", "Query parameters from and to are required")
+		return
+	}
+
+	policies, err := h.repo.GetPolicies(r.Context(), []policyprovider.GetPolicyRequest{
+		{ID: id, Version: from},
+		{ID: id, Version: to},
+	})
+	if err != nil {
+		h.writeRepositoryError(w, err, "Failed to retrieve policy versions", "policy_id", id, "from", from, "to", to)
+		return
+	}
+
+	fromLines, toLines := splitLines(string(policies[0].Content)), splitLines(string(policies[1].Content))
+	if len(fromLines) > maxDiffLines || len(toLines) > maxDiffLines {
+		WriteErrorResponse(w, http.StatusUnprocessableEntity, "Policy versions too large",
+			fmt.Sprintf("Policy versions longer than %d lines cannot be diffed", maxDiffLines))
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, PolicyDiffResponse{
+		ID:    id,
+		From:  policies[0].Version,
+		To:    policies[1].Version,
+		Lines: diffLines(fromLines, toLines),
+	})
+}
+
+// ValidatePolicy handles POST /policies/{id}/validate - compiles policy content without storing it
+func (h *PolicyHandler) ValidatePolicy(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req ValidatePolicyRequest
+	if !decodePolicyRequest(w, r, &req) {
+		return
+	}
+
+	if req.Content == "" {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request", "Content is required")
+		return
+	}
+
+	if !h.validate(w, r, id, "", req.Content) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodePolicyRequest decodes the JSON request body into req, bounded by maxPolicyRequestBytes, writing an error
+// response and returning false if it cannot be decoded
+func decodePolicyRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPolicyRequestBytes)).Decode(req)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Request body too large",
+			fmt.Sprintf("The request body must not exceed %d bytes", maxBytesErr.Limit))
+		return false
+	}
+
+	WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+	return false
+}
+
+// validate compiles the policy content, writing an unprocessable entity response and returning false if it is invalid
+func (h *PolicyHandler) validate(w http.ResponseWriter, r *http.Request, id, version, content string) bool {
+	policy := decisionmaker.Policy{ID: id, Version: version, Content: []byte(content)}
+	if err := h.validator.Validate(r.Context(), []decisionmaker.Policy{policy}); err != nil {
+		h.logger.Warn("Policy validation failed", "policy_id", id, "version", version, "error", err)
+		WriteErrorResponse(w, http.StatusUnprocessableEntity, "Invalid policy", err.Error())
+		return false
+	}
+
+	return true
+}
+
+// writePolicyVersion writes the stored metadata of a policy version
+func (h *PolicyHandler) writePolicyVersion(w http.ResponseWriter, r *http.Request, statusCode int, id, version string) {
+	versions, err := h.repo.ListPolicyVersions(r.Context(), id)
+	if err != nil {
+		h.writeRepositoryError(w, err, "Failed to retrieve policy version", "policy_id", id, "version", version)
+		return
+	}
+
+	for i := range versions {
+		if versions[i].Version == version {
+			WriteJSONResponse(w, statusCode, newPolicyVersionResponse(&versions[i]))
+			return
+		}
+	}
+
+	WriteErrorResponse(w, http.StatusNotFound, "Policy not found", "The requested policy could not be found")
+}
+
+// writeRepositoryError maps policy storage errors to HTTP responses, logging unexpected errors
+func (h *PolicyHandler) writeRepositoryError(w http.ResponseWriter, err error, message string, args ...any) {
+	var notFoundErr *repository.NotFoundError
+	switch {
+	case errors.As(err, &notFoundErr), errors.Is(err, policyprovider.ErrPolicyNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, "Policy not found", "The requested policy could not be found")
+	case errors.Is(err, policyprovider.ErrPolicyVersionExists):
+		WriteErrorResponse(w, http.StatusConflict, message, "The policy version already exists")
+	case errors.Is(err, policyprovider.ErrNoPreviousVersion):
+		WriteErrorResponse(w, http.StatusConflict, message, "The policy has no previously active version")
+	default:
+		h.logger.Error(message, append(args, "error", err)...)
+		WriteErrorResponse(w, http.StatusInternalServerError, message, "An internal error occurred while processing your request")
+	}
+}
+
+// newPolicyVersionResponse converts a stored policy version to its response
+func newPolicyVersionResponse(version *policyprovider.PolicyVersion) PolicyVersionResponse {
+	return PolicyVersionResponse{
+		ID:          version.ID,
+		Version:     version.Version,
+		Status:      string(version.Status),
+		ContentHash: version.ContentHash,
+		CreatedAt:   version.CreatedAt,
+		ActivatedAt: version.ActivatedAt,
+		Target:      version.Target,
+	}
+}
+
+// diffLines returns the lines of a line-based diff from one list of lines to another, prefixed with "-" for removed
+// lines, "+" for added lines and " " for unchanged lines
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+"+b[j])
+	}
+
+	return lines
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,511 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/decisionmaker"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/CameronXie/access-control-explorer/examples/abac/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPolicyRepository struct {
+	mock.Mock
+}
+
+func (m *mockPolicyRepository) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	args := m.Called(ctx, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]policyprovider.PolicyResponse), args.Error(1)
+}
+
+func (m *mockPolicyRepository) CreatePolicy(
+	ctx context.Context,
+	id, version string,
+	content []byte,
+	target policyprovider.PolicyTarget,
+	status policyprovider.PolicyStatus,
+) error {
+	args := m.Called(ctx, id, version, content, target, status)
+	return args.Error(0)
+}
+
+func (m *mockPolicyRepository) ActivatePolicy(ctx context.Context, id, version string) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *mockPolicyRepository) RollbackPolicy(ctx context.Context, id string) (*policyprovider.PolicyVersion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*policyprovider.PolicyVersion), args.Error(1)
+}
+
+func (m *mockPolicyRepository) ArchivePolicy(ctx context.Context, id, version string) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *mockPolicyRepository) ListPolicyVersions(ctx context.Context, id string) ([]policyprovider.PolicyVersion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]policyprovider.PolicyVersion), args.Error(1)
+}
+
+type mockPolicyValidator struct {
+	mock.Mock
+}
+
+func (m *mockPolicyValidator) Validate(ctx context.Context, policies []decisionmaker.Policy) error {
+	args := m.Called(ctx, policies)
+	return args.Error(0)
+}
+
+func TestPolicyHandler_CreatePolicyVersion(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	content := "package abac.subject\n"
+	target := policyprovider.PolicyTarget{
+		ActionIDs:  []string{"read"},
+		Attributes: []policyprovider.AttributeMatch{{Category: policyprovider.AttributeCategorySubject, Attribute: "roles"}},
+	}
+
+	testCases := map[string]struct {
+		requestBody     string
+		target          policyprovider.PolicyTarget
+		validationError error
+		createError     error
+		expectCreate    bool
+		activate        bool
+		expectedStatus  int
+		expectedMessage string
+	}{
+		"should create draft version": {
+			requestBody:    fmt.Sprintf(`{"version": "v2", "content": %q}`, content),
+			expectCreate:   true,
+			expectedStatus: http.StatusCreated,
+		},
+
+		"should create active version": {
+			requestBody:    fmt.Sprintf(`{"version": "v2", "content": %q, "activate": true}`, content),
+			expectCreate:   true,
+			activate:       true,
+			expectedStatus: http.StatusCreated,
+		},
+
+		"should create version with target": {
+			requestBody: fmt.Sprintf(
+				`{"version": "v2", "content": %q, "target": {"actionIds": ["read"], "attributes": [{"category": "subject", "attribute": "roles"}]}}`,
+				content,
+			),
+			target:         target,
+			expectCreate:   true,
+			expectedStatus: http.StatusCreated,
+		},
+
+		"should return bad request when target has unknown attribute category": {
+			requestBody: fmt.Sprintf(
+				`{"version": "v2", "content": %q, "target": {"attributes": [{"category": "tenant", "attribute": "id"}]}}`,
+				content,
+			),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: `attribute match at index 0 has unknown category "tenant"`,
+		},
+
+		"should return bad request when body is not JSON": {
+			requestBody:     `invalid json`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid character",
+		},
+
+		"should return request entity too large when body exceeds the limit": {
+			requestBody:     fmt.Sprintf(`{"version": "v2", "content": %q}`, strings.Repeat("#", maxPolicyRequestBytes)),
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedMessage: "The request body must not exceed 1048576 bytes",
+		},
+
+		"should return bad request when version is missing": {
+			requestBody:     fmt.Sprintf(`{"content": %q}`, content),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Version and content are required",
+		},
+
+		"should return unprocessable entity when policy does not compile": {
+			requestBody:     fmt.Sprintf(`{"version": "v2", "content": %q}`, content),
+			validationError: errors.New("policy validation failed: rego_parse_error"),
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "policy validation failed: rego_parse_error",
+		},
+
+		"should return conflict when version exists": {
+			requestBody:     fmt.Sprintf(`{"version": "v2", "content": %q}`, content),
+			createError:     fmt.Errorf("failed to create policy rbac.rego@v2: %w", policyprovider.ErrPolicyVersionExists),
+			expectCreate:    true,
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: "The policy version already exists",
+		},
+
+		"should return internal server error when creating active version fails": {
+			requestBody:     fmt.Sprintf(`{"version": "v2", "content": %q, "activate": true}`, content),
+			createError:     errors.New("database connection failed"),
+			expectCreate:    true,
+			activate:        true,
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "An internal error occurred while processing your request",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := &mockPolicyRepository{}
+			mockValidator := &mockPolicyValidator{}
+			handler := NewPolicyHandler(mockRepo, mockValidator, newTestLogger().getLogger())
+
+			mockValidator.On("Validate", mock.Anything, []decisionmaker.Policy{
+				{ID: "rbac.rego", Version: "v2", Content: []byte(content)},
+			}).Maybe().Return(tc.validationError)
+
+			status := policyprovider.PolicyStatusDraft
+			if tc.activate {
+				status = policyprovider.PolicyStatusActive
+			}
+
+			if tc.expectCreate {
+				mockRepo.On("CreatePolicy", mock.Anything, "rbac.rego", "v2", []byte(content), tc.target, status).
+					Return(tc.createError)
+			}
+			mockRepo.On("ListPolicyVersions", mock.Anything, "rbac.rego").Maybe().Return([]policyprovider.PolicyVersion{
+				{ID: "rbac.rego", Version: "v2", Status: status, ContentHash: "hash", CreatedAt: createdAt, Target: tc.target},
+				{ID: "rbac.rego", Version: "v1", Status: policyprovider.PolicyStatusActive, CreatedAt: createdAt},
+			}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/policies/rbac.rego/versions", bytes.NewBufferString(tc.requestBody))
+			req.SetPathValue("id", "rbac.rego")
+			w := httptest.NewRecorder()
+
+			handler.CreatePolicyVersion(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			if tc.expectedMessage != "" {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				assert.Contains(t, errorResponse.Message, tc.expectedMessage)
+			} else {
+				var response PolicyVersionResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, PolicyVersionResponse{
+					ID:          "rbac.rego",
+					Version:     "v2",
+					Status:      string(status),
+					ContentHash: "hash",
+					CreatedAt:   createdAt,
+					Target:      tc.target,
+				}, response)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyHandler_ListPolicyVersions(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		mockVersions    []policyprovider.PolicyVersion
+		mockError       error
+		expectedStatus  int
+		expectedBody    []PolicyVersionResponse
+		expectedMessage string
+	}{
+		"should list policy versions": {
+			mockVersions: []policyprovider.PolicyVersion{
+				{ID: "rbac.rego", Version: "v2", Status: policyprovider.PolicyStatusActive, CreatedAt: createdAt, ActivatedAt: &createdAt},
+				{ID: "rbac.rego", Version: "v1", Status: policyprovider.PolicyStatusArchived, CreatedAt: createdAt},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []PolicyVersionResponse{
+				{ID: "rbac.rego", Version: "v2", Status: "active", CreatedAt: createdAt, ActivatedAt: &createdAt},
+				{ID: "rbac.rego", Version: "v1", Status: "archived", CreatedAt: createdAt},
+			},
+		},
+
+		"should return not found when policy has no versions": {
+			mockVersions:    []policyprovider.PolicyVersion{},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "The requested policy could not be found",
+		},
+
+		"should return internal server error when repository fails": {
+			mockError:       errors.New("database connection failed"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "An internal error occurred while processing your request",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := &mockPolicyRepository{}
+			handler := NewPolicyHandler(mockRepo, &mockPolicyValidator{}, newTestLogger().getLogger())
+			mockRepo.On("ListPolicyVersions", mock.Anything, "rbac.rego").Return(tc.mockVersions, tc.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, "/policies/rbac.rego/versions", http.NoBody)
+			req.SetPathValue("id", "rbac.rego")
+			w := httptest.NewRecorder()
+
+			handler.ListPolicyVersions(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			if tc.expectedMessage != "" {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				assert.Contains(t, errorResponse.Message, tc.expectedMessage)
+			} else {
+				var response []PolicyVersionResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedBody, response)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyHandler_ActivatePolicyVersion(t *testing.T) {
+	testCases := map[string]struct {
+		mockError       error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		"should activate policy version": {
+			expectedStatus: http.StatusOK,
+		},
+
+		"should return not found when version does not exist": {
+			mockError:       &repository.NotFoundError{Resource: "policy", Key: "id@version", Value: "rbac.rego@v9"},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "The requested policy could not be found",
+		},
+
+		"should return internal server error when repository fails": {
+			mockError:       errors.New("database connection failed"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "An internal error occurred while processing your request",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := &mockPolicyRepository{}
+			handler := NewPolicyHandler(mockRepo, &mockPolicyValidator{}, newTestLogger().getLogger())
+			mockRepo.On("ActivatePolicy", mock.Anything, "rbac.rego", "v2").Return(tc.mockError)
+			mockRepo.On("ListPolicyVersions", mock.Anything, "rbac.rego").Maybe().Return([]policyprovider.PolicyVersion{
+				{ID: "rbac.rego", Version: "v2", Status: policyprovider.PolicyStatusActive},
+			}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/policies/rbac.rego/versions/v2/activate", http.NoBody)
+			req.SetPathValue("id", "rbac.rego")
+			req.SetPathValue("version", "v2")
+			w := httptest.NewRecorder()
+
+			handler.ActivatePolicyVersion(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			if tc.expectedMessage != "" {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				assert.Contains(t, errorResponse.Message, tc.expectedMessage)
+			} else {
+				var response PolicyVersionResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "active", response.Status)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyHandler_RollbackPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		mockVersion     *policyprovider.PolicyVersion
+		mockError       error
+		expectedStatus  int
+		expectedVersion string
+		expectedMessage string
+	}{
+		"should roll back to previous version": {
+			mockVersion:     &policyprovider.PolicyVersion{ID: "rbac.rego", Version: "v1", Status: policyprovider.PolicyStatusActive},
+			expectedStatus:  http.StatusOK,
+			expectedVersion: "v1",
+		},
+
+		"should return conflict when there is no previous version": {
+			mockError:       fmt.Errorf("failed to roll back policy rbac.rego: %w", policyprovider.ErrNoPreviousVersion),
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: "The policy has no previously active version",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := &mockPolicyRepository{}
+			handler := NewPolicyHandler(mockRepo, &mockPolicyValidator{}, newTestLogger().getLogger())
+			mockRepo.On("RollbackPolicy", mock.Anything, "rbac.rego").Return(tc.mockVersion, tc.mockError)
+
+			req := httptest.NewRequest(http.MethodPost, "/policies/rbac.rego/rollback", http.NoBody)
+			req.SetPathValue("id", "rbac.rego")
+			w := httptest.NewRecorder()
+
+			handler.RollbackPolicy(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			if tc.expectedMessage != "" {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				assert.Contains(t, errorResponse.Message, tc.expectedMessage)
+			} else {
+				var response PolicyVersionResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedVersion, response.Version)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPolicyHandler_DiffPolicyVersions(t *testing.T) {
+	testCases := map[string]struct {
+		query           string
+		mockPolicies    []policyprovider.PolicyResponse
+		mockError       error
+		expectedStatus  int
+		expectedBody    *PolicyDiffResponse
+		expectedMessage string
+	}{
+		"should diff resolved versions": {
+			query: "?from=v1&to=active",
+			mockPolicies: []policyprovider.PolicyResponse{
+				{ID: "rbac.rego", Version: "v1", Content: []byte("package rbac\n\nallow := false\n")},
+				{ID: "rbac.rego", Version: "v2", Content: []byte("package rbac\n\nallow := true\n")},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &PolicyDiffResponse{
+				ID:    "rbac.rego",
+				From:  "v1",
+				To:    "v2",
+				Lines: []string{" package rbac", " ", "-allow := false", "+allow := true"},
+			},
+		},
+
+		"should return bad request when versions are missing": {
+			query:           "?from=v1",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Query parameters from and to are required",
+		},
+
+		"should return unprocessable entity when versions are too large to diff": {
+			query: "?from=v1&to=v2",
+			mockPolicies: []policyprovider.PolicyResponse{
+				{ID: "rbac.rego", Version: "v1", Content: []byte("package rbac\n")},
+				{ID: "rbac.rego", Version: "v2", Content: []byte(strings.Repeat("allow := true\n", maxDiffLines+1))},
+			},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "Policy versions longer than 2000 lines cannot be diffed",
+		},
+
+		"should return not found when version does not exist": {
+			query:           "?from=v1&to=v9",
+			mockError:       fmt.Errorf("failed to get policy rbac.rego@v9: %w", policyprovider.ErrPolicyNotFound),
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "The requested policy could not be found",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := &mockPolicyRepository{}
+			handler := NewPolicyHandler(mockRepo, &mockPolicyValidator{}, newTestLogger().getLogger())
+			mockRepo.On("GetPolicies", mock.Anything, mock.Anything).Maybe().Return(tc.mockPolicies, tc.mockError)
+
+			req := httptest.NewRequest(http.MethodGet, "/policies/rbac.rego/diff"+tc.query, http.NoBody)
+			req.SetPathValue("id", "rbac.rego")
+			w := httptest.NewRecorder()
+
+			handler.DiffPolicyVersions(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+
+			if tc.expectedMessage != "" {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				assert.Contains(t, errorResponse.Message, tc.expectedMessage)
+				return
+			}
+
+			var response PolicyDiffResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, *tc.expectedBody, response)
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	testCases := map[string]struct {
+		from     string
+		to       string
+		expected []string
+	}{
+		"should mark all lines unchanged for equal texts": {
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			expected: []string{" a", " b"},
+		},
+
+		"should mark added and removed lines": {
+			from:     "a\nb\nc",
+			to:       "a\nc\nd",
+			expected: []string{" a", "-b", " c", "+d"},
+		},
+
+		"should mark all lines added for empty source": {
+			from:     "",
+			to:       "a\nb",
+			expected: []string{"+a", "+b"},
+		},
+
+		"should return no lines for empty texts": {
+			expected: []string{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, diffLines(splitLines(tc.from), splitLines(tc.to)))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	ro "github.com/CameronXie/access-control-explorer/abac/requestorchestrator"
+	"github.com/CameronXie/access-control-explorer/examples/abac/internal/enforcer"
+	ip "github.com/CameronXie/access-control-explorer/examples/abac/internal/infoprovider"
+)
+
+const (
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+var policyPathPattern = regexp.MustCompile(`^/policies/([^/]+)(?:/.*)?$`)
+
+type policyExtractor struct {
+	action string
+}
+
+// NewPolicyExtractor creates a policy operation extractor for the policy administration API.
+// The resource ID is the policy ID in the /policies/{id} path prefix.
+func NewPolicyExtractor(action string) enforcer.OperationExtractor {
+	return &policyExtractor{
+		action: action,
+	}
+}
+
+// Extract extracts operation details from HTTP request
+func (e *policyExtractor) Extract(_ context.Context, r *http.Request) (*enforcer.Operation, error) {
+	id, err := ExtractPolicyIDFromPath(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract policy ID: %w", err)
+	}
+
+	return &enforcer.Operation{
+		Action:   ro.Action{ID: e.action},
+		Resource: ro.Resource{Type: string(ip.InfoTypePolicy), ID: id},
+	}, nil
+}
+
+// ExtractPolicyIDFromPath extracts policy ID from URL paths starting with /policies/{id}
+func ExtractPolicyIDFromPath(r *http.Request) (string, error) {
+	matches := policyPathPattern.FindStringSubmatch(r.URL.Path)
+	if len(matches) < 2 {
+		return "", fmt.Errorf("path %q does not match /policies/{id} pattern", r.URL.Path)
+	}
+
+	return matches[1], nil
+}
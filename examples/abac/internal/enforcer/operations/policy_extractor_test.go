@@ -0,0 +1,79 @@
+package operations
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	ro "github.com/CameronXie/access-control-explorer/abac/requestorchestrator"
+	"github.com/CameronXie/access-control-explorer/examples/abac/internal/enforcer"
+	ip "github.com/CameronXie/access-control-explorer/examples/abac/internal/infoprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyExtractor_Extract(t *testing.T) {
+	testCases := map[string]struct {
+		action        string
+		requestPath   string
+		expectedOp    *enforcer.Operation
+		expectedError string
+	}{
+		"should extract policy ID from versions path": {
+			action:      ActionCreate,
+			requestPath: "/policies/rbac.rego/versions",
+			expectedOp: &enforcer.Operation{
+				Action:   ro.Action{ID: ActionCreate},
+				Resource: ro.Resource{Type: string(ip.InfoTypePolicy), ID: "rbac.rego"},
+			},
+		},
+
+		"should extract policy ID from nested version path": {
+			action:      ActionUpdate,
+			requestPath: "/policies/rbac.rego/versions/v2/activate",
+			expectedOp: &enforcer.Operation{
+				Action:   ro.Action{ID: ActionUpdate},
+				Resource: ro.Resource{Type: string(ip.InfoTypePolicy), ID: "rbac.rego"},
+			},
+		},
+
+		"should extract policy ID from policy path": {
+			action:      ActionRead,
+			requestPath: "/policies/default.rego",
+			expectedOp: &enforcer.Operation{
+				Action:   ro.Action{ID: ActionRead},
+				Resource: ro.Resource{Type: string(ip.InfoTypePolicy), ID: "default.rego"},
+			},
+		},
+
+		"should fail without policy ID": {
+			action:        ActionRead,
+			requestPath:   "/policies",
+			expectedError: "failed to extract policy ID: path \"/policies\" does not match /policies/{id} pattern",
+		},
+
+		"should fail with wrong resource path": {
+			action:        ActionRead,
+			requestPath:   "/orders/rbac.rego",
+			expectedError: "does not match /policies/{id} pattern",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, tc.requestPath, http.NoBody)
+			require.NoError(t, err)
+
+			operation, err := NewPolicyExtractor(tc.action).Extract(context.Background(), req)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, operation)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOp, operation)
+		})
+	}
+}
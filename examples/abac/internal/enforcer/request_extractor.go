@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 
+	ip "github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider/environment"
 	ro "github.com/CameronXie/access-control-explorer/abac/requestorchestrator"
 	"github.com/CameronXie/access-control-explorer/examples/abac/pkg/trie"
 )
@@ -31,6 +33,7 @@ type RequestExtractorOption func(*requestExtractor) error
 
 type requestExtractor struct {
 	subjectExtractor       SubjectExtractor
+	environmentProvider    ip.InfoProvider
 	operationExtractorTrie *trie.Node[map[string]OperationExtractor]
 }
 
@@ -59,6 +62,18 @@ func WithSubjectExtractor(extractor SubjectExtractor) RequestExtractorOption {
 	}
 }
 
+// WithEnvironmentProvider sets the provider of the environment attributes of requests, given the HTTP request as params
+// under the environment info type
+func WithEnvironmentProvider(provider ip.InfoProvider) RequestExtractorOption {
+	return func(re *requestExtractor) error {
+		if provider == nil {
+			return fmt.Errorf("environment provider cannot be nil")
+		}
+		re.environmentProvider = provider
+		return nil
+	}
+}
+
 // WithOperationExtractor registers an OperationExtractor for specific path and method
 func WithOperationExtractor(path, method string, extractor OperationExtractor) RequestExtractorOption {
 	return func(re *requestExtractor) error {
@@ -133,13 +148,37 @@ func (re *requestExtractor) Extract(ctx context.Context, r *http.Request) (*ro.A
 		return nil, fmt.Errorf("failed to extract operation: %w", err)
 	}
 
+	// Extract environment
+	env, err := re.extractEnvironment(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract environment: %w", err)
+	}
+
 	return &ro.AccessRequest{
-		Subject:  *subject,
-		Action:   operation.Action,
-		Resource: operation.Resource,
+		Subject:     *subject,
+		Action:      operation.Action,
+		Resource:    operation.Resource,
+		Environment: env,
 	}, nil
 }
 
+// extractEnvironment fetches the environment attributes of the HTTP request, if an environment provider is set
+func (re *requestExtractor) extractEnvironment(ctx context.Context, r *http.Request) (map[string]any, error) {
+	if re.environmentProvider == nil {
+		return nil, nil
+	}
+
+	resp, err := re.environmentProvider.GetInfo(ctx, &ip.GetInfoRequest{
+		InfoType: environment.InfoType,
+		Params:   r,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Info, nil
+}
+
 // extractOperation extracts operation from HTTP request using registered extractors
 func (re *requestExtractor) extractOperation(ctx context.Context, r *http.Request) (*Operation, error) {
 	pathSegments := parsePathSegments(r.URL.Path)
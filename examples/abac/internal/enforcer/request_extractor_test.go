@@ -11,6 +11,8 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	ip "github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider/environment"
 	ro "github.com/CameronXie/access-control-explorer/abac/requestorchestrator"
 	"github.com/CameronXie/access-control-explorer/examples/abac/pkg/trie"
 )
@@ -40,6 +42,12 @@ func (m *mockOperationExtractor) Extract(ctx context.Context, r *http.Request) (
 	return args.Get(0).(*Operation), args.Error(1)
 }
 
+type infoProviderFunc func(ctx context.Context, req *ip.GetInfoRequest) (*ip.GetInfoResponse, error)
+
+func (f infoProviderFunc) GetInfo(ctx context.Context, req *ip.GetInfoRequest) (*ip.GetInfoResponse, error) {
+	return f(ctx, req)
+}
+
 func TestNewRequestExtractor(t *testing.T) {
 	testCases := map[string]struct {
 		options       []RequestExtractorOption
@@ -79,6 +87,14 @@ func TestNewRequestExtractor(t *testing.T) {
 			expectedError: "method cannot be empty",
 		},
 
+		"should fail when nil environment provider provided": {
+			options: []RequestExtractorOption{
+				WithSubjectExtractor(&mockSubjectExtractor{}),
+				WithEnvironmentProvider(nil),
+			},
+			expectedError: "environment provider cannot be nil",
+		},
+
 		"should fail when nil operation extractor provided": {
 			options: []RequestExtractorOption{
 				WithSubjectExtractor(&mockSubjectExtractor{}),
@@ -130,6 +146,7 @@ func TestRequestExtractor_Extract(t *testing.T) {
 		operation              *Operation
 		operationError         error
 		shouldExtractOperation bool
+		environmentProvider    ip.InfoProvider
 		request                *http.Request
 		expectedResult         *ro.AccessRequest
 		expectedError          string
@@ -157,6 +174,53 @@ func TestRequestExtractor_Extract(t *testing.T) {
 			},
 		},
 
+		"should extract environment attributes": {
+			subject: &ro.Subject{
+				ID:   "user123",
+				Type: "users",
+			},
+			operation: &Operation{
+				Action:   ro.Action{ID: "read"},
+				Resource: ro.Resource{Type: "documents"},
+			},
+			opExtractorPath:        "/documents",
+			opExtractorMethod:      http.MethodGet,
+			shouldExtractOperation: true,
+			environmentProvider: infoProviderFunc(func(_ context.Context, req *ip.GetInfoRequest) (*ip.GetInfoResponse, error) {
+				r := req.Params.(*http.Request)
+				return &ip.GetInfoResponse{Info: map[string]any{"info_type": req.InfoType, "request_path": r.URL.Path}}, nil
+			}),
+			request: createTestRequest("GET", "/documents"),
+			expectedResult: &ro.AccessRequest{
+				Subject: ro.Subject{
+					ID:   "user123",
+					Type: "users",
+				},
+				Action:      ro.Action{ID: "read"},
+				Resource:    ro.Resource{Type: "documents"},
+				Environment: map[string]any{"info_type": environment.InfoType, "request_path": "/documents"},
+			},
+		},
+
+		"should fail when environment extraction fails": {
+			subject: &ro.Subject{
+				ID:   "user123",
+				Type: "users",
+			},
+			operation: &Operation{
+				Action:   ro.Action{ID: "read"},
+				Resource: ro.Resource{Type: "documents"},
+			},
+			opExtractorPath:        "/documents",
+			opExtractorMethod:      http.MethodGet,
+			shouldExtractOperation: true,
+			environmentProvider: infoProviderFunc(func(_ context.Context, _ *ip.GetInfoRequest) (*ip.GetInfoResponse, error) {
+				return nil, errors.New("clock unavailable")
+			}),
+			request:       createTestRequest("GET", "/documents"),
+			expectedError: "failed to extract environment: clock unavailable",
+		},
+
 		"should fail when subject extraction fails": {
 			subjectError: errors.New("subject extraction failed"),
 			operation: &Operation{
@@ -251,10 +315,15 @@ func TestRequestExtractor_Extract(t *testing.T) {
 				opExtractor.On("Extract", mock.Anything, tc.request).Return(tc.operation, tc.operationError)
 			}
 
-			extractor, err := NewRequestExtractor(
+			options := []RequestExtractorOption{
 				WithSubjectExtractor(subjectExtractor),
 				WithOperationExtractor(tc.opExtractorPath, tc.opExtractorMethod, opExtractor),
-			)
+			}
+			if tc.environmentProvider != nil {
+				options = append(options, WithEnvironmentProvider(tc.environmentProvider))
+			}
+
+			extractor, err := NewRequestExtractor(options...)
 			require.NoError(t, err)
 
 			// Execute
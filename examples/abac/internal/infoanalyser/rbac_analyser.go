@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/CameronXie/access-control-explorer/abac/requestorchestrator"
 	ip "github.com/CameronXie/access-control-explorer/examples/abac/internal/infoprovider"
-	"github.com/CameronXie/access-control-explorer/examples/abac/internal/requestorchestrator"
 )
 
 type rbacAnalyser struct {
@@ -1,9 +1,6 @@
 package infoprovider
 
 import (
-	"context"
-	"fmt"
-
 	ip "github.com/CameronXie/access-control-explorer/abac/infoprovider"
 )
 
@@ -11,34 +8,26 @@ import (
 type InfoType string
 
 const (
-	InfoTypeUser  InfoType = "user"
-	InfoTypeOrder InfoType = "order"
-	InfoTypeRBAC  InfoType = "rbac"
+	InfoTypeUser   InfoType = "user"
+	InfoTypeOrder  InfoType = "order"
+	InfoTypeRBAC   InfoType = "rbac"
+	InfoTypePolicy InfoType = "policy"
 )
 
-// infoProvider manages a collection of InfoProvider implementations mapped by type.
-// It directs requests to the appropriate provider based on the request type.
-type infoProvider struct {
-	providers map[InfoType]ip.InfoProvider
-}
-
-// NewInfoProvider creates and returns an InfoProvider instance that routes requests based on their type using the given map.
-func NewInfoProvider(providers map[InfoType]ip.InfoProvider) ip.InfoProvider {
-	return &infoProvider{
-		providers: providers,
+// Schemas returns the attribute schemas of the info types whose responses feed the policies.
+func Schemas() map[string]ip.Schema {
+	return map[string]ip.Schema{
+		string(InfoTypeUser): {Attributes: []ip.AttributeDefinition{
+			{Name: "roles", Type: ip.AttributeTypeString, MultiValued: true, Required: true},
+			{Name: "department", Type: ip.AttributeTypeString},
+			{Name: "region", Type: ip.AttributeTypeString},
+		}},
+		string(InfoTypeOrder): {Attributes: []ip.AttributeDefinition{
+			{Name: "owner", Type: ip.AttributeTypeString},
+		}},
+		string(InfoTypeRBAC): {Attributes: []ip.AttributeDefinition{
+			{Name: "role_hierarchy", Type: ip.AttributeTypeObject, Required: true},
+			{Name: "role_permissions", Type: ip.AttributeTypeObject, Required: true},
+		}},
 	}
 }
-
-// GetInfo routes the request to the appropriate provider based on req.Type
-func (p *infoProvider) GetInfo(ctx context.Context, req *ip.GetInfoRequest) (*ip.GetInfoResponse, error) {
-	if req == nil {
-		return nil, fmt.Errorf("request cannot be nil")
-	}
-
-	provider, ok := p.providers[InfoType(req.InfoType)]
-	if !ok {
-		return nil, fmt.Errorf("unsupported info type %s", req.InfoType)
-	}
-
-	return provider.GetInfo(ctx, req)
-}
@@ -0,0 +1,63 @@
+package infoprovider
+
+import (
+	"context"
+	"fmt"
+
+	ip "github.com/CameronXie/access-control-explorer/abac/infoprovider"
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+)
+
+// PolicyVersionsRepository defines the contract for policy version operations
+type PolicyVersionsRepository interface {
+	ListPolicyVersions(ctx context.Context, id string) ([]policyprovider.PolicyVersion, error)
+}
+
+// policyProvider implements InfoProvider for policies managed through the policy administration API
+type policyProvider struct {
+	policyRepo PolicyVersionsRepository
+}
+
+// NewPolicyProvider creates a new policy info provider with dependency injection
+func NewPolicyProvider(policyRepo PolicyVersionsRepository) ip.InfoProvider {
+	return &policyProvider{
+		policyRepo: policyRepo,
+	}
+}
+
+// GetInfo retrieves policy attributes based on the provided request containing a policy ID.
+// Attributes are the active version and latest version of the policy; an unknown policy has no attributes.
+func (p *policyProvider) GetInfo(ctx context.Context, req *ip.GetInfoRequest) (*ip.GetInfoResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	policyID, ok := req.Params.(string)
+	if !ok {
+		return nil, fmt.Errorf("policy ID parameter must be a string, got %T: %v", req.Params, req.Params)
+	}
+
+	attrs := make(map[string]any)
+	if policyID == "" {
+		return &ip.GetInfoResponse{Info: attrs}, nil
+	}
+
+	versions, err := p.policyRepo.ListPolicyVersions(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions are listed most recently created first
+	if len(versions) > 0 {
+		attrs["latest_version"] = versions[0].Version
+	}
+
+	for i := range versions {
+		if versions[i].Status == policyprovider.PolicyStatusActive {
+			attrs["active_version"] = versions[i].Version
+			break
+		}
+	}
+
+	return &ip.GetInfoResponse{Info: attrs}, nil
+}
@@ -0,0 +1,100 @@
+package infoprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	ip "github.com/CameronXie/access-control-explorer/abac/infoprovider"
+)
+
+// mockPolicyVersionsRepository is a mock implementation of PolicyVersionsRepository
+type mockPolicyVersionsRepository struct {
+	mock.Mock
+}
+
+func (m *mockPolicyVersionsRepository) ListPolicyVersions(ctx context.Context, id string) ([]policyprovider.PolicyVersion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]policyprovider.PolicyVersion), args.Error(1)
+}
+
+func TestPolicyProvider_GetInfo(t *testing.T) {
+	testCases := map[string]struct {
+		request          *ip.GetInfoRequest
+		mockVersions     []policyprovider.PolicyVersion
+		mockErr          error
+		expectedResponse *ip.GetInfoResponse
+		expectedError    string
+		shouldCallMock   bool
+	}{
+		"should return active and latest versions": {
+			request: &ip.GetInfoRequest{Params: "rbac.rego"},
+			mockVersions: []policyprovider.PolicyVersion{
+				{ID: "rbac.rego", Version: "v3", Status: policyprovider.PolicyStatusDraft},
+				{ID: "rbac.rego", Version: "v2", Status: policyprovider.PolicyStatusActive},
+				{ID: "rbac.rego", Version: "v1", Status: policyprovider.PolicyStatusArchived},
+			},
+			expectedResponse: &ip.GetInfoResponse{
+				Info: map[string]any{"active_version": "v2", "latest_version": "v3"},
+			},
+			shouldCallMock: true,
+		},
+
+		"should return empty attributes for unknown policy": {
+			request:          &ip.GetInfoRequest{Params: "unknown.rego"},
+			mockVersions:     []policyprovider.PolicyVersion{},
+			expectedResponse: &ip.GetInfoResponse{Info: map[string]any{}},
+			shouldCallMock:   true,
+		},
+
+		"should return empty attributes without policy ID": {
+			request:          &ip.GetInfoRequest{Params: ""},
+			expectedResponse: &ip.GetInfoResponse{Info: map[string]any{}},
+		},
+
+		"should return error when request is nil": {
+			request:       nil,
+			expectedError: "request cannot be nil",
+		},
+
+		"should return error when params is not string": {
+			request:       &ip.GetInfoRequest{Params: 12345},
+			expectedError: "policy ID parameter must be a string, got int: 12345",
+		},
+
+		"should return error when repository returns database error": {
+			request:        &ip.GetInfoRequest{Params: "rbac.rego"},
+			mockErr:        errors.New("database connection failed"),
+			expectedError:  "database connection failed",
+			shouldCallMock: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockPolicyVersionsRepository)
+			if tc.shouldCallMock {
+				mockRepo.On("ListPolicyVersions", mock.Anything, tc.request.Params).Return(tc.mockVersions, tc.mockErr)
+			}
+
+			response, err := NewPolicyProvider(mockRepo).GetInfo(context.Background(), tc.request)
+
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				assert.Nil(t, response)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResponse, response)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
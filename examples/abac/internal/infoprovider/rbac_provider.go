@@ -49,23 +49,13 @@ func (p *roleBasedAccessProvider) GetInfo(ctx context.Context, req *ip.GetInfoRe
 		return nil, fmt.Errorf("request cannot be nil")
 	}
 
-	// Expecting []string in Params (can come as []any from JSON decode paths, normalize here).
-	switch v := req.Params.(type) {
-	case []string:
-		return p.handle(ctx, v)
-	case []any:
-		roleNames := make([]string, 0, len(v))
-		for _, r := range v {
-			s, ok := r.(string)
-			if !ok {
-				return nil, fmt.Errorf("role names must be []string")
-			}
-			roleNames = append(roleNames, s)
-		}
-		return p.handle(ctx, roleNames)
-	default:
+	// Roles are coerced to []string by the user info schema before they are passed as Params
+	roleNames, ok := req.Params.([]string)
+	if !ok {
 		return nil, fmt.Errorf("role names parameter must be a []string, got %T", req.Params)
 	}
+
+	return p.handle(ctx, roleNames)
 }
 
 func (p *roleBasedAccessProvider) handle(ctx context.Context, roleNames []string) (*ip.GetInfoResponse, error) {
@@ -136,43 +136,6 @@ func TestRoleBasedAccessProvider_GetInfo(t *testing.T) {
 			expectedPermissionsParam: []string{"security_admin", "admin", "user"},
 		},
 
-		"should handle []any params and convert to []string": {
-			request: &ip.GetInfoRequest{
-				Params: []any{"admin", "manager"},
-			},
-			mockDescendantsResp: []string{"admin", "manager", "user"},
-			mockPermissionsResp: map[string][]Permission{
-				"admin": {
-					{
-						ActionName:   "delete",
-						ResourceName: "order",
-						Conditions:   []PermissionCondition{},
-					},
-				},
-			},
-			expectedResponse: &ip.GetInfoResponse{
-				Info: map[string]any{
-					"role_hierarchy": RoleHierarchy{
-						RequestedRoles: []string{"admin", "manager"},
-						Descendants:    []string{"admin", "manager", "user"},
-					},
-					"role_permissions": map[string][]Permission{
-						"admin": {
-							{
-								ActionName:   "delete",
-								ResourceName: "order",
-								Conditions:   []PermissionCondition{},
-							},
-						},
-					},
-				},
-			},
-			shouldCallDescendants:    true,
-			shouldCallPermissions:    true,
-			expectedDescendantsParam: []string{"admin", "manager"},
-			expectedPermissionsParam: []string{"admin", "manager", "user"},
-		},
-
 		"should handle roles with whitespace by trimming": {
 			request: &ip.GetInfoRequest{
 				Params: []string{" admin ", "  manager  ", " user"},
@@ -201,7 +164,7 @@ func TestRoleBasedAccessProvider_GetInfo(t *testing.T) {
 			shouldCallPermissions: false,
 		},
 
-		"should return error when params is not []string or []any": {
+		"should return error when params is not []string": {
 			request: &ip.GetInfoRequest{
 				Params: "invalid-params",
 			},
@@ -210,11 +173,11 @@ func TestRoleBasedAccessProvider_GetInfo(t *testing.T) {
 			shouldCallPermissions: false,
 		},
 
-		"should return error when []any contains non-string elements": {
+		"should return error when params is []any": {
 			request: &ip.GetInfoRequest{
-				Params: []any{"admin", 123, "user"},
+				Params: []any{"admin", "user"},
 			},
-			expectedError:         "role names must be []string",
+			expectedError:         "role names parameter must be a []string, got []interface {}",
 			shouldCallDescendants: false,
 			shouldCallPermissions: false,
 		},
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"fmt"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
 )
 
 // NotFoundError represents an error when a resource is not found
@@ -15,3 +17,8 @@ type NotFoundError struct {
 func (e *NotFoundError) Error() string {
 	return fmt.Sprintf("%s with %s %s not found", e.Resource, e.Key, e.Value)
 }
+
+// Is reports the error as infoprovider.ErrNotFound, so that info providers passing it on report missing info
+func (e *NotFoundError) Is(target error) bool {
+	return target == infoprovider.ErrNotFound
+}
@@ -1,9 +1,13 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/CameronXie/access-control-explorer/abac/infoprovider"
 )
 
 func TestNotFoundError_Error(t *testing.T) {
@@ -28,3 +32,10 @@ func TestNotFoundError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestNotFoundError_Is(t *testing.T) {
+	err := fmt.Errorf("failed to get user: %w", &NotFoundError{Resource: "user", Key: "id", Value: "user123"})
+
+	assert.ErrorIs(t, err, infoprovider.ErrNotFound)
+	assert.False(t, errors.Is(err, errors.New("info not found")))
+}
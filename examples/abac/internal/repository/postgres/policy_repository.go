@@ -0,0 +1,347 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/CameronXie/access-control-explorer/examples/abac/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	PolicyResource = "policy"
+
+	// PolicyVersionLatest resolves to the most recently created version of a policy that is neither draft nor archived
+	PolicyVersionLatest = "latest"
+
+	// PolicyVersionActive resolves to the version of a policy with the active status
+	PolicyVersionActive = "active"
+)
+
+// PolicyStatus represents the lifecycle status of a policy version
+type PolicyStatus = policyprovider.PolicyStatus
+
+const (
+	PolicyStatusDraft    = policyprovider.PolicyStatusDraft
+	PolicyStatusActive   = policyprovider.PolicyStatusActive
+	PolicyStatusArchived = policyprovider.PolicyStatusArchived
+)
+
+// uniqueViolationCode is the Postgres error code raised when a unique constraint is violated
+const uniqueViolationCode = "23505"
+
+// querier is implemented by both pgxpool.Pool and pgx.Tx, so repositories can run inside a caller's transaction
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// PolicyRepository provides Postgres-backed policy storage and implements policyprovider.WritablePolicyProvider and
+// policyprovider.PolicyLister
+type PolicyRepository struct {
+	db querier
+}
+
+// NewPolicyRepository creates a new PolicyRepository instance.
+// Pass a pgxpool.Pool, or a pgx.Tx to make policy changes in the same transaction as other changes, such as RBAC tables.
+func NewPolicyRepository(db querier) *PolicyRepository {
+	return &PolicyRepository{db: db}
+}
+
+// GetPolicies retrieves multiple policies in a single round-trip.
+// Request versions are either concrete, or one of PolicyVersionLatest and PolicyVersionActive, which are resolved to
+// the concrete version returned in the response. Stored content is checked against its content hash.
+func (r *PolicyRepository) GetPolicies(
+	ctx context.Context,
+	reqs []policyprovider.GetPolicyRequest,
+) ([]policyprovider.PolicyResponse, error) {
+	if len(reqs) == 0 {
+		return []policyprovider.PolicyResponse{}, nil
+	}
+
+	ids := make([]string, 0, len(reqs))
+	versions := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		ids = append(ids, req.ID)
+		versions = append(versions, req.Version)
+	}
+
+	const query = `
+SELECT r.ord,
+       p.version,
+       p.content,
+       p.content_hash
+FROM unnest($1::text[], $2::text[]) WITH ORDINALITY AS r(id, version, ord)
+JOIN LATERAL (
+    SELECT p.version,
+           p.content,
+           p.content_hash
+    FROM policies p
+    WHERE p.id = r.id
+      AND CASE r.version
+              WHEN $3 THEN p.status NOT IN ('draft', 'archived')
+              WHEN $4 THEN p.status = 'active'
+              ELSE p.version = r.version
+          END
+    ORDER BY p.created_at DESC, p.version DESC
+    LIMIT 1
+) p ON TRUE
+ORDER BY r.ord;
+`
+	rows, err := r.db.Query(ctx, query, ids, versions, PolicyVersionLatest, PolicyVersionActive)
+	if err != nil {
+		return nil, fmt.Errorf("query policies: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[int]policyprovider.PolicyResponse, len(reqs))
+	var ord int
+	var version, content, contentHash string
+	_, scanErr := pgx.ForEachRow(rows, []any{&ord, &version, &content, &contentHash}, func() error {
+		req := reqs[ord-1]
+		if policyprovider.HashContent([]byte(content)) != contentHash {
+			return fmt.Errorf("policy %s@%s content does not match its hash", req.ID, version)
+		}
+
+		found[ord] = policyprovider.PolicyResponse{ID: req.ID, Version: version, Content: []byte(content), ContentHash: contentHash}
+		return nil
+	})
+	if scanErr != nil {
+		return nil, fmt.Errorf("scan policies: %w", scanErr)
+	}
+
+	policies := make([]policyprovider.PolicyResponse, 0, len(reqs))
+	for i, req := range reqs {
+		policy, ok := found[i+1]
+		if !ok {
+			return nil, fmt.Errorf("failed to get policy %s@%s: %w", req.ID, req.Version, policyprovider.ErrPolicyNotFound)
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// CreatePolicy stores a new version of a policy with the given target and status, computing its content hash.
+// Creating a version with the active status archives the previously active version in the same transaction.
+func (r *PolicyRepository) CreatePolicy(
+	ctx context.Context,
+	id, version string,
+	content []byte,
+	target policyprovider.PolicyTarget,
+	status PolicyStatus,
+) error {
+	query := `
+INSERT INTO policies (id, version, content, content_hash, target, status, activated_at)
+VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $6 = $7 THEN CURRENT_TIMESTAMP END)`
+
+	// Nested in a savepoint when the repository already runs in a transaction
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if status == PolicyStatusActive {
+			if err := archiveActiveVersion(ctx, tx, id, version); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec(
+			ctx,
+			query,
+			id,
+			version,
+			string(content),
+			policyprovider.HashContent(content),
+			target,
+			status,
+			PolicyStatusActive,
+		)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == "policies_pkey" {
+				return fmt.Errorf("failed to create policy %s@%s: %w", id, version, policyprovider.ErrPolicyVersionExists)
+			}
+
+			return fmt.Errorf("failed to create policy %s@%s: %w", id, version, err)
+		}
+
+		return nil
+	})
+}
+
+// ActivatePolicy makes a version the active version of a policy, archiving the previously active version
+func (r *PolicyRepository) ActivatePolicy(ctx context.Context, id, version string) error {
+	// Nested in a savepoint when the repository already runs in a transaction
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return activateVersion(ctx, tx, id, version, true)
+	})
+}
+
+// RollbackPolicy reactivates the archived version most recently active before the current active version.
+// The reactivated version keeps its activation time, so rolling back again steps further back in the history.
+func (r *PolicyRepository) RollbackPolicy(ctx context.Context, id string) (*policyprovider.PolicyVersion, error) {
+	var previous *policyprovider.PolicyVersion
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+SELECT p.version
+FROM policies p
+JOIN policies a ON a.id = p.id AND a.status = $2
+WHERE p.id = $1
+  AND p.status = $3
+  AND p.activated_at < a.activated_at
+ORDER BY p.activated_at DESC
+LIMIT 1`
+
+		var version string
+		err := tx.QueryRow(ctx, query, id, PolicyStatusActive, PolicyStatusArchived).Scan(&version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to roll back policy %s: %w", id, policyprovider.ErrNoPreviousVersion)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find previous version of policy %s: %w", id, err)
+		}
+
+		if err := activateVersion(ctx, tx, id, version, false); err != nil {
+			return err
+		}
+
+		previous, err = getPolicyVersion(ctx, tx, id, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return previous, nil
+}
+
+// ArchivePolicy retires a version of a policy. Its activation time is cleared so it is no longer a rollback target.
+func (r *PolicyRepository) ArchivePolicy(ctx context.Context, id, version string) error {
+	query := "UPDATE policies SET status = $3, activated_at = NULL WHERE id = $1 AND version = $2"
+
+	tag, err := r.db.Exec(ctx, query, id, version, PolicyStatusArchived)
+	if err != nil {
+		return fmt.Errorf("failed to archive policy %s@%s: %w", id, version, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return newPolicyNotFoundError(id, version)
+	}
+
+	return nil
+}
+
+// ListPolicyVersions returns the versions of a policy, most recently created first
+func (r *PolicyRepository) ListPolicyVersions(ctx context.Context, id string) ([]policyprovider.PolicyVersion, error) {
+	query := `
+SELECT id, version, status, content_hash, created_at, activated_at, target
+FROM policies
+WHERE id = $1
+ORDER BY created_at DESC, version DESC`
+
+	rows, err := r.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("query policy versions: %w", err)
+	}
+
+	versions, err := pgx.CollectRows(rows, pgx.RowToStructByPos[policyprovider.PolicyVersion])
+	if err != nil {
+		return nil, fmt.Errorf("scan policy versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// ListPolicies returns the active version of each policy with its target, ordered by policy ID
+func (r *PolicyRepository) ListPolicies(ctx context.Context) ([]policyprovider.PolicyMetadata, error) {
+	query := `
+SELECT id, version, target
+FROM policies
+WHERE status = $1
+ORDER BY id`
+
+	rows, err := r.db.Query(ctx, query, PolicyStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("query active policies: %w", err)
+	}
+
+	policies, err := pgx.CollectRows(rows, pgx.RowToStructByPos[policyprovider.PolicyMetadata])
+	if err != nil {
+		return nil, fmt.Errorf("scan active policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// activateVersion archives the active version of a policy and activates the given version. The activation time is set to
+// now when touch is true, and kept otherwise.
+func activateVersion(ctx context.Context, tx pgx.Tx, id, version string, touch bool) error {
+	if err := archiveActiveVersion(ctx, tx, id, version); err != nil {
+		return err
+	}
+
+	activate := `
+UPDATE policies
+SET status       = $3,
+    activated_at = CASE WHEN $4 AND status <> $3 THEN CURRENT_TIMESTAMP ELSE activated_at END
+WHERE id = $1
+  AND version = $2`
+	tag, err := tx.Exec(ctx, activate, id, version, PolicyStatusActive, touch)
+	if err != nil {
+		return fmt.Errorf("failed to activate policy %s@%s: %w", id, version, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return newPolicyNotFoundError(id, version)
+	}
+
+	return nil
+}
+
+// archiveActiveVersion archives the active version of a policy, unless it is the given version
+func archiveActiveVersion(ctx context.Context, tx pgx.Tx, id, version string) error {
+	archive := "UPDATE policies SET status = $3 WHERE id = $1 AND version <> $2 AND status = $4"
+	if _, err := tx.Exec(ctx, archive, id, version, PolicyStatusArchived, PolicyStatusActive); err != nil {
+		return fmt.Errorf("failed to archive active version of policy %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// getPolicyVersion retrieves the metadata of a policy version
+func getPolicyVersion(ctx context.Context, db querier, id, version string) (*policyprovider.PolicyVersion, error) {
+	query := `
+SELECT id, version, status, content_hash, created_at, activated_at, target
+FROM policies
+WHERE id = $1
+  AND version = $2`
+
+	rows, err := db.Query(ctx, query, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("query policy version: %w", err)
+	}
+
+	policyVersion, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByPos[policyprovider.PolicyVersion])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, newPolicyNotFoundError(id, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan policy version: %w", err)
+	}
+
+	return policyVersion, nil
+}
+
+// newPolicyNotFoundError returns the NotFoundError of a policy version
+func newPolicyNotFoundError(id, version string) *repository.NotFoundError {
+	return &repository.NotFoundError{
+		Resource: PolicyResource,
+		Key:      "id@version",
+		Value:    id + "@" + version,
+	}
+}
@@ -0,0 +1,565 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/CameronXie/access-control-explorer/abac/policyprovider"
+	"github.com/CameronXie/access-control-explorer/examples/abac/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPolicy struct {
+	id          string
+	version     string
+	content     string
+	hash        string
+	status      PolicyStatus
+	createdAt   time.Time
+	activatedAt *time.Time
+}
+
+func TestPolicyRepository_GetPolicies(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	policies := []testPolicy{
+		{id: "rbac", version: "v1", content: "rbac v1", status: PolicyStatusArchived, createdAt: createdAt},
+		{id: "rbac", version: "v2", content: "rbac v2", status: PolicyStatusActive, createdAt: createdAt.Add(time.Hour)},
+		{id: "rbac", version: "v3", content: "rbac v3", status: PolicyStatusDraft, createdAt: createdAt.Add(2 * time.Hour)},
+		{id: "default", version: "v1", content: "default v1", status: PolicyStatusActive, createdAt: createdAt},
+		{id: "retired", version: "v1", content: "retired v1", status: PolicyStatusActive, createdAt: createdAt},
+		{id: "retired", version: "v2", content: "retired v2", status: PolicyStatusArchived, createdAt: createdAt.Add(time.Hour)},
+		{id: "tampered", version: "v1", content: "tampered v1", hash: policyprovider.HashContent([]byte("original")), createdAt: createdAt},
+	}
+
+	testCases := map[string]struct {
+		requests       []policyprovider.GetPolicyRequest
+		setupContext   func() context.Context
+		expectedResult []policyprovider.PolicyResponse
+		expectedError  string
+	}{
+		"should retrieve policies by concrete version in request order": {
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "rbac", Version: "v1"},
+				{ID: "default", Version: "v1"},
+			},
+			setupContext: func() context.Context { return context.Background() },
+			expectedResult: []policyprovider.PolicyResponse{
+				{ID: "rbac", Version: "v1", Content: []byte("rbac v1"), ContentHash: policyprovider.HashContent([]byte("rbac v1"))},
+				{ID: "default", Version: "v1", Content: []byte("default v1"), ContentHash: policyprovider.HashContent([]byte("default v1"))},
+			},
+		},
+
+		"should resolve symbolic versions to concrete versions": {
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "rbac", Version: PolicyVersionActive},
+				{ID: "rbac", Version: PolicyVersionLatest},
+			},
+			setupContext: func() context.Context { return context.Background() },
+			expectedResult: []policyprovider.PolicyResponse{
+				{ID: "rbac", Version: "v2", Content: []byte("rbac v2"), ContentHash: policyprovider.HashContent([]byte("rbac v2"))},
+				{ID: "rbac", Version: "v2", Content: []byte("rbac v2"), ContentHash: policyprovider.HashContent([]byte("rbac v2"))},
+			},
+		},
+
+		"should resolve latest version skipping newer retired versions": {
+			requests:     []policyprovider.GetPolicyRequest{{ID: "retired", Version: PolicyVersionLatest}},
+			setupContext: func() context.Context { return context.Background() },
+			expectedResult: []policyprovider.PolicyResponse{
+				{ID: "retired", Version: "v1", Content: []byte("retired v1"), ContentHash: policyprovider.HashContent([]byte("retired v1"))},
+			},
+		},
+
+		"should return empty result for empty requests": {
+			requests:       []policyprovider.GetPolicyRequest{},
+			setupContext:   func() context.Context { return context.Background() },
+			expectedResult: []policyprovider.PolicyResponse{},
+		},
+
+		"should return error when policy version does not exist": {
+			requests: []policyprovider.GetPolicyRequest{
+				{ID: "rbac", Version: "v1"},
+				{ID: "rbac", Version: "v9"},
+			},
+			setupContext:  func() context.Context { return context.Background() },
+			expectedError: "failed to get policy rbac@v9: policy not found",
+		},
+
+		"should return error when policy has no active version": {
+			requests:      []policyprovider.GetPolicyRequest{{ID: "tampered", Version: PolicyVersionActive}},
+			setupContext:  func() context.Context { return context.Background() },
+			expectedError: "failed to get policy tampered@active: policy not found",
+		},
+
+		"should return error when content does not match hash": {
+			requests:      []policyprovider.GetPolicyRequest{{ID: "tampered", Version: "v1"}},
+			setupContext:  func() context.Context { return context.Background() },
+			expectedError: "policy tampered@v1 content does not match its hash",
+		},
+
+		"should return error when context is cancelled": {
+			requests: []policyprovider.GetPolicyRequest{{ID: "rbac", Version: "v1"}},
+			setupContext: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			expectedError: "context canceled",
+		},
+	}
+
+	setupTestPoliciesData(t, pool, policies)
+	defer cleanupTestPoliciesData(t, pool)
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			repo := NewPolicyRepository(pool)
+
+			result, err := repo.GetPolicies(tc.setupContext(), tc.requests)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, result)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestPolicyRepository_ActivatePolicy(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+
+	testCases := map[string]struct {
+		version           string
+		expectedStatuses  map[string]PolicyStatus
+		expectedError     string
+		expectNotFoundErr bool
+	}{
+		"should activate version and archive previously active version": {
+			version: "v2",
+			expectedStatuses: map[string]PolicyStatus{
+				"v1": PolicyStatusArchived,
+				"v2": PolicyStatusActive,
+			},
+		},
+
+		"should keep active version when activated again": {
+			version: "v1",
+			expectedStatuses: map[string]PolicyStatus{
+				"v1": PolicyStatusActive,
+				"v2": PolicyStatusDraft,
+			},
+		},
+
+		"should return not found error when version does not exist": {
+			version:           "v9",
+			expectedError:     "policy with id@version rbac@v9 not found",
+			expectNotFoundErr: true,
+			expectedStatuses: map[string]PolicyStatus{
+				"v1": PolicyStatusActive,
+				"v2": PolicyStatusDraft,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			repo := NewPolicyRepository(pool)
+			ctx := context.Background()
+
+			require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v1", []byte("rbac v1"), policyprovider.PolicyTarget{}, PolicyStatusActive))
+			require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v2", []byte("rbac v2"), policyprovider.PolicyTarget{}, PolicyStatusDraft))
+			defer cleanupTestPoliciesData(t, pool)
+
+			err := repo.ActivatePolicy(ctx, "rbac", tc.version)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+
+				if tc.expectNotFoundErr {
+					var notFoundErr *repository.NotFoundError
+					assert.True(t, errors.As(err, &notFoundErr))
+					assert.Equal(t, PolicyResource, notFoundErr.Resource)
+				}
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedStatuses, getTestPolicyStatuses(t, pool, "rbac"))
+		})
+	}
+}
+
+func TestPolicyRepository_CreatePolicy(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+
+	testCases := map[string]struct {
+		version          string
+		status           PolicyStatus
+		expectedError    error
+		expectedStatuses map[string]PolicyStatus
+	}{
+		"should create new version": {
+			version:          "v2",
+			status:           PolicyStatusDraft,
+			expectedStatuses: map[string]PolicyStatus{"v1": PolicyStatusActive, "v2": PolicyStatusDraft},
+		},
+
+		"should return version exists error when version is already stored": {
+			version:          "v1",
+			status:           PolicyStatusDraft,
+			expectedError:    policyprovider.ErrPolicyVersionExists,
+			expectedStatuses: map[string]PolicyStatus{"v1": PolicyStatusActive},
+		},
+
+		"should archive active version when creating an active version": {
+			version:          "v2",
+			status:           PolicyStatusActive,
+			expectedStatuses: map[string]PolicyStatus{"v1": PolicyStatusArchived, "v2": PolicyStatusActive},
+		},
+
+		"should keep active version when creating an active version fails": {
+			version:          "v1",
+			status:           PolicyStatusActive,
+			expectedError:    policyprovider.ErrPolicyVersionExists,
+			expectedStatuses: map[string]PolicyStatus{"v1": PolicyStatusActive},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			repo := NewPolicyRepository(pool)
+			ctx := context.Background()
+
+			require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v1", []byte("rbac v1"), policyprovider.PolicyTarget{}, PolicyStatusActive))
+			defer cleanupTestPoliciesData(t, pool)
+
+			err := repo.CreatePolicy(ctx, "rbac", tc.version, []byte("rbac new"), policyprovider.PolicyTarget{}, tc.status)
+
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			versions, err := repo.ListPolicyVersions(ctx, "rbac")
+			require.NoError(t, err)
+
+			statuses := make(map[string]PolicyStatus, len(versions))
+			for _, version := range versions {
+				statuses[version.Version] = version.Status
+			}
+			assert.Equal(t, tc.expectedStatuses, statuses)
+		})
+	}
+}
+
+func TestPolicyRepository_RollbackPolicy(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+
+	activatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		value := activatedAt.Add(time.Duration(hours) * time.Hour)
+		return &value
+	}
+
+	testCases := map[string]struct {
+		policies         []testPolicy
+		expectedVersion  string
+		expectedStatuses map[string]PolicyStatus
+		expectedError    error
+	}{
+		"should reactivate the previously active version": {
+			policies: []testPolicy{
+				{id: "rbac", version: "v1", status: PolicyStatusArchived, activatedAt: at(0)},
+				{id: "rbac", version: "v2", status: PolicyStatusArchived, activatedAt: at(1)},
+				{id: "rbac", version: "v3", status: PolicyStatusActive, activatedAt: at(2)},
+				{id: "rbac", version: "v4", status: PolicyStatusDraft},
+			},
+			expectedVersion: "v2",
+			expectedStatuses: map[string]PolicyStatus{
+				"v1": PolicyStatusArchived,
+				"v2": PolicyStatusActive,
+				"v3": PolicyStatusArchived,
+				"v4": PolicyStatusDraft,
+			},
+		},
+
+		"should skip versions activated after the active version": {
+			policies: []testPolicy{
+				{id: "rbac", version: "v1", status: PolicyStatusArchived, activatedAt: at(0)},
+				{id: "rbac", version: "v2", status: PolicyStatusActive, activatedAt: at(1)},
+				{id: "rbac", version: "v3", status: PolicyStatusArchived, activatedAt: at(2)},
+			},
+			expectedVersion: "v1",
+			expectedStatuses: map[string]PolicyStatus{
+				"v1": PolicyStatusActive,
+				"v2": PolicyStatusArchived,
+				"v3": PolicyStatusArchived,
+			},
+		},
+
+		"should return no previous version error when active version is the first": {
+			policies: []testPolicy{
+				{id: "rbac", version: "v1", status: PolicyStatusActive, activatedAt: at(0)},
+				{id: "rbac", version: "v2", status: PolicyStatusArchived},
+			},
+			expectedError: policyprovider.ErrNoPreviousVersion,
+			expectedStatuses: map[string]PolicyStatus{
+				"v1": PolicyStatusActive,
+				"v2": PolicyStatusArchived,
+			},
+		},
+
+		"should return no previous version error when policy has no active version": {
+			policies: []testPolicy{
+				{id: "rbac", version: "v1", status: PolicyStatusArchived, activatedAt: at(0)},
+			},
+			expectedError: policyprovider.ErrNoPreviousVersion,
+			expectedStatuses: map[string]PolicyStatus{
+				"v1": PolicyStatusArchived,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for i := range tc.policies {
+				tc.policies[i].content = "rbac " + tc.policies[i].version
+				tc.policies[i].createdAt = activatedAt
+			}
+
+			setupTestPoliciesData(t, pool, tc.policies)
+			defer cleanupTestPoliciesData(t, pool)
+
+			result, err := NewPolicyRepository(pool).RollbackPolicy(context.Background(), "rbac")
+
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedVersion, result.Version)
+				assert.Equal(t, PolicyStatusActive, result.Status)
+			}
+
+			assert.Equal(t, tc.expectedStatuses, getTestPolicyStatuses(t, pool, "rbac"))
+		})
+	}
+}
+
+func TestPolicyRepository_ArchivePolicy(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := NewPolicyRepository(pool)
+
+	require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v1", []byte("rbac v1"), policyprovider.PolicyTarget{}, PolicyStatusDraft))
+	require.NoError(t, repo.ActivatePolicy(ctx, "rbac", "v1"))
+	require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v2", []byte("rbac v2"), policyprovider.PolicyTarget{}, PolicyStatusDraft))
+	require.NoError(t, repo.ActivatePolicy(ctx, "rbac", "v2"))
+	defer cleanupTestPoliciesData(t, pool)
+
+	// A retired version is no longer a rollback target
+	require.NoError(t, repo.ArchivePolicy(ctx, "rbac", "v1"))
+	_, err := repo.RollbackPolicy(ctx, "rbac")
+	require.ErrorIs(t, err, policyprovider.ErrNoPreviousVersion)
+
+	err = repo.ArchivePolicy(ctx, "rbac", "v9")
+	var notFoundErr *repository.NotFoundError
+	require.True(t, errors.As(err, &notFoundErr))
+	assert.Equal(t, "rbac@v9", notFoundErr.Value)
+}
+
+func TestPolicyRepository_ListPolicyVersions(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	setupTestPoliciesData(t, pool, []testPolicy{
+		{id: "rbac", version: "v1", content: "rbac v1", status: PolicyStatusArchived, createdAt: createdAt, activatedAt: &createdAt},
+		{id: "rbac", version: "v2", content: "rbac v2", status: PolicyStatusDraft, createdAt: createdAt.Add(time.Hour)},
+		{id: "default", version: "v1", content: "default v1", status: PolicyStatusActive, createdAt: createdAt},
+	})
+	defer cleanupTestPoliciesData(t, pool)
+
+	testCases := map[string]struct {
+		id             string
+		expectedResult []policyprovider.PolicyVersion
+	}{
+		"should list versions most recently created first": {
+			id: "rbac",
+			expectedResult: []policyprovider.PolicyVersion{
+				{
+					ID:          "rbac",
+					Version:     "v2",
+					Status:      PolicyStatusDraft,
+					ContentHash: policyprovider.HashContent([]byte("rbac v2")),
+					CreatedAt:   createdAt.Add(time.Hour),
+				},
+				{
+					ID:          "rbac",
+					Version:     "v1",
+					Status:      PolicyStatusArchived,
+					ContentHash: policyprovider.HashContent([]byte("rbac v1")),
+					CreatedAt:   createdAt,
+					ActivatedAt: &createdAt,
+				},
+			},
+		},
+
+		"should return empty list for unknown policy": {
+			id: "unknown",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := NewPolicyRepository(pool).ListPolicyVersions(context.Background(), tc.id)
+
+			require.NoError(t, err)
+			require.Len(t, result, len(tc.expectedResult))
+			for i, expected := range tc.expectedResult {
+				assert.Equal(t, expected.Version, result[i].Version)
+				assert.Equal(t, expected.Status, result[i].Status)
+				assert.Equal(t, expected.ContentHash, result[i].ContentHash)
+				assert.True(t, expected.CreatedAt.Equal(result[i].CreatedAt))
+				assert.Equal(t, expected.ActivatedAt != nil, result[i].ActivatedAt != nil)
+			}
+		})
+	}
+}
+
+func TestPolicyRepository_ListPolicies(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+
+	ctx := context.Background()
+	repo := NewPolicyRepository(pool)
+	target := policyprovider.PolicyTarget{
+		ResourceTypes: []string{"order"},
+		Attributes: []policyprovider.AttributeMatch{
+			{Category: policyprovider.AttributeCategorySubject, Attribute: "roles", Values: []any{"admin"}},
+		},
+	}
+
+	require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v1", []byte("rbac v1"), policyprovider.PolicyTarget{}, PolicyStatusActive))
+	require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v2", []byte("rbac v2"), target, PolicyStatusDraft))
+	require.NoError(t, repo.CreatePolicy(ctx, "default", "v1", []byte("default v1"), policyprovider.PolicyTarget{}, PolicyStatusActive))
+	require.NoError(t, repo.CreatePolicy(ctx, "orders", "v1", []byte("orders v1"), target, PolicyStatusDraft))
+	defer cleanupTestPoliciesData(t, pool)
+
+	require.NoError(t, repo.ActivatePolicy(ctx, "rbac", "v2"))
+
+	result, err := repo.ListPolicies(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, []policyprovider.PolicyMetadata{
+		{ID: "default", Version: "v1"},
+		{ID: "rbac", Version: "v2", Target: target},
+	}, result)
+}
+
+func TestPolicyRepository_Transaction(t *testing.T) {
+	pool := setupTestDBForPolicies(t)
+	defer pool.Close()
+	defer cleanupTestPoliciesData(t, pool)
+
+	ctx := context.Background()
+
+	// Changes made through a transaction are discarded with it, along with any RBAC changes in the same transaction
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+
+	repo := NewPolicyRepository(tx)
+	require.NoError(t, repo.CreatePolicy(ctx, "rbac", "v1", []byte("rbac v1"), policyprovider.PolicyTarget{}, PolicyStatusDraft))
+	require.NoError(t, repo.ActivatePolicy(ctx, "rbac", "v1"))
+
+	result, err := repo.GetPolicies(ctx, []policyprovider.GetPolicyRequest{{ID: "rbac", Version: PolicyVersionActive}})
+	require.NoError(t, err)
+	expected := []policyprovider.PolicyResponse{
+		{ID: "rbac", Version: "v1", Content: []byte("rbac v1"), ContentHash: policyprovider.HashContent([]byte("rbac v1"))},
+	}
+	assert.Equal(t, expected, result)
+
+	require.NoError(t, tx.Rollback(ctx))
+
+	_, err = NewPolicyRepository(pool).GetPolicies(ctx, []policyprovider.GetPolicyRequest{{ID: "rbac", Version: "v1"}})
+	assert.ErrorContains(t, err, "failed to get policy rbac@v1: policy not found")
+}
+
+func setupTestDBForPolicies(t *testing.T) *pgxpool.Pool {
+	pg := fmt.Sprintf(
+		"postgres://%s:%s@%s/%s?sslmode=%s",
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_HOST"),
+		os.Getenv("POSTGRES_DB_TEST"),
+		os.Getenv("POSTGRES_SSL"),
+	)
+
+	pool, err := pgxpool.New(context.Background(), pg)
+	require.NoError(t, err)
+	return pool
+}
+
+func setupTestPoliciesData(t *testing.T, pool *pgxpool.Pool, policies []testPolicy) {
+	for _, policy := range policies {
+		hash := policy.hash
+		if hash == "" {
+			hash = policyprovider.HashContent([]byte(policy.content))
+		}
+
+		status := policy.status
+		if status == "" {
+			status = PolicyStatusDraft
+		}
+
+		_, err := pool.Exec(
+			context.Background(),
+			`INSERT INTO policies (id, version, content, content_hash, status, created_at, activated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			policy.id, policy.version, policy.content, hash, status, policy.createdAt, policy.activatedAt,
+		)
+		require.NoError(t, err)
+	}
+}
+
+func getTestPolicyStatuses(t *testing.T, pool *pgxpool.Pool, id string) map[string]PolicyStatus {
+	rows, err := pool.Query(context.Background(), "SELECT version, status FROM policies WHERE id = $1", id)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	statuses := make(map[string]PolicyStatus)
+	for rows.Next() {
+		var version string
+		var status PolicyStatus
+		require.NoError(t, rows.Scan(&version, &status))
+		statuses[version] = status
+	}
+	require.NoError(t, rows.Err())
+
+	return statuses
+}
+
+func cleanupTestPoliciesData(t *testing.T, pool *pgxpool.Pool) {
+	_, err := pool.Exec(context.Background(), "DELETE FROM policies")
+	require.NoError(t, err)
+}